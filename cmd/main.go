@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/urfave/cli"
 )
@@ -32,6 +33,11 @@ var flags = []cli.Flag{
 		Name:   "slack-token",
 		Usage:  "token for accessing Slack",
 	},
+	cli.StringFlag{
+		EnvVar: "SLACK_SIGNING_SECRET",
+		Name:   "slack-signing-secret",
+		Usage:  "signing secret of Slack app verifying the requests from Slack, which are rejected if it's empty",
+	},
 	cli.StringFlag{
 		EnvVar: "GITLAB_SCHEME",
 		Name:   "gitlab-scheme",
@@ -49,6 +55,46 @@ var flags = []cli.Flag{
 		Name:   "gitlab-token",
 		Usage:  "token for accessing GitLab",
 	},
+	cli.StringFlag{
+		EnvVar: "WEBHOOK_SECRET",
+		Name:   "webhook-secret",
+		Usage:  "secret token GitLab sends in X-Gitlab-Token, overridden by the secret of project",
+	},
+	cli.IntFlag{
+		EnvVar: "WEBHOOK_WORKERS",
+		Name:   "webhook-workers",
+		Usage:  "number of workers processing webhook events",
+		Value:  4,
+	},
+	cli.IntFlag{
+		EnvVar: "WEBHOOK_QUEUE_SIZE",
+		Name:   "webhook-queue-size",
+		Usage:  "number of webhook events each worker can buffer",
+		Value:  100,
+	},
+	cli.DurationFlag{
+		EnvVar: "EVENT_RETENTION",
+		Name:   "event-retention",
+		Usage:  "how long the UUIDs of webhook events are kept for detecting redelivery",
+		Value:  72 * time.Hour,
+	},
+	cli.DurationFlag{
+		EnvVar: "WEBHOOK_EVENT_RETENTION",
+		Name:   "webhook-event-retention",
+		Usage:  "how long the processed webhook events are kept for listing and replay",
+		Value:  30 * 24 * time.Hour,
+	},
+	cli.BoolFlag{
+		EnvVar: "DRY_RUN",
+		Name:   "dry-run",
+		Usage:  "log messages instead of sending them to Slack, threads are still recorded with fake IDs",
+	},
+	cli.IntFlag{
+		EnvVar: "DRY_RUN_BUFFER",
+		Name:   "dry-run-buffer",
+		Usage:  "number of messages kept in dry-run mode",
+		Value:  100,
+	},
 	cli.StringFlag{
 		EnvVar: "SERVER_ADDR",
 		Name:   "server-addr",
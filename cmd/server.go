@@ -31,6 +31,7 @@ func start(c *cli.Context) {
 	srv := newServer(c)
 	srv.setupRouter()
 	srv.setupAndStartCronjob()
+	srv.router.StartQueue()
 
 	// sync users, projects
 	srv.router.SyncUser()
@@ -35,6 +35,8 @@ func (s *server) setupRouter() {
 	{
 		user.GET("/:email", s.router.GetUser)
 		user.PUT("/:email", s.router.UpdateUser)
+		user.GET("/:email/preferences", s.router.GetUserPreferences)
+		user.PATCH("/:email/preferences", s.router.UpdateUserPreferences)
 		user.POST("", s.router.WrapSyncUser)
 	}
 
@@ -49,6 +51,52 @@ func (s *server) setupRouter() {
 		project.PUT("/:namespace/*path", s.router.UpdateProject)
 		project.POST("", s.router.WrapSyncProject)
 	}
+
+	// templates and routes are apart from projects and groups, whose paths may have any segment
+	template := s.engine.Group("/api/templates")
+	{
+		template.GET("/project/*path", s.router.GetProjectTemplate)
+		template.PUT("/project/*path", s.router.UpdateProjectTemplate)
+		template.DELETE("/project/*path", s.router.DeleteProjectTemplate)
+		template.GET("/group/*path", s.router.GetGroupTemplate)
+		template.PUT("/group/*path", s.router.UpdateGroupTemplate)
+		template.DELETE("/group/*path", s.router.DeleteGroupTemplate)
+	}
+
+	routes := s.engine.Group("/api/routes")
+	{
+		routes.GET("/project/*path", s.router.GetProjectRoutes)
+		routes.PUT("/project/*path", s.router.UpdateProjectRoutes)
+		routes.GET("/group/*path", s.router.GetGroupRoutes)
+		routes.PUT("/group/*path", s.router.UpdateGroupRoutes)
+		routes.GET("/test/*path", s.router.TestProjectRoutes)
+	}
+
+	outbox := s.engine.Group("/api/outbox")
+	{
+		outbox.GET("", s.router.ListOutbox)
+		outbox.POST("/:id/retry", s.router.RetryOutbox)
+	}
+
+	event := s.engine.Group("/api/events")
+	{
+		event.GET("", s.router.ListEvents)
+		event.POST("/:id/replay", s.router.ReplayEvent)
+	}
+
+	digest := s.engine.Group("/api/review-digest")
+	{
+		digest.GET("", s.router.ListReviewDigests)
+		digest.PUT("/:channel", s.router.UpdateReviewDigest)
+		digest.DELETE("/:channel", s.router.DeleteReviewDigest)
+	}
+
+	s.engine.POST("/api/slack/command", s.router.SlashCommand)
+	s.engine.POST("/api/slack/interactivity", s.router.Interact)
+	s.engine.POST("/api/slack/events", s.router.SlackEvent)
+	s.engine.POST("/api/preview", s.router.Preview)
+	s.engine.GET("/api/dry-run/messages", s.router.ListDryRunMessages)
+	s.engine.GET("/api/metrics", s.router.GetMetrics)
 }
 
 func (s *server) setupAndStartCronjob() {
@@ -60,6 +108,43 @@ func (s *server) setupAndStartCronjob() {
 		logrus.Errorf("cronjob starting failed: %v", err)
 		return
 	}
+	err = s.cronjob.AddFunc("@every 10s", s.router.DispatchOutbox)
+	if err != nil {
+		logrus.Errorf("cronjob starting failed: %v", err)
+		return
+	}
+	err = s.cronjob.AddFunc("@every 1m", s.router.DispatchDigests)
+	if err != nil {
+		logrus.Errorf("cronjob starting failed: %v", err)
+		return
+	}
+	// the schedules of review digests are checked every minute
+	err = s.cronjob.AddFunc("@every 1m", s.router.DispatchReviewDigests)
+	if err != nil {
+		logrus.Errorf("cronjob starting failed: %v", err)
+		return
+	}
+	err = s.cronjob.AddFunc("@every 5m", s.router.DispatchStaleReminders)
+	if err != nil {
+		logrus.Errorf("cronjob starting failed: %v", err)
+		return
+	}
+	// the webhook events left out of a full queue are picked up again
+	err = s.cronjob.AddFunc("@every 30s", s.router.RequeueEvents)
+	if err != nil {
+		logrus.Errorf("cronjob starting failed: %v", err)
+		return
+	}
+	err = s.cronjob.AddFunc("@hourly", s.router.PurgeEventUUIDs)
+	if err != nil {
+		logrus.Errorf("cronjob starting failed: %v", err)
+		return
+	}
+	err = s.cronjob.AddFunc("@hourly", s.router.PurgeWebhookEvents)
+	if err != nil {
+		logrus.Errorf("cronjob starting failed: %v", err)
+		return
+	}
 	s.cronjob.Start()
 }
 
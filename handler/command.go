@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"gitlack/model"
+	"gitlack/resource/gitlab"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const commandHelp = "*Usage*\n" +
+	"`/gitlack me channel #channel` - post your merge requests and issues to the channel by default\n" +
+	"`/gitlack project group/repo channel #channel` - post the events of the project to the channel by default\n" +
+	"`/gitlack status` - show your settings and the projects posting to this channel\n" +
+	"`/gitlack help` - show this message"
+
+const unlinkedUser = "Your Slack account isn't linked to a GitLab user yet, users are synchronized by email every midnight."
+
+// channelMention is the channel escaped by Slack, such as `<#C0123456789|general>`
+var channelMention = regexp.MustCompile(`^<#([A-Z0-9]+)(\|[^>]*)?>$`)
+
+// channelID is the ID of Slack channel, which is shown as a link instead of a name
+var channelID = regexp.MustCompile(`^[CG][A-Z0-9]{8,}$`)
+
+// SlashCommand handles `/gitlack`, the response is only shown to the user invoking it
+// ref: https://api.slack.com/interactivity/slash-commands
+func (r *router) SlashCommand(c *gin.Context) {
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		logrus.Errorln(err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": "Invalid request body",
+		})
+		return
+	}
+	if !r.verifySignature(c, body) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"ok":    false,
+			"error": "Invalid X-Slack-Signature",
+		})
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		logrus.Errorln(err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": "Invalid request body",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"response_type": "ephemeral",
+		"text":          r.runCommand(form),
+	})
+}
+
+// runCommand returns the response of command invoked by the Slack user,
+// all commands but help need the user linked to GitLab
+func (r *router) runCommand(form url.Values) string {
+	args := strings.Fields(form.Get("text"))
+	if len(args) == 0 || args[0] == "help" {
+		return commandHelp
+	}
+
+	u, err := r.db.GetUserBySlackID(form.Get("user_id"))
+	if err != nil {
+		if strings.Contains(err.Error(), "sql: no rows in result set") {
+			return unlinkedUser
+		}
+		return "Something went wrong, please try again later."
+	}
+
+	switch {
+	case args[0] == "me" && len(args) == 3 && args[1] == "channel":
+		return r.setUserChannel(u, args[2])
+	case args[0] == "project" && len(args) == 4 && args[2] == "channel":
+		return r.setProjectChannel(u, strings.Trim(args[1], "/"), args[3])
+	case args[0] == "status" && len(args) == 1:
+		return r.commandStatus(u, form.Get("channel_id"), form.Get("channel_name"))
+	}
+	return fmt.Sprintf("Unknown command `%v`\n\n%v", strings.Join(args, " "), commandHelp)
+}
+
+func (r *router) setUserChannel(u *model.User, arg string) string {
+	channel, ok := parseChannel(arg)
+	if !ok {
+		return fmt.Sprintf("Invalid channel `%v`", arg)
+	}
+	err := r.db.UpdateUserDefaultChannel(u.Email, channel)
+	if err != nil {
+		return "Something went wrong, please try again later."
+	}
+	logrus.Infof("default channel of %v updated to %v by slash command", u.Email, channel)
+	return fmt.Sprintf("Your default channel is %v now.", showChannel(channel))
+}
+
+func (r *router) setProjectChannel(u *model.User, path, arg string) string {
+	channel, ok := parseChannel(arg)
+	if !ok {
+		return fmt.Sprintf("Invalid channel `%v`", arg)
+	}
+	p, err := r.db.GetProjectByPath(path)
+	if err != nil {
+		if strings.Contains(err.Error(), "sql: no rows in result set") {
+			return fmt.Sprintf("Project `%v` not found", path)
+		}
+		return "Something went wrong, please try again later."
+	}
+	level, err := r.g.GetProjectAccessLevel(p.ID, u.GitLabID)
+	if err != nil {
+		return "Something went wrong, please try again later."
+	}
+	if level < gitlab.MaintainerAccess {
+		logrus.Infof("%v isn't allowed to change the default channel of %v", u.Email, path)
+		return fmt.Sprintf("You need at least Maintainer access to `%v` in GitLab to change its default channel.", path)
+	}
+	err = r.db.UpdateProjectDefaultChannel(path, channel)
+	if err != nil {
+		return "Something went wrong, please try again later."
+	}
+	logrus.Infof("default channel of %v updated to %v by %v with slash command", path, channel, u.Email)
+	return fmt.Sprintf("The default channel of `%v` is %v now.", path, showChannel(channel))
+}
+
+// commandStatus shows the settings of user and the projects whose default channel is the channel the command is invoked in
+func (r *router) commandStatus(u *model.User, id, name string) string {
+	projects, err := r.db.ListProjectsByChannel(id, name)
+	if err != nil {
+		return "Something went wrong, please try again later."
+	}
+
+	channel := "not set"
+	if u.DefaultChannel != "" {
+		channel = showChannel(u.DefaultChannel)
+	}
+	delivery := u.Delivery
+	if delivery == "" {
+		delivery = model.DeliveryChannel
+	}
+	lines := []string{
+		"*Your settings*",
+		fmt.Sprintf("GitLab user: %v", u.Name),
+		fmt.Sprintf("Default channel: %v", channel),
+		fmt.Sprintf("Delivery: %v", delivery),
+	}
+	if u.QuietStart != "" && u.QuietEnd != "" {
+		lines = append(lines, fmt.Sprintf("Quiet hours: %v - %v", u.QuietStart, u.QuietEnd))
+	}
+
+	lines = append(lines, "", "*Projects posting to this channel by default*")
+	if len(projects) == 0 {
+		lines = append(lines, "None")
+	}
+	for _, p := range projects {
+		lines = append(lines, "• "+p.Name)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseChannel returns the ID of channel escaped by Slack, or the name of channel without `#`
+func parseChannel(arg string) (string, bool) {
+	if m := channelMention.FindStringSubmatch(arg); m != nil {
+		return m[1], true
+	}
+	name := strings.TrimPrefix(arg, "#")
+	if name == "" || strings.ContainsAny(name, "<>|#@") {
+		return "", false
+	}
+	return name, true
+}
+
+// showChannel links to the channel by ID, or by name
+func showChannel(channel string) string {
+	if channelID.MatchString(channel) {
+		return fmt.Sprintf("<#%v>", channel)
+	}
+	return "#" + channel
+}
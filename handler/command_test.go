@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlack/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	mGitLab "gitlack/resource/gitlab/mocks"
+	mDB "gitlack/store/mocks"
+)
+
+// signSlackRequest signs body by secret at ts as Slack does
+func signSlackRequest(req *http.Request, secret, body string, ts time.Time) {
+	sec := strconv.FormatInt(ts.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%v:%v", sec, body)
+	req.Header.Set("X-Slack-Request-Timestamp", sec)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+}
+
+func serveSlashCommand(r *router, text, secret string, ts time.Time) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.POST("/api/slack/command", r.SlashCommand)
+
+	body := url.Values{
+		"command":      {"/gitlack"},
+		"text":         {text},
+		"user_id":      {"fake-slack-id"},
+		"channel_id":   {"C0123456789"},
+		"channel_name": {"fake-channel"},
+	}.Encode()
+	req, _ := http.NewRequest(http.MethodPost, "/api/slack/command", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	signSlackRequest(req, secret, body, ts)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	return w
+}
+
+func commandText(w *httptest.ResponseRecorder) string {
+	var res struct {
+		Text string `json:"text"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &res)
+	return res.Text
+}
+
+func TestSlashCommandSignature(t *testing.T) {
+	input := []struct {
+		routerSecret string
+		secret       string
+		ts           time.Time
+		code         int
+	}{
+		{"fake-secret", "fake-secret", time.Now(), http.StatusOK},
+		{"fake-secret", "fake-wrong-secret", time.Now(), http.StatusUnauthorized},
+		{"fake-secret", "fake-secret", time.Now().Add(-10 * time.Minute), http.StatusUnauthorized},
+		{"", "", time.Now(), http.StatusUnauthorized},
+	}
+	for _, in := range input {
+		// arrange
+		router := getRouter(&mDB.Store{}, nil, nil)
+		router.signingSecret = in.routerSecret
+
+		// act
+		w := serveSlashCommand(router, "help", in.secret, in.ts)
+
+		// assert
+		assert.Equal(t, in.code, w.Code, in)
+	}
+}
+
+func TestSlashCommandMeChannel(t *testing.T) {
+	for arg, channel := range map[string]string{"#fake-channel": "fake-channel", "<#C0123456789|fake-channel>": "C0123456789"} {
+		// arrange
+		mockDB := &mDB.Store{}
+		mockDB.On("GetUserBySlackID", "fake-slack-id").Return(&model.User{Email: "fake-user"}, nil)
+		mockDB.On("UpdateUserDefaultChannel", "fake-user", channel).Return(nil)
+		router := getRouter(mockDB, nil, nil)
+		router.signingSecret = "fake-secret"
+
+		// act
+		w := serveSlashCommand(router, "me channel "+arg, "fake-secret", time.Now())
+
+		// assert
+		assert.Equal(t, http.StatusOK, w.Code, arg)
+		assert.Contains(t, commandText(w), "Your default channel is", arg)
+		mockDB.AssertExpectations(t)
+	}
+}
+
+func TestSlashCommandProjectChannel(t *testing.T) {
+	// arrange
+	mockDB := &mDB.Store{}
+	mockDB.On("GetUserBySlackID", "fake-slack-id").Return(&model.User{Email: "fake-user", GitLabID: 2}, nil)
+	mockDB.On("GetProjectByPath", "fake/project").Return(&model.Project{ID: 1}, nil)
+	mockDB.On("GetProjectByPath", "fake/developer").Return(&model.Project{ID: 3}, nil)
+	mockDB.On("GetProjectByPath", "fake/not-found").Return(nil, errors.New("sql: no rows in result set"))
+	mockDB.On("UpdateProjectDefaultChannel", "fake/project", "fake-channel").Return(nil)
+	stubGitLab := &mGitLab.GitLab{}
+	stubGitLab.On("GetProjectAccessLevel", 1, 2).Return(40, nil)
+	stubGitLab.On("GetProjectAccessLevel", 3, 2).Return(30, nil)
+	router := getRouter(mockDB, nil, stubGitLab)
+	router.signingSecret = "fake-secret"
+
+	// act
+	updated := serveSlashCommand(router, "project fake/project channel #fake-channel", "fake-secret", time.Now())
+	forbidden := serveSlashCommand(router, "project fake/developer channel #fake-channel", "fake-secret", time.Now())
+	notFound := serveSlashCommand(router, "project fake/not-found channel #fake-channel", "fake-secret", time.Now())
+
+	// assert
+	assert.Equal(t, "The default channel of `fake/project` is #fake-channel now.", commandText(updated))
+	assert.Equal(t, "You need at least Maintainer access to `fake/developer` in GitLab to change its default channel.", commandText(forbidden))
+	assert.Equal(t, "Project `fake/not-found` not found", commandText(notFound))
+	mockDB.AssertNumberOfCalls(t, "UpdateProjectDefaultChannel", 1)
+}
+
+func TestSlashCommandStatus(t *testing.T) {
+	// arrange
+	mockDB := &mDB.Store{}
+	mockDB.On("GetUserBySlackID", "fake-slack-id").Return(&model.User{Name: "fake-name", DefaultChannel: "C0123456789", Delivery: "both"}, nil)
+	mockDB.On("ListProjectsByChannel", "C0123456789", "fake-channel").Return([]*model.Project{{Name: "fake/project"}}, nil)
+	router := getRouter(mockDB, nil, nil)
+	router.signingSecret = "fake-secret"
+
+	// act
+	w := serveSlashCommand(router, "status", "fake-secret", time.Now())
+
+	// assert
+	expected := "*Your settings*\nGitLab user: fake-name\nDefault channel: <#C0123456789>\nDelivery: both\n\n" +
+		"*Projects posting to this channel by default*\n• fake/project"
+	assert.Equal(t, expected, commandText(w))
+}
+
+func TestSlashCommandUnlinkedUser(t *testing.T) {
+	// arrange
+	mockDB := &mDB.Store{}
+	mockDB.On("GetUserBySlackID", "fake-slack-id").Return(nil, errors.New("sql: no rows in result set"))
+	router := getRouter(mockDB, nil, nil)
+	router.signingSecret = "fake-secret"
+
+	// act
+	w := serveSlashCommand(router, "me channel #fake-channel", "fake-secret", time.Now())
+
+	// assert
+	assert.Equal(t, unlinkedUser, commandText(w))
+	mockDB.AssertNotCalled(t, "UpdateUserDefaultChannel")
+}
+
+func TestParseChannel(t *testing.T) {
+	input := map[string]string{
+		"#general":               "general",
+		"general":                "general",
+		"<#C0123456789|general>": "C0123456789",
+		"<#C0123456789>":         "C0123456789",
+		"#":                      "",
+		"<@U0123456789>":         "",
+	}
+	for arg, expected := range input {
+		actual, ok := parseChannel(arg)
+		assert.Equal(t, expected, actual, arg)
+		assert.Equal(t, expected != "", ok, arg)
+	}
+}
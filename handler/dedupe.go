@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// isDuplicate reports whether the event of uuid has been received,
+// GitLab redelivers the same event on timeout and manual resending, and Slack retries its events by event_id
+func (r *router) isDuplicate(uuid string) bool {
+	// older GitLab doesn't send X-Gitlab-Event-UUID
+	if uuid == "" {
+		return false
+	}
+	created, err := r.db.CreateEventUUID(uuid, time.Now())
+	if err != nil || created {
+		return false
+	}
+	duplicated := atomic.AddInt64(&r.metrics.WebhookDuplicated, 1)
+	logrus.Infof("webhook event %v is duplicated, total duplicated: %v", uuid, duplicated)
+	return true
+}
+
+// PurgeEventUUIDs removes the UUIDs of events older than retention
+func (r *router) PurgeEventUUIDs() {
+	n, err := r.db.DeleteEventUUIDsBefore(time.Now().Add(-r.retention))
+	if err != nil {
+		return
+	}
+	logrus.Debugf("%v event UUIDs purged", n)
+}
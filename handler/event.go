@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlack/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultEventLimit = 50
+	maxEventLimit     = 500
+)
+
+func (r *router) ListEvents(c *gin.Context) {
+	filter := &model.WebhookEventFilter{
+		Event:  c.Query("event"),
+		Status: c.Query("status"),
+		Limit:  defaultEventLimit,
+	}
+	if filter.Status != "" && filter.Status != model.EventQueued && filter.Status != model.EventDone && filter.Status != model.EventFailed {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": fmt.Sprintf("Invalid \"status\": %q", filter.Status),
+		})
+		return
+	}
+	if projectID, ok := c.GetQuery("project_id"); ok {
+		id, err := strconv.Atoi(projectID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"ok":    false,
+				"error": fmt.Sprintf("Invalid \"project_id\": %q", projectID),
+			})
+			return
+		}
+		filter.ProjectID = id
+	}
+	if limit, ok := c.GetQuery("limit"); ok {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > maxEventLimit {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"ok":    false,
+				"error": fmt.Sprintf("Invalid \"limit\": %q", limit),
+			})
+			return
+		}
+		filter.Limit = n
+	}
+
+	events, err := r.db.SearchWebhookEvents(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"ok":    false,
+			"error": "Server error",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"ok":     true,
+		"events": events,
+	})
+}
+
+// ReplayEvent puts the event back into queue, its outcome is recorded once it's processed
+func (r *router) ReplayEvent(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": fmt.Sprintf("Invalid \"id\": %q", c.Param("id")),
+		})
+		return
+	}
+
+	e, err := r.db.GetWebhookEvent(id)
+	if err != nil {
+		if strings.Contains(err.Error(), "sql: no rows in result set") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"ok":    false,
+				"error": "Event not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"ok":    false,
+			"error": "Server error",
+		})
+		return
+	}
+
+	// the event is picked up by the next re-scan if the queue is full
+	e.Status = model.EventQueued
+	e.Error = ""
+	e.ProcessedAt = nil
+	err = r.db.UpdateWebhookEvent(e)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"ok":    false,
+			"error": "Server error",
+		})
+		return
+	}
+	r.queue.push(e)
+	c.JSON(http.StatusAccepted, gin.H{
+		"ok":      true,
+		"id":      e.ID,
+		"message": fmt.Sprintf("Event: %v queued", e.ID),
+	})
+}
+
+// PurgeWebhookEvents removes the webhook events processed before the retention of events,
+// queued events are kept however old they are
+func (r *router) PurgeWebhookEvents() {
+	n, err := r.db.DeleteWebhookEventsBefore(time.Now().Add(-r.eventRetention))
+	if err != nil {
+		return
+	}
+	logrus.Debugf("%v webhook events purged", n)
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gitlack/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mDB "gitlack/store/mocks"
+)
+
+func serveEvents(r *router, method, url string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/api/events", r.ListEvents)
+	engine.POST("/api/events/:id/replay", r.ReplayEvent)
+
+	req, _ := http.NewRequest(method, url, nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestListEventsWithFilter(t *testing.T) {
+	// arrange
+	expected := &model.WebhookEventFilter{
+		ProjectID: 1,
+		Event:     "Note Hook",
+		Status:    model.EventFailed,
+		Limit:     10,
+	}
+	db := &mDB.Store{}
+	db.On("SearchWebhookEvents", expected).Return([]*model.WebhookEvent{}, nil)
+
+	// act
+	w := serveEvents(&router{db: db}, http.MethodGet, "/api/events?project_id=1&event=Note+Hook&status=failed&limit=10")
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code, "Status code should be 200")
+	db.AssertNumberOfCalls(t, "SearchWebhookEvents", 1)
+}
+
+func TestListEventsDefaultLimit(t *testing.T) {
+	// arrange
+	db := &mDB.Store{}
+	db.On("SearchWebhookEvents", &model.WebhookEventFilter{Limit: defaultEventLimit}).Return([]*model.WebhookEvent{}, nil)
+
+	// act
+	w := serveEvents(&router{db: db}, http.MethodGet, "/api/events")
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code, "Status code should be 200")
+	db.AssertNumberOfCalls(t, "SearchWebhookEvents", 1)
+}
+
+func TestListEventsInvalidQuery(t *testing.T) {
+	input := []string{
+		"/api/events?status=fake-status",
+		"/api/events?project_id=fake",
+		"/api/events?limit=0",
+		"/api/events?limit=501",
+	}
+	for _, url := range input {
+		db := &mDB.Store{}
+		w := serveEvents(&router{db: db}, http.MethodGet, url)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, url)
+		db.AssertNotCalled(t, "SearchWebhookEvents", mock.Anything)
+	}
+}
+
+func TestReplayEvent(t *testing.T) {
+	// arrange
+	event := &model.WebhookEvent{
+		ID:      1,
+		Event:   "Merge Request Hook",
+		Payload: `{"project": {"id": 1}}`,
+		Status:  model.EventFailed,
+		Error:   "fake-error",
+	}
+	db := &mDB.Store{}
+	db.On("GetWebhookEvent", 1).Return(event, nil)
+	db.On("UpdateWebhookEvent", event).Return(nil)
+	hook := getStubMergeRequestHook()
+	router := getWebhookRouter(db, hook, "")
+
+	// act
+	w := serveEvents(router, http.MethodPost, "/api/events/1/replay")
+
+	// assert
+	assert.Equal(t, http.StatusAccepted, w.Code, "Status code should be 202")
+	assert.Contains(t, w.Body.String(), `"id":1`)
+	hook.AssertNotCalled(t, "MergeRequestEvent", mock.Anything)
+	assert.Equal(t, model.EventQueued, event.Status)
+	assert.Equal(t, "", event.Error)
+	db.AssertNumberOfCalls(t, "UpdateWebhookEvent", 1)
+
+	drainQueue(router)
+	hook.AssertCalled(t, "MergeRequestEvent", []byte(event.Payload))
+	assert.Equal(t, model.EventDone, event.Status)
+	db.AssertNumberOfCalls(t, "UpdateWebhookEvent", 2)
+}
+
+func TestReplayEventNotFound(t *testing.T) {
+	// arrange
+	db := &mDB.Store{}
+	db.On("GetWebhookEvent", 1).Return(nil, errors.New("sql: no rows in result set"))
+	hook := getStubMergeRequestHook()
+	router := getWebhookRouter(db, hook, "")
+
+	// act
+	w := serveEvents(router, http.MethodPost, "/api/events/1/replay")
+
+	// assert
+	assert.Equal(t, http.StatusNotFound, w.Code, "Status code should be 404")
+	hook.AssertNotCalled(t, "MergeRequestEvent", mock.Anything)
+}
+
+func TestPurgeWebhookEvents(t *testing.T) {
+	// arrange
+	db := &mDB.Store{}
+	db.On("DeleteWebhookEventsBefore", mock.MatchedBy(func(before time.Time) bool {
+		return time.Since(before) > 719*time.Hour && time.Since(before) < 721*time.Hour
+	})).Return(int64(2), nil)
+	router := &router{db: db, eventRetention: 720 * time.Hour}
+
+	// act
+	router.PurgeWebhookEvents()
+
+	// assert
+	db.AssertExpectations(t)
+}
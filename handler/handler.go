@@ -1,8 +1,14 @@
 package handler
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -21,22 +27,66 @@ type Handler interface {
 	UpdateProject(*gin.Context)
 	WrapSyncProject(*gin.Context)
 	SyncProject() error
+	GetProjectTemplate(*gin.Context)
+	UpdateProjectTemplate(*gin.Context)
+	DeleteProjectTemplate(*gin.Context)
+	GetProjectRoutes(*gin.Context)
+	UpdateProjectRoutes(*gin.Context)
+	TestProjectRoutes(*gin.Context)
 
 	UpdateGroup(*gin.Context)
+	GetGroupTemplate(*gin.Context)
+	UpdateGroupTemplate(*gin.Context)
+	DeleteGroupTemplate(*gin.Context)
+	GetGroupRoutes(*gin.Context)
+	UpdateGroupRoutes(*gin.Context)
 
 	GetUser(*gin.Context)
 	UpdateUser(*gin.Context)
+	GetUserPreferences(*gin.Context)
+	UpdateUserPreferences(*gin.Context)
 	WrapSyncUser(*gin.Context)
 	SyncUser() error
 
 	Webhook(*gin.Context)
+	StartQueue()
+	RequeueEvents()
+	PurgeEventUUIDs()
+	PurgeWebhookEvents()
+	ListEvents(*gin.Context)
+	ReplayEvent(*gin.Context)
+	Preview(*gin.Context)
+	ListDryRunMessages(*gin.Context)
+
+	ListOutbox(*gin.Context)
+	RetryOutbox(*gin.Context)
+	DispatchOutbox()
+	DispatchDigests()
+
+	ListReviewDigests(*gin.Context)
+	UpdateReviewDigest(*gin.Context)
+	DeleteReviewDigest(*gin.Context)
+	DispatchReviewDigests()
+	DispatchStaleReminders()
+
+	SlashCommand(*gin.Context)
+	Interact(*gin.Context)
+	SlackEvent(*gin.Context)
+
+	GetMetrics(*gin.Context)
 }
 
 type router struct {
-	db   store.Store
-	g    gitlab.GitLab
-	s    slack.Slack
-	hook webhook.Webhook
+	db             store.Store
+	g              gitlab.GitLab
+	s              slack.Slack
+	hook           webhook.Webhook
+	secret         string
+	signingSecret  string
+	retention      time.Duration
+	eventRetention time.Duration
+	queue          *queue
+	metrics        *metrics
 }
 
 // NewHandler create a Handler
@@ -44,12 +94,23 @@ func NewHandler(c *cli.Context) Handler {
 	db := store.NewStore(c)
 	g := gitlab.NewGitLab(c)
 	s := slack.NewSlack(c)
+	if c.Bool("dry-run") {
+		logrus.Warnln("dry-run mode, messages are not sent to Slack")
+		s = slack.NewDryRunSlack(s, c.Int("dry-run-buffer"))
+	}
 	h := webhook.NewWebhook(db, g, s)
+	m := &metrics{}
 	return &router{
-		db:   db,
-		g:    g,
-		s:    s,
-		hook: h,
+		db:             db,
+		g:              g,
+		s:              s,
+		hook:           h,
+		secret:         c.String("webhook-secret"),
+		signingSecret:  c.String("slack-signing-secret"),
+		retention:      c.Duration("event-retention"),
+		eventRetention: c.Duration("webhook-event-retention"),
+		queue:          newQueue(c.Int("webhook-workers"), c.Int("webhook-queue-size"), m),
+		metrics:        m,
 	}
 }
 
@@ -64,19 +125,78 @@ func (r *router) Webhook(c *gin.Context) {
 		})
 		return
 	}
-	switch event := c.GetHeader("X-Gitlab-Event"); event {
-	case "Tag Push Hook":
-		r.hook.TagPushEvent(body)
-	case "Merge Request Hook":
-		r.hook.MergeRequestEvent(body)
-	case "Issue Hook":
-		r.hook.IssuesEvent(body)
-	case "Note Hook":
-		r.hook.CommentsEvent(body)
-	default:
-		logrus.Infof("Event not supported: %v", event)
+	if !r.verifyToken(c, body) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"ok":    false,
+			"error": "Invalid X-Gitlab-Token",
+		})
+		return
+	}
+	if r.isDuplicate(c.GetHeader("X-Gitlab-Event-UUID")) {
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+		})
+		return
 	}
+	// events are processed by workers, so slow Slack or GitLab doesn't fail the webhook
+	r.enqueue(c.Request.Header, body)
 	c.JSON(http.StatusOK, gin.H{
 		"ok": true,
 	})
 }
+
+// verifyToken checks X-Gitlab-Token against the secret of project,
+// falls back to the global secret if the project has no secret
+// and accepts every request if neither of them is set
+func (r *router) verifyToken(c *gin.Context, body []byte) bool {
+	var payload struct {
+		ProjectInfo webhook.Project `json:"project"`
+	}
+	err := json.Unmarshal(body, &payload)
+	if err != nil {
+		logrus.Debugf("cannot parse project from webhook body: %v", err)
+	}
+
+	secret, ok := r.webhookSecret(payload.ProjectInfo.ID)
+	if ok && secret == "" {
+		return true
+	}
+
+	// compare the digests so that the length of secret isn't leaked either
+	expected := sha256.Sum256([]byte(secret))
+	actual := sha256.Sum256([]byte(c.GetHeader("X-Gitlab-Token")))
+	if ok && subtle.ConstantTimeCompare(expected[:], actual[:]) == 1 {
+		return true
+	}
+
+	rejected := atomic.AddInt64(&r.metrics.WebhookRejected, 1)
+	logrus.Warnf("webhook rejected, invalid X-Gitlab-Token from %v, project: %v, event: %v, total rejected: %v",
+		c.ClientIP(), payload.ProjectInfo.PathWithNamespace, c.GetHeader("X-Gitlab-Event"), rejected)
+	return false
+}
+
+// webhookSecret returns the secret the event of project must carry, ok is false if the event must be rejected.
+// The body isn't authenticated yet, so an event of missing or unknown project is rejected
+// without the global secret if any project has its own secret, and so is every event if the database fails
+func (r *router) webhookSecret(projectID int) (secret string, ok bool) {
+	known := false
+	if projectID != 0 {
+		p, err := r.db.GetProjectByID(projectID)
+		if err == nil && p.WebhookSecret != "" {
+			return p.WebhookSecret, true
+		}
+		if err != nil && !strings.Contains(err.Error(), "sql: no rows in result set") {
+			return "", false
+		}
+		known = err == nil
+	}
+	if r.secret != "" || known {
+		return r.secret, true
+	}
+
+	n, err := r.db.CountProjectWebhookSecrets()
+	if err != nil || n != 0 {
+		return "", false
+	}
+	return "", true
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"gitlack/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mDB "gitlack/store/mocks"
+)
+
+func TestWebhookWithoutSecret(t *testing.T) {
+	// arrange
+	stubDB := getStubGetProjectByIDDB(&model.Project{}, nil)
+	stubHook := getStubMergeRequestHook()
+	router := getWebhookRouter(stubDB, stubHook, "")
+
+	// act
+	w := serveWebhook(router, "")
+	drainQueue(router)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code, "Status code should be 200")
+	stubHook.AssertNumberOfCalls(t, "MergeRequestEvent", 1)
+}
+
+func TestWebhookWithGlobalSecret(t *testing.T) {
+	// arrange
+	stubDB := getStubGetProjectByIDDB(&model.Project{}, nil)
+	stubHook := getStubMergeRequestHook()
+	router := getWebhookRouter(stubDB, stubHook, "fake-secret")
+
+	// act
+	w := serveWebhook(router, "fake-secret")
+	drainQueue(router)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code, "Status code should be 200")
+	stubHook.AssertNumberOfCalls(t, "MergeRequestEvent", 1)
+}
+
+func TestWebhookWithInvalidToken(t *testing.T) {
+	// arrange
+	stubDB := getStubGetProjectByIDDB(&model.Project{}, nil)
+	stubHook := getStubMergeRequestHook()
+	router := getWebhookRouter(stubDB, stubHook, "fake-secret")
+
+	// act
+	w := serveWebhook(router, "fake-wrong-secret")
+	drainQueue(router)
+
+	// assert
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "Status code should be 401")
+	assert.Equal(t, int64(1), router.metrics.WebhookRejected, "Rejected request should be counted")
+	stubHook.AssertNotCalled(t, "MergeRequestEvent")
+}
+
+func TestWebhookWithMissingToken(t *testing.T) {
+	// arrange
+	stubDB := getStubGetProjectByIDDB(&model.Project{}, nil)
+	stubHook := getStubMergeRequestHook()
+	router := getWebhookRouter(stubDB, stubHook, "fake-secret")
+
+	// act
+	w := serveWebhook(router, "")
+	drainQueue(router)
+
+	// assert
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "Status code should be 401")
+	stubHook.AssertNotCalled(t, "MergeRequestEvent")
+}
+
+func TestWebhookProjectSecretOverridesGlobalSecret(t *testing.T) {
+	// arrange
+	stubDB := getStubGetProjectByIDDB(&model.Project{WebhookSecret: "fake-project-secret"}, nil)
+	stubHook := getStubMergeRequestHook()
+	router := getWebhookRouter(stubDB, stubHook, "fake-secret")
+
+	// act
+	rejected := serveWebhook(router, "fake-secret")
+	accepted := serveWebhook(router, "fake-project-secret")
+	drainQueue(router)
+
+	// assert
+	assert.Equal(t, http.StatusUnauthorized, rejected.Code, "Global secret should be rejected")
+	assert.Equal(t, http.StatusOK, accepted.Code, "Project secret should be accepted")
+	stubHook.AssertNumberOfCalls(t, "MergeRequestEvent", 1)
+}
+
+func TestWebhookUnknownProjectUsesGlobalSecret(t *testing.T) {
+	// arrange
+	stubDB := getStubGetProjectByIDDB(nil, errors.New("sql: no rows in result set"))
+	stubHook := getStubMergeRequestHook()
+	router := getWebhookRouter(stubDB, stubHook, "fake-secret")
+
+	// act
+	w := serveWebhook(router, "fake-secret")
+	drainQueue(router)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code, "Status code should be 200")
+	stubHook.AssertNumberOfCalls(t, "MergeRequestEvent", 1)
+}
+
+func TestWebhookUnknownProjectRejectedWithProjectSecrets(t *testing.T) {
+	for _, err := range []error{errors.New("sql: no rows in result set"), nil} {
+		// arrange
+		stubDB := &mDB.Store{}
+		stubDB.On("GetProjectByID", mock.Anything).Return(nil, errors.New("sql: no rows in result set"))
+		stubDB.On("CountProjectWebhookSecrets").Return(1, err)
+		stubHook := getStubMergeRequestHook()
+		router := getWebhookRouter(stubDB, stubHook, "")
+
+		// act
+		w := serveWebhook(router, "")
+		drainQueue(router)
+
+		// assert
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "Status code should be 401")
+		stubHook.AssertNotCalled(t, "MergeRequestEvent")
+	}
+}
+
+func TestWebhookRejectedOnStoreFail(t *testing.T) {
+	// arrange
+	stubDB := &mDB.Store{}
+	stubDB.On("GetProjectByID", mock.Anything).Return(nil, errors.New("fake-db-error"))
+	stubHook := getStubMergeRequestHook()
+	router := getWebhookRouter(stubDB, stubHook, "fake-secret")
+
+	// act
+	w := serveWebhook(router, "fake-secret")
+	drainQueue(router)
+
+	// assert
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "Status code should be 401")
+	stubHook.AssertNotCalled(t, "MergeRequestEvent")
+}
+
+func TestWebhookDuplicatedEventSkipped(t *testing.T) {
+	// arrange
+	stubDB := getStubGetProjectByIDDB(&model.Project{}, nil)
+	stubDB.On("CreateEventUUID", "fake-uuid", mock.Anything).Return(true, nil).Once()
+	stubDB.On("CreateEventUUID", "fake-uuid", mock.Anything).Return(false, nil)
+	stubHook := getStubMergeRequestHook()
+	router := getWebhookRouter(stubDB, stubHook, "")
+
+	// act
+	first := serveWebhookWithUUID(router, "", "fake-uuid")
+	redelivered := serveWebhookWithUUID(router, "", "fake-uuid")
+	drainQueue(router)
+
+	// assert
+	assert.Equal(t, http.StatusOK, first.Code, "Status code should be 200")
+	assert.Equal(t, http.StatusOK, redelivered.Code, "Status code should be 200")
+	assert.Equal(t, int64(1), router.metrics.WebhookDuplicated, "Duplicated event should be counted")
+	stubDB.AssertNumberOfCalls(t, "CreateWebhookEvent", 1)
+	stubHook.AssertNumberOfCalls(t, "MergeRequestEvent", 1)
+}
+
+func TestWebhookEventUUIDStoreFail(t *testing.T) {
+	// arrange
+	stubDB := getStubGetProjectByIDDB(&model.Project{}, nil)
+	stubDB.On("CreateEventUUID", "fake-uuid", mock.Anything).Return(false, errors.New("fake-db-error"))
+	stubHook := getStubMergeRequestHook()
+	router := getWebhookRouter(stubDB, stubHook, "")
+
+	// act
+	serveWebhookWithUUID(router, "", "fake-uuid")
+	drainQueue(router)
+
+	// assert
+	stubHook.AssertNumberOfCalls(t, "MergeRequestEvent", 1)
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gitlack/handler/webhook"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// interactionPayload is the part of block_actions payload used by Gitlack
+// ref: https://api.slack.com/reference/interaction-payloads/block-actions
+type interactionPayload struct {
+	Type string `json:"type"`
+	User struct {
+		ID string `json:"id"`
+	} `json:"user"`
+	ResponseURL string `json:"response_url"`
+	Actions     []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
+
+// Interact handles the buttons clicked on the messages posted by Gitlack,
+// Slack is acknowledged at once since it waits for only 3 seconds
+// ref: https://api.slack.com/interactivity/handling
+func (r *router) Interact(c *gin.Context) {
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		logrus.Errorln(err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": "Invalid request body",
+		})
+		return
+	}
+	if !r.verifySignature(c, body) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"ok":    false,
+			"error": "Invalid X-Slack-Signature",
+		})
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		logrus.Errorln(err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": "Invalid request body",
+		})
+		return
+	}
+	var payload interactionPayload
+	err = json.Unmarshal([]byte(form.Get("payload")), &payload)
+	if err != nil {
+		logrus.Errorln(err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": "Invalid payload",
+		})
+		return
+	}
+
+	var actions []*webhook.Action
+	if payload.Type == "block_actions" {
+		for _, a := range payload.Actions {
+			projectID, mrNum, ok := parseActionValue(a.Value)
+			if !ok {
+				logrus.Warnf("invalid value %q of action %v", a.Value, a.ActionID)
+				continue
+			}
+			actions = append(actions, &webhook.Action{
+				Name:        a.ActionID,
+				ProjectID:   projectID,
+				MRNum:       mrNum,
+				SlackID:     payload.User.ID,
+				ResponseURL: payload.ResponseURL,
+			})
+		}
+	}
+	go func() {
+		for _, a := range actions {
+			r.hook.MergeRequestAction(a)
+		}
+	}()
+
+	c.Status(http.StatusOK)
+}
+
+// parseActionValue returns the project ID and the number of merge request in value `projectID:iid`
+func parseActionValue(value string) (int, int, bool) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	projectID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	mrNum, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return projectID, mrNum, true
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlack/handler/webhook"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mWebhook "gitlack/handler/webhook/mocks"
+)
+
+func serveInteraction(r *router, payload, secret string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.POST("/api/slack/interactivity", r.Interact)
+
+	body := url.Values{"payload": {payload}}.Encode()
+	req, _ := http.NewRequest(http.MethodPost, "/api/slack/interactivity", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	signSlackRequest(req, secret, body, time.Now())
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestInteract(t *testing.T) {
+	// arrange
+	done := make(chan *webhook.Action, 1)
+	mockedHook := &mWebhook.Webhook{}
+	mockedHook.On("MergeRequestAction", mock.Anything).Run(func(args mock.Arguments) {
+		done <- args.Get(0).(*webhook.Action)
+	})
+	router := &router{hook: mockedHook, signingSecret: "fake-secret"}
+	payload := `{"type": "block_actions", "user": {"id": "fake-slack-id"}, "response_url": "fake-url",
+		"actions": [{"action_id": "mr_merge", "value": "1:2"}]}`
+
+	// act
+	w := serveInteraction(router, payload, "fake-secret")
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	select {
+	case a := <-done:
+		assert.Equal(t, &webhook.Action{Name: webhook.ActionMerge, ProjectID: 1, MRNum: 2, SlackID: "fake-slack-id", ResponseURL: "fake-url"}, a)
+	case <-time.After(time.Second):
+		t.Fatal("action should be performed")
+	}
+}
+
+func TestInteractInvalidSignature(t *testing.T) {
+	// arrange
+	mockedHook := &mWebhook.Webhook{}
+	router := &router{hook: mockedHook, signingSecret: "fake-secret"}
+
+	// act
+	w := serveInteraction(router, `{"type": "block_actions"}`, "fake-wrong-secret")
+
+	// assert
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockedHook.AssertNotCalled(t, "MergeRequestAction", mock.Anything)
+}
+
+func TestParseActionValue(t *testing.T) {
+	input := map[string][]int{
+		"1:2":   {1, 2},
+		"1":     nil,
+		"a:2":   nil,
+		"1:2:3": nil,
+	}
+	for value, expected := range input {
+		projectID, mrNum, ok := parseActionValue(value)
+		assert.Equal(t, expected != nil, ok, value)
+		if expected != nil {
+			assert.Equal(t, expected, []int{projectID, mrNum}, value)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// metrics holds the counters of Gitlack, all fields must be accessed atomically
+type metrics struct {
+	WebhookRejected   int64 `json:"webhook_rejected"`
+	WebhookQueued     int64 `json:"webhook_queued"`
+	WebhookQueueFull  int64 `json:"webhook_queue_full"`
+	WebhookProcessed  int64 `json:"webhook_processed"`
+	WebhookFailed     int64 `json:"webhook_failed"`
+	WebhookDuplicated int64 `json:"webhook_duplicated"`
+}
+
+func (m *metrics) snapshot() *metrics {
+	return &metrics{
+		WebhookRejected:   atomic.LoadInt64(&m.WebhookRejected),
+		WebhookQueued:     atomic.LoadInt64(&m.WebhookQueued),
+		WebhookQueueFull:  atomic.LoadInt64(&m.WebhookQueueFull),
+		WebhookProcessed:  atomic.LoadInt64(&m.WebhookProcessed),
+		WebhookFailed:     atomic.LoadInt64(&m.WebhookFailed),
+		WebhookDuplicated: atomic.LoadInt64(&m.WebhookDuplicated),
+	}
+}
+
+func (r *router) GetMetrics(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"ok":      true,
+		"metrics": r.metrics.snapshot(),
+	})
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlack/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (r *router) DispatchOutbox() {
+	r.hook.DispatchOutbox()
+}
+
+func (r *router) ListOutbox(c *gin.Context) {
+	status := c.DefaultQuery("status", model.OutboxFailed)
+	if status != model.OutboxPending && status != model.OutboxSent && status != model.OutboxFailed {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": fmt.Sprintf("Invalid \"status\": %q", status),
+		})
+		return
+	}
+
+	msgs, err := r.db.ListOutboxMessages(status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"ok":    false,
+			"error": "Server error",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"ok":       true,
+		"messages": msgs,
+	})
+}
+
+func (r *router) RetryOutbox(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": fmt.Sprintf("Invalid \"id\": %q", c.Param("id")),
+		})
+		return
+	}
+
+	msg, err := r.db.GetOutboxMessage(id)
+	if err != nil {
+		if strings.Contains(err.Error(), "sql: no rows in result set") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"ok":    false,
+				"error": "Message not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"ok":    false,
+			"error": "Server error",
+		})
+		return
+	}
+	if msg.Status != model.OutboxFailed {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": fmt.Sprintf("Message: %v is %v", id, msg.Status),
+		})
+		return
+	}
+
+	// put it back to outbox, dispatcher sends it on next run
+	msg.Status = model.OutboxPending
+	msg.Attempts = 0
+	msg.NextAttemptAt = time.Now()
+	err = r.db.UpdateOutboxMessage(msg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"ok":    false,
+			"error": "Server error",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"ok":      true,
+		"message": fmt.Sprintf("Message: %v queued", id),
+	})
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gitlack/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mDB "gitlack/store/mocks"
+)
+
+func serveRetryOutbox(r *router, id string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.POST("/api/outbox/:id/retry", r.RetryOutbox)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/outbox/"+id+"/retry", nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestRetryOutboxFailedMessage(t *testing.T) {
+	msg := &model.OutboxMessage{
+		ID:       1,
+		Status:   model.OutboxFailed,
+		Attempts: 10,
+	}
+	db := &mDB.Store{}
+	db.On("GetOutboxMessage", 1).Return(msg, nil)
+	db.On("UpdateOutboxMessage", msg).Return(nil)
+
+	w := serveRetryOutbox(&router{db: db}, "1")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, model.OutboxPending, msg.Status)
+	assert.Equal(t, 0, msg.Attempts)
+	db.AssertNumberOfCalls(t, "UpdateOutboxMessage", 1)
+}
+
+func TestRetryOutboxSentMessage(t *testing.T) {
+	db := &mDB.Store{}
+	db.On("GetOutboxMessage", 1).Return(&model.OutboxMessage{ID: 1, Status: model.OutboxSent}, nil)
+
+	w := serveRetryOutbox(&router{db: db}, "1")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	db.AssertNotCalled(t, "UpdateOutboxMessage", mock.Anything)
+}
+
+func TestRetryOutboxMessageNotFound(t *testing.T) {
+	db := &mDB.Store{}
+	db.On("GetOutboxMessage", 1).Return(nil, errors.New("sql: no rows in result set"))
+
+	w := serveRetryOutbox(&router{db: db}, "1")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRetryOutboxInvalidID(t *testing.T) {
+	w := serveRetryOutbox(&router{db: &mDB.Store{}}, "fake")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
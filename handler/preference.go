@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"gitlack/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// preferences is the JSON of the notification preferences of user,
+// events tell whether user is mentioned for each event
+type preferences struct {
+	Events        map[string]bool `json:"events"`
+	Delivery      string          `json:"delivery"`
+	MutedProjects []string        `json:"muted_projects"`
+	TimeZone      string          `json:"time_zone"`
+	QuietHours    quietHours      `json:"quiet_hours"`
+}
+
+// quietHours is the window in `15:04` of the time zone of user, both empty turns it off
+type quietHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// preferencesPatch is the JSON body of updating preferences, only the given fields and events are changed
+type preferencesPatch struct {
+	Events        map[string]bool `json:"events"`
+	Delivery      *string         `json:"delivery"`
+	MutedProjects *[]string       `json:"muted_projects"`
+	TimeZone      *string         `json:"time_zone"`
+	QuietHours    *quietHours     `json:"quiet_hours"`
+}
+
+// DispatchDigests sends the notifications queued during quiet hours
+func (r *router) DispatchDigests() {
+	r.hook.DispatchDigests()
+}
+
+func (r *router) GetUserPreferences(c *gin.Context) {
+	u, ok := r.findUser(c, c.Param("email"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"ok":          true,
+		"preferences": userPreferences(u),
+	})
+}
+
+func (r *router) UpdateUserPreferences(c *gin.Context) {
+	var patch preferencesPatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": fmt.Sprintf("Invalid preferences: %v", err),
+		})
+		return
+	}
+	if err := validatePreferences(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": fmt.Sprintf("Invalid preferences: %v", err),
+		})
+		return
+	}
+	u, ok := r.findUser(c, c.Param("email"))
+	if !ok {
+		return
+	}
+
+	if patch.Delivery != nil {
+		u.Delivery = *patch.Delivery
+	}
+	if patch.TimeZone != nil {
+		u.TimeZone = *patch.TimeZone
+	}
+	if patch.QuietHours != nil {
+		u.QuietStart, u.QuietEnd = patch.QuietHours.Start, patch.QuietHours.End
+	}
+	if patch.MutedProjects != nil {
+		var paths []string
+		for _, p := range *patch.MutedProjects {
+			if p = strings.Trim(strings.TrimSpace(p), "/"); p != "" {
+				paths = append(paths, p)
+			}
+		}
+		u.MutedProjects = strings.Join(paths, ",")
+	}
+	if len(patch.Events) != 0 {
+		var muted []string
+		for _, e := range model.MentionEvents {
+			mentioned, ok := patch.Events[e]
+			if !ok {
+				mentioned = !containsItem(u.MutedEvents, e)
+			}
+			if !mentioned {
+				muted = append(muted, e)
+			}
+		}
+		u.MutedEvents = strings.Join(muted, ",")
+	}
+
+	err := r.db.UpdateUserPreferences(u)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"ok":    false,
+			"error": "Server error",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"ok":          true,
+		"preferences": userPreferences(u),
+	})
+}
+
+// findUser returns the user of email, the response is written if it's not found
+func (r *router) findUser(c *gin.Context, email string) (*model.User, bool) {
+	u, err := r.db.GetUserByEmail(email)
+	if err != nil {
+		if strings.Contains(err.Error(), "sql: no rows in result set") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"ok":    false,
+				"error": "User not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"ok":    false,
+			"error": "Server error",
+		})
+		return nil, false
+	}
+	return u, true
+}
+
+// userPreferences returns the preferences of u, users who never set it are mentioned for all events in channel
+func userPreferences(u *model.User) *preferences {
+	p := &preferences{
+		Events:        map[string]bool{},
+		Delivery:      u.Delivery,
+		MutedProjects: []string{},
+		TimeZone:      u.TimeZone,
+		QuietHours:    quietHours{Start: u.QuietStart, End: u.QuietEnd},
+	}
+	if p.Delivery == "" {
+		p.Delivery = model.DeliveryChannel
+	}
+	for _, e := range model.MentionEvents {
+		p.Events[e] = !containsItem(u.MutedEvents, e)
+	}
+	for _, path := range strings.Split(u.MutedProjects, ",") {
+		if path != "" {
+			p.MutedProjects = append(p.MutedProjects, path)
+		}
+	}
+	return p
+}
+
+func validatePreferences(patch *preferencesPatch) error {
+	if patch.Delivery != nil {
+		switch *patch.Delivery {
+		case model.DeliveryChannel, model.DeliveryDM, model.DeliveryBoth:
+		default:
+			return fmt.Errorf("invalid \"delivery\": %q", *patch.Delivery)
+		}
+	}
+	if patch.TimeZone != nil {
+		if _, err := time.LoadLocation(*patch.TimeZone); err != nil {
+			return fmt.Errorf("invalid \"time_zone\": %q", *patch.TimeZone)
+		}
+	}
+	if q := patch.QuietHours; q != nil && (q.Start != "" || q.End != "") {
+		for _, t := range []string{q.Start, q.End} {
+			if _, err := time.Parse("15:04", t); err != nil {
+				return fmt.Errorf("invalid \"quiet_hours\": %q", t)
+			}
+		}
+	}
+	for e := range patch.Events {
+		if !validEvent(e) {
+			return fmt.Errorf("invalid event: %q", e)
+		}
+	}
+	return nil
+}
+
+func validEvent(event string) bool {
+	for _, e := range model.MentionEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// containsItem returns whether the comma-separated list contains item
+func containsItem(list, item string) bool {
+	for _, l := range strings.Split(list, ",") {
+		if strings.TrimSpace(l) == item {
+			return true
+		}
+	}
+	return false
+}
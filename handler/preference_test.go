@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gitlack/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mDB "gitlack/store/mocks"
+)
+
+func servePreferences(r *router, method, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/api/user/:email/preferences", r.GetUserPreferences)
+	engine.PATCH("/api/user/:email/preferences", r.UpdateUserPreferences)
+
+	req, _ := http.NewRequest(method, "/api/user/fake-user/preferences", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestGetUserPreferences(t *testing.T) {
+	// arrange
+	stubDB := &mDB.Store{}
+	stubDB.On("GetUserByEmail", "fake-user").Return(&model.User{MutedEvents: "comment_on_my_mr", MutedProjects: "fake/project,other"}, nil)
+	router := getRouter(stubDB, nil, nil)
+
+	// act
+	w := servePreferences(router, http.MethodGet, "")
+
+	// assert
+	var res struct {
+		Preferences *preferences `json:"preferences"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, model.DeliveryChannel, res.Preferences.Delivery)
+	assert.False(t, res.Preferences.Events[model.MentionCommentOnMyMR])
+	assert.True(t, res.Preferences.Events[model.MentionMRAssigned])
+	assert.Equal(t, []string{"fake/project", "other"}, res.Preferences.MutedProjects)
+}
+
+func TestUpdateUserPreferences(t *testing.T) {
+	// arrange
+	mockDB := &mDB.Store{}
+	mockDB.On("GetUserByEmail", "fake-user").Return(&model.User{Email: "fake-user", MutedEvents: "issue_assigned", MutedProjects: "old"}, nil)
+	mockDB.On("UpdateUserPreferences", &model.User{
+		Email:         "fake-user",
+		Delivery:      model.DeliveryDM,
+		MutedEvents:   "comment_on_my_mr,issue_assigned",
+		MutedProjects: "old",
+	}).Return(nil)
+	router := getRouter(mockDB, nil, nil)
+
+	// act
+	w := servePreferences(router, http.MethodPatch, `{"delivery": "dm", "events": {"comment_on_my_mr": false, "mr_assigned": true}}`)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockDB.AssertExpectations(t)
+}
+
+func TestUpdateUserPreferencesMutedProjects(t *testing.T) {
+	// arrange
+	mockDB := &mDB.Store{}
+	mockDB.On("GetUserByEmail", "fake-user").Return(&model.User{Email: "fake-user", Delivery: model.DeliveryBoth, MutedEvents: "issue_assigned"}, nil)
+	mockDB.On("UpdateUserPreferences", &model.User{
+		Email:         "fake-user",
+		Delivery:      model.DeliveryBoth,
+		MutedEvents:   "issue_assigned",
+		MutedProjects: "fake/project,fake-group",
+	}).Return(nil)
+	router := getRouter(mockDB, nil, nil)
+
+	// act
+	w := servePreferences(router, http.MethodPatch, `{"muted_projects": ["fake/project", " fake-group/ ", ""]}`)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockDB.AssertExpectations(t)
+}
+
+func TestUpdateUserPreferencesQuietHours(t *testing.T) {
+	// arrange
+	mockDB := &mDB.Store{}
+	mockDB.On("GetUserByEmail", "fake-user").Return(&model.User{Email: "fake-user"}, nil)
+	mockDB.On("UpdateUserPreferences", &model.User{
+		Email:      "fake-user",
+		TimeZone:   "Asia/Taipei",
+		QuietStart: "22:00",
+		QuietEnd:   "08:00",
+	}).Return(nil)
+	router := getRouter(mockDB, nil, nil)
+
+	// act
+	w := servePreferences(router, http.MethodPatch, `{"time_zone": "Asia/Taipei", "quiet_hours": {"start": "22:00", "end": "08:00"}}`)
+
+	// assert
+	var res struct {
+		Preferences *preferences `json:"preferences"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, quietHours{Start: "22:00", End: "08:00"}, res.Preferences.QuietHours)
+	mockDB.AssertExpectations(t)
+}
+
+func TestUpdateUserPreferencesInvalid(t *testing.T) {
+	input := map[string]int{
+		`[]`:                                   http.StatusBadRequest,
+		`{"delivery": "email"}`:                http.StatusBadRequest,
+		`{"events": {"push": false}}`:          http.StatusBadRequest,
+		`{"events": {"mr_assigned": "maybe"}}`: http.StatusBadRequest,
+		`{"time_zone": "Mars/Olympus"}`:        http.StatusBadRequest,
+		`{"quiet_hours": {"start": "22:00"}}`:  http.StatusBadRequest,
+	}
+	for body, code := range input {
+		// arrange
+		mockDB := &mDB.Store{}
+		router := getRouter(mockDB, nil, nil)
+
+		// act
+		w := servePreferences(router, http.MethodPatch, body)
+
+		// assert
+		assert.Equal(t, code, w.Code, body)
+		mockDB.AssertNotCalled(t, "UpdateUserPreferences", mock.Anything)
+	}
+}
+
+func TestUpdateUserPreferencesUserNotFound(t *testing.T) {
+	// arrange
+	stubDB := &mDB.Store{}
+	stubDB.On("GetUserByEmail", "fake-user").Return(nil, errors.New("sql: no rows in result set"))
+	router := getRouter(stubDB, nil, nil)
+
+	// act
+	w := servePreferences(router, http.MethodPatch, `{"delivery": "both"}`)
+
+	// assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
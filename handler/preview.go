@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"gitlack/resource/slack"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// Preview renders the message of webhook payload in body without posting it,
+// the event is taken from `X-Gitlab-Event` as the webhook does
+func (r *router) Preview(c *gin.Context) {
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		logrus.Errorln(err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": "Invalid body",
+		})
+		return
+	}
+
+	p, err := r.hook.Preview(c.GetHeader("X-Gitlab-Event"), body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"ok":      true,
+		"preview": p,
+	})
+}
+
+// ListDryRunMessages responds the messages which would have been sent to Slack in dry-run mode
+func (r *router) ListDryRunMessages(c *gin.Context) {
+	rec, ok := r.s.(slack.Recorder)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"ok":    false,
+			"error": "Dry-run mode is off",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"ok":       true,
+		"messages": rec.Messages(),
+	})
+}
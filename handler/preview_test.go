@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gitlack/handler/webhook"
+	"gitlack/resource/slack"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	mHook "gitlack/handler/webhook/mocks"
+	mSlack "gitlack/resource/slack/mocks"
+)
+
+func servePreview(r *router, event, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.POST("/api/preview", r.Preview)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/preview", strings.NewReader(body))
+	req.Header.Set("X-Gitlab-Event", event)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestPreview(t *testing.T) {
+	// arrange
+	stubHook := &mHook.Webhook{}
+	stubHook.On("Preview", "Issue Hook", []byte(`{"fake": "body"}`)).Return(&webhook.Preview{
+		Channel: "fake-channel",
+		Text:    "fake-text",
+	}, nil)
+	router := &router{hook: stubHook}
+
+	// act
+	w := servePreview(router, "Issue Hook", `{"fake": "body"}`)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"ok": true, "preview": {"channel": "fake-channel", "text": "fake-text"}}`, w.Body.String())
+}
+
+func TestPreviewError(t *testing.T) {
+	// arrange
+	stubHook := &mHook.Webhook{}
+	stubHook.On("Preview", "Push Hook", []byte(`{}`)).Return(nil, errors.New(`event not supported: "Push Hook"`))
+	router := &router{hook: stubHook}
+
+	// act
+	w := servePreview(router, "Push Hook", `{}`)
+
+	// assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "event not supported")
+}
+
+func TestListDryRunMessages(t *testing.T) {
+	// arrange
+	s := slack.NewDryRunSlack(nil, 10)
+	s.PostSlackMessage("fake-channel", "fake-text", nil, nil)
+	router := &router{s: s}
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/api/dry-run/messages", router.ListDryRunMessages)
+
+	// act
+	req, _ := http.NewRequest(http.MethodGet, "/api/dry-run/messages", nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"text":"fake-text"`)
+}
+
+func TestListDryRunMessagesOff(t *testing.T) {
+	// arrange
+	router := &router{s: &mSlack.Slack{}}
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/api/dry-run/messages", router.ListDryRunMessages)
+
+	// act
+	req, _ := http.NewRequest(http.MethodGet, "/api/dry-run/messages", nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	// assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
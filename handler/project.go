@@ -5,8 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"gitlack/handler/webhook"
+
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
@@ -37,22 +40,62 @@ func (r *router) GetProject(c *gin.Context) {
 	})
 }
 
+// UpdateProject updates the settings of project given in query string, and the webhook secret in form body,
+// the settings not given are kept
 func (r *router) UpdateProject(c *gin.Context) {
-	defaultChannel := c.Query("default_channel")
-	if defaultChannel == "" {
-		logrus.Debugln("Default channel not found")
+	defaultChannel, hasChannel := c.GetQuery("default_channel")
+	// secret is taken from body so that it won't be shown in access log
+	webhookSecret, hasSecret := c.GetPostForm("webhook_secret")
+	watchedBranches, hasBranches := c.GetQuery("watched_branches")
+	reactions, hasReactions := c.GetQuery("reactions")
+	staleAfter, hasStaleAfter := c.GetQuery("stale_after")
+	escalateAfter, hasEscalateAfter := c.GetQuery("escalate_after")
+	escalateTo, hasEscalateTo := c.GetQuery("escalate_to")
+	if !hasChannel && !hasSecret && !hasBranches && !hasReactions && !hasStaleAfter && !hasEscalateAfter && !hasEscalateTo {
+		logrus.Debugln("Default channel, webhook secret, watched branches, reactions and staleness not found")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok": false,
+			"error": "Missing parameter: one of \"default_channel\", \"webhook_secret\", \"watched_branches\", \"reactions\", " +
+				"\"stale_after\", \"escalate_after\" or \"escalate_to\"",
+		})
+		return
+	}
+	if hasChannel && defaultChannel == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"ok":    false,
 			"error": fmt.Sprintf("Invalid \"default_channel\": %q", defaultChannel),
 		})
 		return
 	}
+	if _, err := webhook.ParseReactions(reactions); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": fmt.Sprintf("Invalid \"reactions\": %v", err),
+		})
+		return
+	}
+	staleHours, err := parseHours(staleAfter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": fmt.Sprintf("Invalid \"stale_after\": %q", staleAfter),
+		})
+		return
+	}
+	escalateHours, err := parseHours(escalateAfter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": fmt.Sprintf("Invalid \"escalate_after\": %q", escalateAfter),
+		})
+		return
+	}
 
 	// check project exists
 	namespace := c.Param("namespace")
 	path := c.Param("path")
 	pathWithNamespace := namespace + path
-	_, err := r.db.GetProjectByPath(pathWithNamespace)
+	p, err := r.db.GetProjectByPath(pathWithNamespace)
 	if err != nil {
 		if strings.Contains(err.Error(), "sql: no rows in result set") {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -68,8 +111,29 @@ func (r *router) UpdateProject(c *gin.Context) {
 		return
 	}
 
-	// update project default channel
-	err = r.db.UpdateProjectDefaultChannel(pathWithNamespace, defaultChannel)
+	if hasChannel {
+		p.DefaultChannel = defaultChannel
+	}
+	// empty secret removes it, empty branches stop announcing pushes, empty reactions reset to the default ones
+	if hasSecret {
+		p.WebhookSecret = webhookSecret
+	}
+	if hasBranches {
+		p.WatchedBranches = watchedBranches
+	}
+	if hasReactions {
+		p.Reactions = reactions
+	}
+	if hasStaleAfter {
+		p.StaleAfter = staleHours
+	}
+	if hasEscalateAfter {
+		p.EscalateAfter = escalateHours
+	}
+	if hasEscalateTo {
+		p.EscalateTo = escalateTo
+	}
+	err = r.db.UpdateProjectSettings(p)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"ok":    false,
@@ -83,6 +147,18 @@ func (r *router) UpdateProject(c *gin.Context) {
 	})
 }
 
+// parseHours parses the threshold of staleness in hours, empty is zero which turns it off
+func parseHours(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	hours, err := strconv.Atoi(s)
+	if err != nil || hours < 0 {
+		return 0, fmt.Errorf("invalid hours: %q", s)
+	}
+	return hours, nil
+}
+
 func (r *router) WrapSyncProject(c *gin.Context) {
 	err := r.SyncProject()
 	if err != nil {
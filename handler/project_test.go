@@ -2,9 +2,15 @@ package handler
 
 import (
 	"fmt"
+	"net/http"
 	"testing"
 
+	"gitlack/model"
+
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mDB "gitlack/store/mocks"
 )
 
 func TestSyncProjectWithFiveProjects(t *testing.T) {
@@ -45,3 +51,85 @@ func TestSyncProjectWithCreateProjectFail(t *testing.T) {
 	// assert
 	assert.Error(t, err, "Error should not be nil")
 }
+
+func TestUpdateProjectReactions(t *testing.T) {
+	// arrange
+	stubDB := &mDB.Store{}
+	stubDB.On("GetProjectByPath", "fake/fake-project").Return(&model.Project{DefaultChannel: "fake-channel"}, nil)
+	stubDB.On("UpdateProjectSettings", &model.Project{DefaultChannel: "fake-channel", Reactions: "merge:tada,close:"}).Return(nil)
+	router := getRouter(stubDB, nil, nil)
+
+	// act
+	w := serveUpdateProject(router, "reactions=merge:tada,close:")
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code, "Status code should be 200")
+	stubDB.AssertNumberOfCalls(t, "UpdateProjectSettings", 1)
+}
+
+func TestUpdateProjectInvalidReactions(t *testing.T) {
+	// arrange
+	stubDB := &mDB.Store{}
+	router := getRouter(stubDB, nil, nil)
+
+	// act
+	w := serveUpdateProject(router, "reactions=fake-event:tada")
+
+	// assert
+	assert.Equal(t, http.StatusBadRequest, w.Code, "Status code should be 400")
+	assert.Contains(t, w.Body.String(), `Invalid \"reactions\"`)
+	stubDB.AssertNotCalled(t, "UpdateProjectSettings", mock.Anything)
+}
+
+func TestUpdateProjectStaleness(t *testing.T) {
+	// arrange
+	stubDB := &mDB.Store{}
+	stubDB.On("GetProjectByPath", "fake/fake-project").Return(&model.Project{StaleAfter: 48, EscalateAfter: 96}, nil)
+	stubDB.On("UpdateProjectSettings", &model.Project{StaleAfter: 16, EscalateAfter: 96, EscalateTo: "lead@fake.com"}).Return(nil)
+	router := getRouter(stubDB, nil, nil)
+
+	// act
+	w := serveUpdateProject(router, "stale_after=16&escalate_to=lead@fake.com")
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code, "Status code should be 200")
+	stubDB.AssertNumberOfCalls(t, "UpdateProjectSettings", 1)
+}
+
+func TestUpdateProjectSettings(t *testing.T) {
+	// arrange
+	stubDB := &mDB.Store{}
+	stubDB.On("GetProjectByPath", "fake/fake-project").Return(&model.Project{ID: 1, Name: "fake/fake-project", Reactions: "merge:tada"}, nil)
+	stubDB.On("UpdateProjectSettings", &model.Project{ID: 1, Name: "fake/fake-project", DefaultChannel: "fake-channel",
+		WatchedBranches: "main,release/*", Reactions: "merge:tada", EscalateAfter: 24}).Return(nil)
+	router := getRouter(stubDB, nil, nil)
+
+	// act
+	w := serveUpdateProject(router, "default_channel=fake-channel&watched_branches=main,release/*&escalate_after=24")
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code, "Status code should be 200")
+	stubDB.AssertNumberOfCalls(t, "UpdateProjectSettings", 1)
+}
+
+func TestUpdateProjectInvalidParameter(t *testing.T) {
+	input := map[string]string{
+		"":                  "Missing parameter",
+		"default_channel=":  `Invalid \"default_channel\"`,
+		"stale_after=2d":    `Invalid \"stale_after\": \"2d\"`,
+		"escalate_after=-1": `Invalid \"escalate_after\": \"-1\"`,
+	}
+	for query, expected := range input {
+		// arrange
+		stubDB := &mDB.Store{}
+		router := getRouter(stubDB, nil, nil)
+
+		// act
+		w := serveUpdateProject(router, query)
+
+		// assert
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		assert.Contains(t, w.Body.String(), expected, query)
+		stubDB.AssertNotCalled(t, "UpdateProjectSettings", mock.Anything)
+	}
+}
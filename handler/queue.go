@@ -0,0 +1,282 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gitlack/handler/webhook"
+	"gitlack/model"
+
+	"github.com/sirupsen/logrus"
+)
+
+// queue dispatches webhook events to a bounded pool of workers,
+// events of the same key always go to the same worker so that they are processed in order
+type queue struct {
+	shards  []chan *model.WebhookEvent
+	metrics *metrics
+	// inFlight are the IDs of persisted events which are in shards or being processed,
+	// left are the IDs of persisted events left queued in store by key, which are put back by the re-scan
+	mu       sync.Mutex
+	inFlight map[int]bool
+	left     map[string]map[int]bool
+}
+
+func newQueue(workers, size int, m *metrics) *queue {
+	if workers < 1 {
+		workers = 1
+	}
+	if size < 1 {
+		size = 1
+	}
+	q := &queue{
+		shards:   make([]chan *model.WebhookEvent, workers),
+		metrics:  m,
+		inFlight: map[int]bool{},
+		left:     map[string]map[int]bool{},
+	}
+	for i := range q.shards {
+		q.shards[i] = make(chan *model.WebhookEvent, size)
+	}
+	return q
+}
+
+// start runs the workers, each of them calls process for events in its shard one by one
+func (q *queue) start(process func(*model.WebhookEvent)) {
+	for _, shard := range q.shards {
+		go func(shard chan *model.WebhookEvent) {
+			for e := range shard {
+				atomic.AddInt64(&q.metrics.WebhookQueued, -1)
+				process(e)
+				q.done(e)
+			}
+		}(shard)
+	}
+}
+
+// push puts event into its shard unless it's in flight already, it never blocks,
+// the event is left queued in store if the shard is full or an older event of the same key is left,
+// and it's put back in order by the next re-scan
+func (q *queue) push(e *model.WebhookEvent) bool {
+	return q.requeue(e, nil)
+}
+
+// requeue pushes the event listed from store, queued reports whether it's still queued in store,
+// it's checked with the events in flight at once so that an event done since it's listed isn't processed again
+func (q *queue) requeue(e *model.WebhookEvent, queued func(int) bool) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if e.ID != 0 {
+		if q.inFlight[e.ID] {
+			return false
+		}
+		if queued != nil && !queued(e.ID) {
+			delete(q.left[e.ObjectKey], e.ID)
+			return false
+		}
+		if q.behind(e) {
+			q.leave(e)
+			return false
+		}
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(e.ObjectKey))
+	shard := q.shards[h.Sum32()%uint32(len(q.shards))]
+
+	select {
+	case shard <- e:
+		atomic.AddInt64(&q.metrics.WebhookQueued, 1)
+		if e.ID != 0 {
+			q.inFlight[e.ID] = true
+			delete(q.left[e.ObjectKey], e.ID)
+		}
+		return true
+	default:
+		q.leave(e)
+		full := atomic.AddInt64(&q.metrics.WebhookQueueFull, 1)
+		logrus.Warnf("webhook queue is full, event %v is left queued, key: %v, total full: %v", e.ID, e.ObjectKey, full)
+		return false
+	}
+}
+
+// behind reports whether an older event of the same key is left queued in store
+func (q *queue) behind(e *model.WebhookEvent) bool {
+	for id := range q.left[e.ObjectKey] {
+		if id < e.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// leave records event left queued in store, the caller holds q.mu
+func (q *queue) leave(e *model.WebhookEvent) {
+	if e.ID == 0 {
+		return
+	}
+	if q.left[e.ObjectKey] == nil {
+		q.left[e.ObjectKey] = map[int]bool{}
+	}
+	q.left[e.ObjectKey][e.ID] = true
+}
+
+// done marks event no longer in flight
+func (q *queue) done(e *model.WebhookEvent) {
+	q.mu.Lock()
+	delete(q.inFlight, e.ID)
+	q.mu.Unlock()
+}
+
+// objectKey returns the key that events must be processed in order,
+// events of a merge request or an issue share the same key, others are keyed by project
+func objectKey(event string, body []byte) (int, string) {
+	var payload struct {
+		ProjectInfo      webhook.Project          `json:"project"`
+		ObjAttr          webhook.ObjectAttributes `json:"object_attributes"`
+		MergeRequestInfo webhook.MergeRequest     `json:"merge_request"`
+		IssueInfo        webhook.Issue            `json:"issue"`
+	}
+	err := json.Unmarshal(body, &payload)
+	if err != nil {
+		logrus.Debugf("cannot parse object from webhook body: %v", err)
+	}
+	projectID := payload.ProjectInfo.ID
+
+	switch event {
+	case "Merge Request Hook":
+		return projectID, fmt.Sprintf("%v!%v", projectID, payload.ObjAttr.ObjectNum)
+	case "Issue Hook":
+		return projectID, fmt.Sprintf("%v#%v", projectID, payload.ObjAttr.ObjectNum)
+	case "Note Hook", "Pipeline Hook":
+		if payload.MergeRequestInfo.Num != 0 {
+			return projectID, fmt.Sprintf("%v!%v", projectID, payload.MergeRequestInfo.Num)
+		}
+		if payload.IssueInfo.Num != 0 {
+			return projectID, fmt.Sprintf("%v#%v", projectID, payload.IssueInfo.Num)
+		}
+	}
+	return projectID, fmt.Sprintf("%v", projectID)
+}
+
+// enqueue persists the webhook request and puts it into queue
+func (r *router) enqueue(header http.Header, body []byte) {
+	event := header.Get("X-Gitlab-Event")
+	projectID, key := objectKey(event, body)
+	e := &model.WebhookEvent{
+		Event:     event,
+		ProjectID: projectID,
+		ObjectKey: key,
+		UUID:      header.Get("X-Gitlab-Event-UUID"),
+		Headers:   logHeaders(header),
+		Payload:   string(body),
+		Status:    model.EventQueued,
+		CreatedAt: time.Now(),
+	}
+	// the event is still processed even if it isn't persisted
+	r.db.CreateWebhookEvent(e)
+	r.queue.push(e)
+}
+
+// logHeaders returns the headers of request in JSON without the secret token
+func logHeaders(header http.Header) string {
+	logged := http.Header{}
+	for k, v := range header {
+		if k == "X-Gitlab-Token" {
+			continue
+		}
+		logged[k] = v
+	}
+	b, err := json.Marshal(logged)
+	if err != nil {
+		logrus.Errorln(err)
+		return ""
+	}
+	return string(b)
+}
+
+// StartQueue starts the workers and puts back the events which are not processed before last shutdown
+func (r *router) StartQueue() {
+	r.queue.start(r.processEvent)
+	r.RequeueEvents()
+}
+
+// RequeueEvents puts the events still queued in store back into queue in order, such as the ones left out of a full queue
+func (r *router) RequeueEvents() {
+	events, err := r.db.ListWebhookEvents(model.EventQueued)
+	if err != nil {
+		return
+	}
+	requeued := 0
+	for _, e := range events {
+		if r.queue.requeue(e, r.stillQueued) {
+			requeued++
+		}
+	}
+	if requeued != 0 {
+		logrus.Infof("%v webhook events are put back into queue", requeued)
+	}
+}
+
+// stillQueued reports whether the event is queued in store, it may be done since it's listed
+func (r *router) stillQueued(id int) bool {
+	e, err := r.db.GetWebhookEvent(id)
+	if err != nil {
+		return false
+	}
+	return e.Status == model.EventQueued
+}
+
+// processEvent dispatches event to webhook and records the result
+func (r *router) processEvent(e *model.WebhookEvent) {
+	err := r.dispatch(e.Event, []byte(e.Payload))
+	now := time.Now()
+	e.ProcessedAt = &now
+	if err != nil {
+		atomic.AddInt64(&r.metrics.WebhookFailed, 1)
+		logrus.Errorf("webhook event %v failed: %v", e.ID, err)
+		e.Status = model.EventFailed
+		e.Error = err.Error()
+	} else {
+		atomic.AddInt64(&r.metrics.WebhookProcessed, 1)
+		e.Status = model.EventDone
+		e.Error = ""
+	}
+	if e.ID != 0 {
+		r.db.UpdateWebhookEvent(e)
+	}
+}
+
+// dispatch calls the webhook of event and returns its error, a panic of webhook is returned as error too
+func (r *router) dispatch(event string, body []byte) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	switch event {
+	case "Tag Push Hook":
+		return r.hook.TagPushEvent(body)
+	case "Merge Request Hook":
+		return r.hook.MergeRequestEvent(body)
+	case "Issue Hook":
+		return r.hook.IssuesEvent(body)
+	case "Note Hook":
+		return r.hook.CommentsEvent(body)
+	case "Pipeline Hook":
+		return r.hook.PipelineEvent(body)
+	case "Push Hook":
+		return r.hook.PushEvent(body)
+	case "Release Hook":
+		return r.hook.ReleaseEvent(body)
+	default:
+		logrus.Infof("Event not supported: %v", event)
+	}
+	return nil
+}
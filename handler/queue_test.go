@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gitlack/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mHook "gitlack/handler/webhook/mocks"
+	mDB "gitlack/store/mocks"
+)
+
+func TestObjectKey(t *testing.T) {
+	input := []struct {
+		event string
+		body  string
+	}{
+		{"Merge Request Hook", `{"project": {"id": 1}, "object_attributes": {"iid": 2}}`},
+		{"Issue Hook", `{"project": {"id": 1}, "object_attributes": {"iid": 2}}`},
+		{"Note Hook", `{"project": {"id": 1}, "object_attributes": {"iid": 9}, "merge_request": {"iid": 2}}`},
+		{"Note Hook", `{"project": {"id": 1}, "object_attributes": {"iid": 9}, "issue": {"iid": 2}}`},
+		{"Pipeline Hook", `{"project": {"id": 1}, "object_attributes": {"id": 9}, "merge_request": {"iid": 2}}`},
+		{"Push Hook", `{"project": {"id": 1}}`},
+		{"Merge Request Hook", `not-json`},
+	}
+	expected := []string{"1!2", "1#2", "1!2", "1#2", "1!2", "1", "0!0"}
+
+	for i, in := range input {
+		_, key := objectKey(in.event, []byte(in.body))
+		assert.Equal(t, expected[i], key, in.body)
+	}
+}
+
+func TestWebhookProcessedByWorker(t *testing.T) {
+	// arrange
+	stubDB := getStubGetProjectByIDDB(&model.Project{}, nil)
+	stubHook := getStubMergeRequestHook()
+	router := getWebhookRouter(stubDB, stubHook, "")
+
+	// act
+	serveWebhook(router, "")
+
+	// assert
+	stubDB.AssertNumberOfCalls(t, "CreateWebhookEvent", 1)
+	stubHook.AssertNotCalled(t, "MergeRequestEvent", mock.Anything)
+	assert.Equal(t, int64(1), router.metrics.WebhookQueued)
+
+	drainQueue(router)
+	stubHook.AssertNumberOfCalls(t, "MergeRequestEvent", 1)
+	assert.Equal(t, int64(1), router.metrics.WebhookProcessed)
+}
+
+func TestWebhookEventLogsHeadersWithoutToken(t *testing.T) {
+	// arrange
+	stubDB := getStubGetProjectByIDDB(&model.Project{}, nil)
+	stubDB.On("CreateEventUUID", "fake-uuid", mock.Anything).Return(true, nil)
+	stubHook := getStubMergeRequestHook()
+	router := getWebhookRouter(stubDB, stubHook, "fake-secret")
+
+	// act
+	serveWebhookWithUUID(router, "fake-secret", "fake-uuid")
+
+	// assert
+	stubDB.AssertNumberOfCalls(t, "CreateWebhookEvent", 1)
+	e := <-router.queue.shards[0]
+	assert.Equal(t, "fake-uuid", e.UUID)
+	assert.Equal(t, "1!0", e.ObjectKey)
+	assert.Contains(t, e.Headers, "Merge Request Hook")
+	assert.NotContains(t, e.Headers, "fake-secret")
+}
+
+func TestQueueKeepsOrderOfSameKey(t *testing.T) {
+	// arrange
+	m := &metrics{}
+	q := newQueue(4, 1, m)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	processed := map[string][]int{}
+	q.start(func(e *model.WebhookEvent) {
+		mu.Lock()
+		processed[e.ObjectKey] = append(processed[e.ObjectKey], e.ID)
+		mu.Unlock()
+		wg.Done()
+	})
+
+	// act
+	keys := []string{"1!1", "1!2", "1#1", "2"}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		// the event is pushed again while its shard is full like the re-scan does
+		for !q.push(&model.WebhookEvent{ID: i, ObjectKey: keys[i%len(keys)]}) {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	wg.Wait()
+
+	// assert
+	for i, key := range keys {
+		var expected []int
+		for id := i; id < 20; id += len(keys) {
+			expected = append(expected, id)
+		}
+		assert.Equal(t, expected, processed[key], key)
+	}
+	assert.Equal(t, int64(0), m.WebhookQueued)
+}
+
+func TestQueueFull(t *testing.T) {
+	// arrange
+	m := &metrics{}
+	q := newQueue(1, 1, m)
+	q.push(&model.WebhookEvent{ID: 1})
+
+	// act
+	pushed := q.push(&model.WebhookEvent{ID: 2})
+
+	// assert
+	assert.False(t, pushed)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&m.WebhookQueueFull))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&m.WebhookQueued))
+	assert.Equal(t, map[int]bool{1: true}, q.inFlight)
+	assert.Equal(t, map[int]bool{2: true}, q.left[""])
+	first := <-q.shards[0]
+	assert.Equal(t, 1, first.ID)
+}
+
+func TestQueueKeepsEventBehindLeftOne(t *testing.T) {
+	// arrange
+	m := &metrics{}
+	q := newQueue(1, 1, m)
+	q.push(&model.WebhookEvent{ID: 1, ObjectKey: "1!1"})
+	q.push(&model.WebhookEvent{ID: 2, ObjectKey: "1!1"})
+	q.done(<-q.shards[0])
+
+	// act
+	pushed := q.push(&model.WebhookEvent{ID: 3, ObjectKey: "1!1"})
+	requeued := q.requeue(&model.WebhookEvent{ID: 2, ObjectKey: "1!1"}, func(int) bool { return true })
+	pushedAfter := q.push(&model.WebhookEvent{ID: 3, ObjectKey: "1!1"})
+
+	// assert
+	assert.False(t, pushed)
+	assert.True(t, requeued)
+	assert.False(t, pushedAfter, "shard of the key is full again")
+	assert.Equal(t, map[int]bool{3: true}, q.left["1!1"])
+}
+
+func TestQueueSkipsEventInFlight(t *testing.T) {
+	// arrange
+	m := &metrics{}
+	q := newQueue(1, 2, m)
+	q.push(&model.WebhookEvent{ID: 1})
+
+	// act
+	pushed := q.push(&model.WebhookEvent{ID: 1})
+
+	// assert
+	assert.False(t, pushed)
+	assert.Equal(t, 1, len(q.shards[0]))
+	assert.Equal(t, int64(0), atomic.LoadInt64(&m.WebhookQueueFull))
+}
+
+func TestRequeueEvents(t *testing.T) {
+	// arrange
+	inFlight := &model.WebhookEvent{ID: 1, ObjectKey: "1!1", Status: model.EventQueued}
+	leftOut := &model.WebhookEvent{ID: 2, ObjectKey: "1!2", Status: model.EventQueued}
+	doneSinceListed := &model.WebhookEvent{ID: 3, ObjectKey: "1!3", Status: model.EventQueued}
+	stubDB := &mDB.Store{}
+	stubDB.On("ListWebhookEvents", model.EventQueued).Return([]*model.WebhookEvent{inFlight, leftOut, doneSinceListed}, nil)
+	stubDB.On("GetWebhookEvent", 2).Return(&model.WebhookEvent{ID: 2, Status: model.EventQueued}, nil)
+	stubDB.On("GetWebhookEvent", 3).Return(&model.WebhookEvent{ID: 3, Status: model.EventDone}, nil)
+	router := getWebhookRouter(stubDB, &mHook.Webhook{}, "")
+	router.queue.push(inFlight)
+
+	// act
+	router.RequeueEvents()
+
+	// assert
+	var ids []int
+	for _, shard := range router.queue.shards {
+		for len(shard) != 0 {
+			ids = append(ids, (<-shard).ID)
+		}
+	}
+	assert.ElementsMatch(t, []int{1, 2}, ids)
+	stubDB.AssertNotCalled(t, "GetWebhookEvent", 1)
+}
+
+func TestProcessEventRecordsPanic(t *testing.T) {
+	// arrange
+	event := &model.WebhookEvent{
+		ID:      1,
+		Event:   "Merge Request Hook",
+		Payload: "{}",
+		Status:  model.EventQueued,
+	}
+	stubDB := &mDB.Store{}
+	stubDB.On("UpdateWebhookEvent", event).Return(nil)
+	stubHook := &mHook.Webhook{}
+	stubHook.On("MergeRequestEvent", mock.Anything).Run(func(mock.Arguments) {
+		panic("fake-panic")
+	})
+	router := getWebhookRouter(stubDB, stubHook, "")
+
+	// act
+	router.processEvent(event)
+
+	// assert
+	stubDB.AssertNumberOfCalls(t, "UpdateWebhookEvent", 1)
+	assert.Equal(t, model.EventFailed, event.Status)
+	assert.Equal(t, "panic: fake-panic", event.Error)
+	assert.NotNil(t, event.ProcessedAt)
+	assert.Equal(t, int64(1), router.metrics.WebhookFailed)
+}
+
+func TestProcessEventRecordsError(t *testing.T) {
+	// arrange
+	event := &model.WebhookEvent{
+		ID:      1,
+		Event:   "Merge Request Hook",
+		Payload: "{}",
+		Status:  model.EventQueued,
+	}
+	stubDB := &mDB.Store{}
+	stubDB.On("UpdateWebhookEvent", event).Return(nil)
+	stubHook := &mHook.Webhook{}
+	stubHook.On("MergeRequestEvent", mock.Anything).Return(errors.New("fake-error"))
+	router := getWebhookRouter(stubDB, stubHook, "")
+
+	// act
+	router.processEvent(event)
+
+	// assert
+	stubDB.AssertNumberOfCalls(t, "UpdateWebhookEvent", 1)
+	assert.Equal(t, model.EventFailed, event.Status)
+	assert.Equal(t, "fake-error", event.Error)
+	assert.Equal(t, int64(1), router.metrics.WebhookFailed)
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"gitlack/model"
+	"gitlack/resource/slack"
+
+	"github.com/gin-gonic/gin"
+	"github.com/robfig/cron"
+)
+
+// DispatchReviewDigests posts the merge requests awaiting review to the channels whose schedule is due
+func (r *router) DispatchReviewDigests() {
+	r.hook.DispatchReviewDigests()
+}
+
+// DispatchStaleReminders reminds and escalates the merge requests idle longer than the thresholds of project
+func (r *router) DispatchStaleReminders() {
+	r.hook.DispatchStaleReminders()
+}
+
+func (r *router) ListReviewDigests(c *gin.Context) {
+	digests, err := r.db.ListReviewDigests()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"ok":    false,
+			"error": "Server error",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"ok":      true,
+		"digests": digests,
+	})
+}
+
+// UpdateReviewDigest sets the schedule of review digest in channel, the first digest is posted at the next scheduled time
+func (r *router) UpdateReviewDigest(c *gin.Context) {
+	schedule := c.Query("schedule")
+	if _, err := cron.ParseStandard(schedule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": fmt.Sprintf("Invalid \"schedule\": %q", schedule),
+		})
+		return
+	}
+
+	channel, ok := r.digestChannel(c)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	err := r.db.CreateReviewDigest(&model.ReviewDigest{
+		Channel:   channel,
+		Schedule:  schedule,
+		LastRunAt: now,
+		CreatedAt: now,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"ok":    false,
+			"error": "Server error",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"ok":      true,
+		"message": fmt.Sprintf("Review digest of %v updated", channel),
+	})
+}
+
+func (r *router) DeleteReviewDigest(c *gin.Context) {
+	channel, ok := r.digestChannel(c)
+	if !ok {
+		return
+	}
+	n, err := r.db.DeleteReviewDigest(channel)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"ok":    false,
+			"error": "Server error",
+		})
+		return
+	}
+	if n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"ok":    false,
+			"error": "Review digest not found",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"ok":      true,
+		"message": fmt.Sprintf("Review digest of %v removed", channel),
+	})
+}
+
+// digestChannel returns the ID of channel in path, the name is resolved to the ID since threads are recorded by ID,
+// the error is responded if it fails
+func (r *router) digestChannel(c *gin.Context) (string, bool) {
+	channel := c.Param("channel")
+	if slack.IsChannelID(channel) {
+		return channel, true
+	}
+	id, err := r.s.GetChannelID(channel)
+	if err == slack.ErrChannelNotFound {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": fmt.Sprintf("Invalid \"channel\": %q not found", channel),
+		})
+		return "", false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"ok":    false,
+			"error": "Server error",
+		})
+		return "", false
+	}
+	return id, true
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gitlack/model"
+	"gitlack/resource/slack"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mSlack "gitlack/resource/slack/mocks"
+	mDB "gitlack/store/mocks"
+)
+
+func serveReviewDigest(r *router, method, target string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/api/review-digest", r.ListReviewDigests)
+	engine.PUT("/api/review-digest/:channel", r.UpdateReviewDigest)
+	engine.DELETE("/api/review-digest/:channel", r.DeleteReviewDigest)
+
+	req, _ := http.NewRequest(method, target, nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestUpdateReviewDigest(t *testing.T) {
+	// arrange
+	mockDB := &mDB.Store{}
+	mockDB.On("CreateReviewDigest", mock.MatchedBy(func(d *model.ReviewDigest) bool {
+		return d.Channel == "C0123456789" && d.Schedule == "0 9 * * 1-5" && !d.LastRunAt.IsZero()
+	})).Return(nil)
+	router := getRouter(mockDB, nil, nil)
+
+	// act
+	w := serveReviewDigest(router, http.MethodPut, "/api/review-digest/C0123456789?schedule=0+9+*+*+1-5")
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockDB.AssertExpectations(t)
+}
+
+func TestUpdateReviewDigestByChannelName(t *testing.T) {
+	input := map[string]int{
+		"fake-team":    http.StatusOK,
+		"fake-unknown": http.StatusBadRequest,
+	}
+	for channel, code := range input {
+		// arrange
+		mockDB := &mDB.Store{}
+		mockDB.On("CreateReviewDigest", mock.MatchedBy(func(d *model.ReviewDigest) bool {
+			return d.Channel == "C0123456789"
+		})).Return(nil)
+		stubSlack := &mSlack.Slack{}
+		stubSlack.On("GetChannelID", "fake-team").Return("C0123456789", nil)
+		stubSlack.On("GetChannelID", "fake-unknown").Return("", slack.ErrChannelNotFound)
+		router := getRouter(mockDB, stubSlack, nil)
+
+		// act
+		w := serveReviewDigest(router, http.MethodPut, "/api/review-digest/"+channel+"?schedule=@daily")
+
+		// assert
+		assert.Equal(t, code, w.Code, channel)
+		if code == http.StatusOK {
+			mockDB.AssertExpectations(t)
+		} else {
+			mockDB.AssertNotCalled(t, "CreateReviewDigest", mock.Anything)
+		}
+	}
+}
+
+func TestUpdateReviewDigestInvalidSchedule(t *testing.T) {
+	for _, target := range []string{"/api/review-digest/C0123456789", "/api/review-digest/C0123456789?schedule=every+morning"} {
+		// arrange
+		mockDB := &mDB.Store{}
+		router := getRouter(mockDB, nil, nil)
+
+		// act
+		w := serveReviewDigest(router, http.MethodPut, target)
+
+		// assert
+		assert.Equal(t, http.StatusBadRequest, w.Code, target)
+		mockDB.AssertNotCalled(t, "CreateReviewDigest", mock.Anything)
+	}
+}
+
+func TestDeleteReviewDigest(t *testing.T) {
+	input := map[string]int{
+		"C0123456789": http.StatusOK,
+		"C0000000000": http.StatusNotFound,
+		"C9999999999": http.StatusInternalServerError,
+	}
+	for channel, code := range input {
+		// arrange
+		stubDB := &mDB.Store{}
+		stubDB.On("DeleteReviewDigest", "C0123456789").Return(int64(1), nil)
+		stubDB.On("DeleteReviewDigest", "C0000000000").Return(int64(0), nil)
+		stubDB.On("DeleteReviewDigest", "C9999999999").Return(int64(0), errors.New("fake error"))
+		router := getRouter(stubDB, nil, nil)
+
+		// act
+		w := serveReviewDigest(router, http.MethodDelete, "/api/review-digest/"+channel)
+
+		// assert
+		assert.Equal(t, code, w.Code, channel)
+	}
+}
@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"gitlack/handler/webhook"
+	"gitlack/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routesRoute returns the path of project or group at `/*path`
+func routesRoute(c *gin.Context) (string, bool) {
+	path := strings.Trim(c.Param("path"), "/")
+	return path, path != ""
+}
+
+func (r *router) GetProjectRoutes(c *gin.Context) {
+	path, ok := r.routesTarget(c, true)
+	if !ok {
+		return
+	}
+	r.getRoutes(c, path)
+}
+
+func (r *router) UpdateProjectRoutes(c *gin.Context) {
+	path, ok := r.routesTarget(c, true)
+	if !ok {
+		return
+	}
+	r.updateRoutes(c, path)
+}
+
+func (r *router) GetGroupRoutes(c *gin.Context) {
+	path, ok := r.routesTarget(c, false)
+	if !ok {
+		return
+	}
+	r.getRoutes(c, path)
+}
+
+func (r *router) UpdateGroupRoutes(c *gin.Context) {
+	path, ok := r.routesTarget(c, false)
+	if !ok {
+		return
+	}
+	r.updateRoutes(c, path)
+}
+
+// TestProjectRoutes explains which channels a message described by the query would be routed to
+func (r *router) TestProjectRoutes(c *gin.Context) {
+	pathWithNamespace, ok := routesRoute(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"ok":    false,
+			"error": "Not found",
+		})
+		return
+	}
+	event := c.Query("event")
+	if event == "" || !webhook.ValidRouteEvent(event) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": fmt.Sprintf("Invalid \"event\": %q", event),
+		})
+		return
+	}
+	p, ok := r.findProject(c, pathWithNamespace)
+	if !ok {
+		return
+	}
+
+	// users are optional, a user who isn't found only matches nothing
+	var author, owner *model.User
+	if email := c.Query("author"); email != "" {
+		author, _ = r.db.GetUserByEmail(email)
+		if author == nil {
+			author = &model.User{Email: email}
+		}
+	}
+	owner = author
+	if email := c.Query("assignee"); email != "" {
+		owner, _ = r.db.GetUserByEmail(email)
+	}
+
+	// changed files are never fetched from GitLab, they're given by query
+	files := []string{}
+	if paths := c.Query("paths"); paths != "" {
+		files = strings.Split(paths, ",")
+	}
+	var labels []string
+	if l := c.Query("labels"); l != "" {
+		labels = strings.Split(l, ",")
+	}
+	route, err := r.hook.ExplainRoute(&webhook.RouteTarget{
+		Event:        event,
+		ProjectID:    p.ID,
+		Path:         pathWithNamespace,
+		Description:  c.Query("description"),
+		Labels:       labels,
+		SourceBranch: c.Query("source_branch"),
+		TargetBranch: c.Query("target_branch"),
+		Author:       author,
+		Owner:        owner,
+		FilePaths:    files,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"ok":    false,
+			"error": "Server error",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"ok":    true,
+		"route": route,
+	})
+}
+
+// routesTarget returns the path of which the routes are, the response is written if it's not ok
+func (r *router) routesTarget(c *gin.Context, isProject bool) (string, bool) {
+	path, ok := routesRoute(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"ok":    false,
+			"error": "Not found",
+		})
+		return "", false
+	}
+	if !isProject {
+		return path, true
+	}
+	if _, ok := r.findProject(c, path); !ok {
+		return "", false
+	}
+	return path, true
+}
+
+// findProject returns the project at path, the response is written if it's not found
+func (r *router) findProject(c *gin.Context, path string) (*model.Project, bool) {
+	p, err := r.db.GetProjectByPath(path)
+	if err != nil {
+		if strings.Contains(err.Error(), "sql: no rows in result set") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"ok":    false,
+				"error": "Project not found",
+			})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"ok":    false,
+			"error": "Server error",
+		})
+		return nil, false
+	}
+	return p, true
+}
+
+// getRoutes responds the rules in effect in order, rules of path come before the ones inherited from groups
+func (r *router) getRoutes(c *gin.Context, path string) {
+	routes, err := r.db.ListRoutes(webhook.TemplatePaths(path))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"ok":    false,
+			"error": "Server error",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"ok":     true,
+		"routes": routes,
+	})
+}
+
+// updateRoutes replaces the rules of path with the JSON array in body, an empty array removes them
+func (r *router) updateRoutes(c *gin.Context, path string) {
+	var routes []*model.Route
+	if err := c.ShouldBindJSON(&routes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": fmt.Sprintf("Invalid routes: %v", err),
+		})
+		return
+	}
+	now := time.Now()
+	for i, route := range routes {
+		if err := validateRoute(route); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"ok":    false,
+				"error": fmt.Sprintf("Invalid route %v: %v", i, err),
+			})
+			return
+		}
+		route.CreatedAt = now
+	}
+
+	err := r.db.ReplaceRoutes(path, routes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"ok":    false,
+			"error": "Server error",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"ok":      true,
+		"message": fmt.Sprintf("Routes of %v updated", path),
+	})
+}
+
+func validateRoute(route *model.Route) error {
+	if route == nil {
+		return fmt.Errorf("empty route")
+	}
+	if !webhook.ValidRouteEvent(route.Event) {
+		return fmt.Errorf("invalid \"event\": %q", route.Event)
+	}
+	if strings.Trim(route.Channels, ", ") == "" {
+		return fmt.Errorf("no channel")
+	}
+	for _, patterns := range []string{route.SourceBranch, route.TargetBranch, route.FilePaths} {
+		for _, p := range strings.Split(patterns, ",") {
+			if _, err := path.Match(strings.TrimSpace(p), ""); err != nil {
+				return fmt.Errorf("invalid pattern %q: %v", p, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gitlack/handler/webhook"
+	"gitlack/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mHook "gitlack/handler/webhook/mocks"
+	mDB "gitlack/store/mocks"
+)
+
+func serveRoutes(r *router, method, target, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/api/routes/project/*path", r.GetProjectRoutes)
+	engine.PUT("/api/routes/project/*path", r.UpdateProjectRoutes)
+	engine.GET("/api/routes/group/*path", r.GetGroupRoutes)
+	engine.PUT("/api/routes/group/*path", r.UpdateGroupRoutes)
+	engine.GET("/api/routes/test/*path", r.TestProjectRoutes)
+	engine.GET("/api/project/:namespace/*path", r.GetProject)
+
+	req, _ := http.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestGetProjectRoutes(t *testing.T) {
+	// arrange
+	stubDB := &mDB.Store{}
+	stubDB.On("GetProjectByPath", "fake/fake-project").Return(&model.Project{}, nil)
+	stubDB.On("ListRoutes", []string{"fake/fake-project", "fake"}).Return([]*model.Route{
+		{Path: "fake/fake-project", Labels: "bug", Channels: "fake-bug"},
+		{Path: "fake", Channels: "fake-group"},
+	}, nil)
+	router := getRouter(stubDB, nil, nil)
+
+	// act
+	w := serveRoutes(router, http.MethodGet, "/api/routes/project/fake/fake-project", "")
+
+	// assert
+	var res struct {
+		Routes []*model.Route `json:"routes"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, res.Routes, 2)
+	assert.Equal(t, "fake/fake-project", res.Routes[0].Path)
+	assert.Equal(t, "fake-group", res.Routes[1].Channels)
+}
+
+func TestUpdateProjectRoutes(t *testing.T) {
+	// arrange
+	mockDB := &mDB.Store{}
+	mockDB.On("GetProjectByPath", "fake/fake-project").Return(&model.Project{}, nil)
+	mockDB.On("ReplaceRoutes", "fake/fake-project", mock.MatchedBy(func(routes []*model.Route) bool {
+		return len(routes) == 2 && routes[0].Labels == "bug" && routes[1].FilePaths == "docs/**" && !routes[1].CreatedAt.IsZero()
+	})).Return(nil)
+	router := getRouter(mockDB, nil, nil)
+	body := `[
+		{"event": "merge_request", "labels": "bug", "channels": "fake-bug"},
+		{"file_paths": "docs/**", "channels": "fake-docs,fake-writers"}
+	]`
+
+	// act
+	w := serveRoutes(router, http.MethodPut, "/api/routes/project/fake/fake-project", body)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockDB.AssertExpectations(t)
+}
+
+func TestUpdateGroupRoutesInvalid(t *testing.T) {
+	input := map[string]string{
+		`{"channels": "fake"}`:                         `Invalid routes: `,
+		`[{"event": "comment", "channels": "fake"}]`:   `Invalid route 0: invalid \"event\": \"comment\"`,
+		`[{"channels": "fake"}, {"labels": "bug"}]`:    `Invalid route 1: no channel`,
+		`[{"source_branch": "[", "channels": "fake"}]`: `Invalid route 0: invalid pattern`,
+	}
+	for body, expected := range input {
+		// arrange
+		mockDB := &mDB.Store{}
+		router := getRouter(mockDB, nil, nil)
+
+		// act
+		w := serveRoutes(router, http.MethodPut, "/api/routes/group/fake/sub", body)
+
+		// assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), expected)
+		mockDB.AssertNotCalled(t, "ReplaceRoutes", mock.Anything, mock.Anything)
+	}
+}
+
+func TestUpdateGroupRoutes(t *testing.T) {
+	// arrange
+	mockDB := &mDB.Store{}
+	mockDB.On("ReplaceRoutes", "fake/sub", []*model.Route{}).Return(nil)
+	router := getRouter(mockDB, nil, nil)
+
+	// act
+	w := serveRoutes(router, http.MethodPut, "/api/routes/group/fake/sub", "[]")
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockDB.AssertExpectations(t)
+}
+
+func TestTestProjectRoutes(t *testing.T) {
+	// arrange
+	stubDB := &mDB.Store{}
+	stubDB.On("GetProjectByPath", "fake/fake-project").Return(&model.Project{ID: 1}, nil)
+	stubDB.On("GetUserByEmail", "fake-author").Return(&model.User{Email: "fake-author"}, nil)
+	rule := &model.Route{Path: "fake", Position: 2, Channels: "fake-docs"}
+	mockHook := &mHook.Webhook{}
+	mockHook.On("ExplainRoute", &webhook.RouteTarget{
+		Event:        "merge_request",
+		ProjectID:    1,
+		Path:         "fake/fake-project",
+		Labels:       []string{"bug", "docs"},
+		TargetBranch: "master",
+		Author:       &model.User{Email: "fake-author"},
+		Owner:        &model.User{Email: "fake-author"},
+		FilePaths:    []string{"docs/a.md"},
+	}).Return(&webhook.Route{Channels: []string{"fake-docs"}, Reason: webhook.RouteByRule, Rule: rule}, nil)
+	router := getRouter(stubDB, nil, nil)
+	router.hook = mockHook
+
+	// act
+	w := serveRoutes(router, http.MethodGet, "/api/routes/test/fake/fake-project?event=merge_request&labels=bug,docs&target_branch=master&author=fake-author&paths=docs/a.md", "")
+
+	// assert
+	var res struct {
+		Route *webhook.Route `json:"route"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"fake-docs"}, res.Route.Channels)
+	assert.Equal(t, "rule", res.Route.Reason)
+	assert.Equal(t, 2, res.Route.Rule.Position)
+}
+
+func TestTestProjectRoutesError(t *testing.T) {
+	input := map[string]int{
+		"/api/routes/test/fake/fake-project":                  http.StatusBadRequest,
+		"/api/routes/test/fake/fake-project?event=comment":    http.StatusBadRequest,
+		"/api/routes/test/fake/not-found?event=merge_request": http.StatusNotFound,
+		"/api/routes/test/fake/fake-project?event=tag_push":   http.StatusInternalServerError,
+		"/api/routes/test/fake/fake-project?event=issue&x=1":  http.StatusInternalServerError,
+		"/api/routes/test/?event=merge_request":               http.StatusNotFound,
+	}
+	for target, code := range input {
+		// arrange
+		stubDB := &mDB.Store{}
+		stubDB.On("GetProjectByPath", "fake/fake-project").Return(&model.Project{}, nil)
+		stubDB.On("GetProjectByPath", "fake/not-found").Return(nil, errors.New("sql: no rows in result set"))
+		stubHook := &mHook.Webhook{}
+		stubHook.On("ExplainRoute", mock.Anything).Return(nil, errors.New("fake error"))
+		router := getRouter(stubDB, nil, nil)
+		router.hook = stubHook
+
+		// act
+		w := serveRoutes(router, http.MethodGet, target, "")
+
+		// assert
+		assert.Equal(t, code, w.Code, target)
+	}
+}
+
+func TestGetProjectNamedRoutes(t *testing.T) {
+	// arrange
+	stubDB := &mDB.Store{}
+	stubDB.On("GetProjectByPath", "fake/routes").Return(&model.Project{ID: 1, Name: "fake/routes"}, nil)
+	router := getRouter(stubDB, nil, nil)
+
+	// act
+	w := serveRoutes(router, http.MethodGet, "/api/project/fake/routes", "")
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	stubDB.AssertNotCalled(t, "ListRoutes", mock.Anything)
+}
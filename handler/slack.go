@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// slackRequestMaxAge rejects the requests signed earlier, which may be replayed
+const slackRequestMaxAge = 5 * time.Minute
+
+// verifySignature checks X-Slack-Signature of body against the signing secret,
+// every request is rejected if the signing secret isn't set
+// ref: https://api.slack.com/authentication/verifying-requests-from-slack
+func (r *router) verifySignature(c *gin.Context, body []byte) bool {
+	if r.signingSecret == "" {
+		logrus.Warnln("Slack request rejected, signing secret is not set")
+		return false
+	}
+
+	ts := c.GetHeader("X-Slack-Request-Timestamp")
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		logrus.Warnf("Slack request rejected, invalid timestamp: %q", ts)
+		return false
+	}
+	if age := time.Since(time.Unix(sec, 0)); age > slackRequestMaxAge || age < -slackRequestMaxAge {
+		logrus.Warnf("Slack request rejected, timestamp too old: %v", ts)
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(r.signingSecret))
+	fmt.Fprintf(mac, "v0:%v:%s", ts, body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if hmac.Equal([]byte(expected), []byte(c.GetHeader("X-Slack-Signature"))) {
+		return true
+	}
+	logrus.Warnf("Slack request rejected, invalid X-Slack-Signature from %v", c.ClientIP())
+	return false
+}
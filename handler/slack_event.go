@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"gitlack/handler/webhook"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// slackEventPayload is the part of Events API payload used by Gitlack
+// ref: https://api.slack.com/apis/connections/events-api
+type slackEventPayload struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	EventID   string `json:"event_id"`
+	Event     struct {
+		Type     string `json:"type"`
+		Subtype  string `json:"subtype"`
+		BotID    string `json:"bot_id"`
+		User     string `json:"user"`
+		Text     string `json:"text"`
+		Channel  string `json:"channel"`
+		TS       string `json:"ts"`
+		ThreadTS string `json:"thread_ts"`
+	} `json:"event"`
+}
+
+// SlackEvent receives the messages in channels Gitlack is in, the replies in threads
+// are commented on the merge request or issue, Slack is acknowledged at once
+func (r *router) SlackEvent(c *gin.Context) {
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		logrus.Errorln(err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": "Invalid request body",
+		})
+		return
+	}
+	if !r.verifySignature(c, body) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"ok":    false,
+			"error": "Invalid X-Slack-Signature",
+		})
+		return
+	}
+	var payload slackEventPayload
+	err = json.Unmarshal(body, &payload)
+	if err != nil {
+		logrus.Errorln(err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": "Invalid payload",
+		})
+		return
+	}
+
+	// Slack verifies the request URL when it's saved
+	if payload.Type == "url_verification" {
+		c.JSON(http.StatusOK, gin.H{
+			"challenge": payload.Challenge,
+		})
+		return
+	}
+	if payload.Type == "event_callback" && isThreadReply(&payload) && !r.isDuplicate(payload.EventID) {
+		reply := &webhook.Reply{
+			Channel:  payload.Event.Channel,
+			ThreadTS: payload.Event.ThreadTS,
+			SlackID:  payload.Event.User,
+			Text:     payload.Event.Text,
+		}
+		go r.hook.SlackReply(reply)
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// ignoredSubtypes are the subtypes of message which aren't replies of user, such as edits, deletions, bots and joins,
+// the replies also sent to channel (`thread_broadcast`) and the ones with files (`file_share`) are kept
+// ref: https://api.slack.com/events/message#subtypes
+var ignoredSubtypes = map[string]bool{
+	"bot_message":       true,
+	"channel_join":      true,
+	"channel_leave":     true,
+	"channel_topic":     true,
+	"channel_purpose":   true,
+	"channel_name":      true,
+	"channel_archive":   true,
+	"channel_unarchive": true,
+	"group_join":        true,
+	"group_leave":       true,
+	"group_topic":       true,
+	"group_purpose":     true,
+	"group_name":        true,
+	"group_archive":     true,
+	"group_unarchive":   true,
+	"message_changed":   true,
+	"message_deleted":   true,
+	"message_replied":   true,
+	"pinned_item":       true,
+	"unpinned_item":     true,
+	"reminder_add":      true,
+	"tombstone":         true,
+}
+
+// isThreadReply reports whether the event is a message replied by a user in thread,
+// the messages of bots including Gitlack itself, edits and deletions are ignored
+func isThreadReply(payload *slackEventPayload) bool {
+	e := payload.Event
+	if e.Type != "message" || ignoredSubtypes[e.Subtype] || e.BotID != "" || e.User == "" {
+		return false
+	}
+	return e.ThreadTS != "" && e.ThreadTS != e.TS
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlack/handler/webhook"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mWebhook "gitlack/handler/webhook/mocks"
+	mDB "gitlack/store/mocks"
+)
+
+func serveSlackEvent(r *router, body, secret string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.POST("/api/slack/events", r.SlackEvent)
+
+	req, _ := http.NewRequest(http.MethodPost, "/api/slack/events", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	signSlackRequest(req, secret, body, time.Now())
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestSlackEventURLVerification(t *testing.T) {
+	// arrange
+	router := &router{signingSecret: "fake-secret"}
+
+	// act
+	verified := serveSlackEvent(router, `{"type": "url_verification", "challenge": "fake-challenge"}`, "fake-secret")
+	rejected := serveSlackEvent(router, `{"type": "url_verification", "challenge": "fake-challenge"}`, "fake-wrong-secret")
+
+	// assert
+	assert.Equal(t, http.StatusOK, verified.Code)
+	assert.JSONEq(t, `{"challenge": "fake-challenge"}`, verified.Body.String())
+	assert.Equal(t, http.StatusUnauthorized, rejected.Code)
+}
+
+func TestSlackEventThreadReply(t *testing.T) {
+	// arrange
+	done := make(chan *webhook.Reply, 1)
+	mockedHook := &mWebhook.Webhook{}
+	mockedHook.On("SlackReply", mock.Anything).Run(func(args mock.Arguments) {
+		done <- args.Get(0).(*webhook.Reply)
+	})
+	mockedDB := &mDB.Store{}
+	mockedDB.On("CreateEventUUID", "fake-event-id", mock.Anything).Return(true, nil)
+	router := &router{db: mockedDB, hook: mockedHook, signingSecret: "fake-secret", metrics: &metrics{}}
+	body := `{"type": "event_callback", "event_id": "fake-event-id", "event": {"type": "message", "user": "fake-slack-id",
+		"text": "fake-text", "channel": "fake-channel", "ts": "2.000000", "thread_ts": "1.000000"}}`
+
+	// act
+	w := serveSlackEvent(router, body, "fake-secret")
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	select {
+	case reply := <-done:
+		assert.Equal(t, &webhook.Reply{Channel: "fake-channel", ThreadTS: "1.000000", SlackID: "fake-slack-id", Text: "fake-text"}, reply)
+	case <-time.After(time.Second):
+		t.Fatal("reply should be synchronized")
+	}
+}
+
+func TestIsThreadReply(t *testing.T) {
+	input := map[string]bool{
+		`{"type": "message", "user": "U1", "ts": "2", "thread_ts": "1"}`:                                true,
+		`{"type": "message", "user": "U1", "ts": "1"}`:                                                  false,
+		`{"type": "message", "user": "U1", "ts": "1", "thread_ts": "1"}`:                                false,
+		`{"type": "message", "bot_id": "B1", "ts": "2", "thread_ts": "1"}`:                              false,
+		`{"type": "message", "user": "U1", "bot_id": "B1", "ts": "2", "thread_ts": "1"}`:                false,
+		`{"type": "message", "subtype": "message_changed", "user": "U1", "ts": "2", "thread_ts": "1"}`:  false,
+		`{"type": "message", "subtype": "channel_join", "user": "U1", "ts": "2", "thread_ts": "1"}`:     false,
+		`{"type": "message", "subtype": "thread_broadcast", "user": "U1", "ts": "2", "thread_ts": "1"}`: true,
+		`{"type": "message", "subtype": "file_share", "user": "U1", "ts": "2", "thread_ts": "1"}`:       true,
+		`{"type": "app_mention", "user": "U1", "ts": "2", "thread_ts": "1"}`:                            false,
+	}
+	for event, expected := range input {
+		var payload slackEventPayload
+		json.Unmarshal([]byte(`{"event": `+event+`}`), &payload)
+		assert.Equal(t, expected, isThreadReply(&payload), event)
+	}
+}
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"gitlack/handler/webhook"
+	"gitlack/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// templateRoute returns the path of project or group and the event of template at `/*path?event=:event`
+func templateRoute(c *gin.Context) (string, string, bool) {
+	path := strings.Trim(c.Param("path"), "/")
+	if path == "" {
+		return "", "", false
+	}
+	return path, c.Query("event"), true
+}
+
+func (r *router) GetProjectTemplate(c *gin.Context) {
+	path, event, ok := r.templateTarget(c, true)
+	if !ok {
+		return
+	}
+	r.getTemplate(c, path, event)
+}
+
+func (r *router) UpdateProjectTemplate(c *gin.Context) {
+	path, event, ok := r.templateTarget(c, true)
+	if !ok {
+		return
+	}
+	r.updateTemplate(c, path, event)
+}
+
+func (r *router) DeleteProjectTemplate(c *gin.Context) {
+	path, event, ok := r.templateTarget(c, true)
+	if !ok {
+		return
+	}
+	r.deleteTemplate(c, path, event)
+}
+
+func (r *router) GetGroupTemplate(c *gin.Context) {
+	path, event, ok := r.templateTarget(c, false)
+	if !ok {
+		return
+	}
+	r.getTemplate(c, path, event)
+}
+
+func (r *router) UpdateGroupTemplate(c *gin.Context) {
+	path, event, ok := r.templateTarget(c, false)
+	if !ok {
+		return
+	}
+	r.updateTemplate(c, path, event)
+}
+
+func (r *router) DeleteGroupTemplate(c *gin.Context) {
+	path, event, ok := r.templateTarget(c, false)
+	if !ok {
+		return
+	}
+	r.deleteTemplate(c, path, event)
+}
+
+// templateTarget returns the path and event of template, the response is written if it's not ok
+func (r *router) templateTarget(c *gin.Context, isProject bool) (string, string, bool) {
+	path, event, ok := templateRoute(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"ok":    false,
+			"error": "Not found",
+		})
+		return "", "", false
+	}
+	if _, ok := webhook.DefaultTemplate(event); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": fmt.Sprintf("Invalid event: %q", event),
+		})
+		return "", "", false
+	}
+	if !isProject {
+		return path, event, true
+	}
+
+	// check project exists
+	_, err := r.db.GetProjectByPath(path)
+	if err != nil {
+		if strings.Contains(err.Error(), "sql: no rows in result set") {
+			c.JSON(http.StatusNotFound, gin.H{
+				"ok":    false,
+				"error": "Project not found",
+			})
+			return "", "", false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"ok":    false,
+			"error": "Server error",
+		})
+		return "", "", false
+	}
+	return path, event, true
+}
+
+// getTemplate responds the template in effect, it's inherited from the nearest group or the default one
+func (r *router) getTemplate(c *gin.Context, path, event string) {
+	templates, err := r.db.ListTemplates(event, webhook.TemplatePaths(path))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"ok":    false,
+			"error": "Server error",
+		})
+		return
+	}
+
+	text, _ := webhook.DefaultTemplate(event)
+	source := "default"
+	if len(templates) != 0 {
+		text = templates[0].Text
+		source = templates[0].Path
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"ok": true,
+		"template": gin.H{
+			"event":  event,
+			"text":   text,
+			"source": source,
+		},
+	})
+}
+
+func (r *router) updateTemplate(c *gin.Context, path, event string) {
+	// template is taken from body since it's usually multi-line
+	text := c.PostForm("template")
+	if err := webhook.ValidateTemplate(event, text); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": fmt.Sprintf("Invalid \"template\": %v", err),
+		})
+		return
+	}
+
+	err := r.db.CreateTemplate(&model.Template{
+		Path:      path,
+		Event:     event,
+		Text:      text,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"ok":    false,
+			"error": "Server error",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"ok":      true,
+		"message": fmt.Sprintf("Template: %v of %v updated", event, path),
+	})
+}
+
+func (r *router) deleteTemplate(c *gin.Context, path, event string) {
+	n, err := r.db.DeleteTemplate(path, event)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"ok":    false,
+			"error": "Server error",
+		})
+		return
+	}
+	if n == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"ok":    false,
+			"error": "Template not found",
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"ok":      true,
+		"message": fmt.Sprintf("Template: %v of %v removed", event, path),
+	})
+}
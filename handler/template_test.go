@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"gitlack/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mDB "gitlack/store/mocks"
+)
+
+func serveTemplate(r *router, method, target string, form url.Values) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/api/templates/project/*path", r.GetProjectTemplate)
+	engine.PUT("/api/templates/project/*path", r.UpdateProjectTemplate)
+	engine.DELETE("/api/templates/project/*path", r.DeleteProjectTemplate)
+	engine.GET("/api/templates/group/*path", r.GetGroupTemplate)
+	engine.PUT("/api/templates/group/*path", r.UpdateGroupTemplate)
+	engine.GET("/api/project/:namespace/*path", r.GetProject)
+
+	req, _ := http.NewRequest(method, target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestGetProjectTemplateInherited(t *testing.T) {
+	// arrange
+	stubDB := &mDB.Store{}
+	stubDB.On("GetProjectByPath", "fake/sub/fake-project").Return(&model.Project{}, nil)
+	stubDB.On("ListTemplates", "merge_request", []string{"fake/sub/fake-project", "fake/sub", "fake"}).Return([]*model.Template{
+		{Path: "fake/sub", Event: "merge_request", Text: "fake-text"},
+	}, nil)
+	router := getRouter(stubDB, nil, nil)
+
+	// act
+	w := serveTemplate(router, http.MethodGet, "/api/templates/project/fake/sub/fake-project?event=merge_request", nil)
+
+	// assert
+	var res struct {
+		Template map[string]string `json:"template"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &res)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "fake-text", res.Template["text"])
+	assert.Equal(t, "fake/sub", res.Template["source"])
+}
+
+func TestGetProjectTemplateDefault(t *testing.T) {
+	// arrange
+	stubDB := &mDB.Store{}
+	stubDB.On("GetProjectByPath", "fake/fake-project").Return(&model.Project{}, nil)
+	stubDB.On("ListTemplates", "issue", mock.Anything).Return([]*model.Template{}, nil)
+	router := getRouter(stubDB, nil, nil)
+
+	// act
+	w := serveTemplate(router, http.MethodGet, "/api/templates/project/fake/fake-project?event=issue", nil)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"source":"default"`)
+}
+
+func TestGetProjectTemplateInvalidEvent(t *testing.T) {
+	// arrange
+	router := getRouter(&mDB.Store{}, nil, nil)
+
+	// act
+	w := serveTemplate(router, http.MethodGet, "/api/templates/project/fake/fake-project?event=fake-event", nil)
+
+	// assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `Invalid event: \"fake-event\"`)
+}
+
+func TestUpdateProjectTemplate(t *testing.T) {
+	// arrange
+	stubDB := &mDB.Store{}
+	stubDB.On("GetProjectByPath", "fake/fake-project").Return(&model.Project{}, nil)
+	stubDB.On("CreateTemplate", mock.Anything).Return(nil)
+	router := getRouter(stubDB, nil, nil)
+
+	// act
+	w := serveTemplate(router, http.MethodPut, "/api/templates/project/fake/fake-project?event=comment", url.Values{
+		"template": {"{{.Author}} said:\n{{.Desc}}"},
+	})
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	saved := stubDB.Calls[1].Arguments.Get(0).(*model.Template)
+	assert.Equal(t, "fake/fake-project", saved.Path)
+	assert.Equal(t, "comment", saved.Event)
+	assert.Equal(t, "{{.Author}} said:\n{{.Desc}}", saved.Text)
+}
+
+func TestUpdateProjectTemplateInvalidTemplate(t *testing.T) {
+	input := []string{"", "{{.Author", "{{.Unknown}}"}
+	for _, tmpl := range input {
+		// arrange
+		stubDB := &mDB.Store{}
+		stubDB.On("GetProjectByPath", "fake/fake-project").Return(&model.Project{}, nil)
+		router := getRouter(stubDB, nil, nil)
+
+		// act
+		w := serveTemplate(router, http.MethodPut, "/api/templates/project/fake/fake-project?event=issue", url.Values{
+			"template": {tmpl},
+		})
+
+		// assert
+		assert.Equal(t, http.StatusBadRequest, w.Code, "template %q should be rejected", tmpl)
+		stubDB.AssertNotCalled(t, "CreateTemplate", mock.Anything)
+	}
+}
+
+func TestUpdateProjectTemplateProjectNotFound(t *testing.T) {
+	// arrange
+	stubDB := &mDB.Store{}
+	stubDB.On("GetProjectByPath", "fake/fake-project").Return(nil, errors.New("sql: no rows in result set"))
+	router := getRouter(stubDB, nil, nil)
+
+	// act
+	w := serveTemplate(router, http.MethodPut, "/api/templates/project/fake/fake-project?event=issue", url.Values{
+		"template": {"{{.Author}}"},
+	})
+
+	// assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUpdateGroupTemplate(t *testing.T) {
+	// arrange
+	stubDB := &mDB.Store{}
+	stubDB.On("CreateTemplate", mock.Anything).Return(nil)
+	router := getRouter(stubDB, nil, nil)
+
+	// act
+	w := serveTemplate(router, http.MethodPut, "/api/templates/group/fake/sub?event=tag_push", url.Values{
+		"template": {"{{.Tag}} of {{.Path}}"},
+	})
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	saved := stubDB.Calls[0].Arguments.Get(0).(*model.Template)
+	assert.Equal(t, "fake/sub", saved.Path)
+	stubDB.AssertNotCalled(t, "UpdateGroupDefaultChannel", mock.Anything, mock.Anything)
+}
+
+func TestDeleteProjectTemplate(t *testing.T) {
+	input := map[int64]int{
+		1: http.StatusOK,
+		0: http.StatusNotFound,
+	}
+	for n, expected := range input {
+		// arrange
+		stubDB := &mDB.Store{}
+		stubDB.On("GetProjectByPath", "fake/fake-project").Return(&model.Project{}, nil)
+		stubDB.On("DeleteTemplate", "fake/fake-project", "merge_request").Return(n, nil)
+		router := getRouter(stubDB, nil, nil)
+
+		// act
+		w := serveTemplate(router, http.MethodDelete, "/api/templates/project/fake/fake-project?event=merge_request", nil)
+
+		// assert
+		assert.Equal(t, expected, w.Code)
+	}
+}
+
+func TestGetProjectInSubgroupNamedTemplates(t *testing.T) {
+	// arrange
+	stubDB := &mDB.Store{}
+	stubDB.On("GetProjectByPath", "fake/templates/issue").Return(&model.Project{ID: 1, Name: "fake/templates/issue"}, nil)
+	router := getRouter(stubDB, nil, nil)
+
+	// act
+	w := serveTemplate(router, http.MethodGet, "/api/project/fake/templates/issue", nil)
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	stubDB.AssertNotCalled(t, "ListTemplates", mock.Anything, mock.Anything)
+}
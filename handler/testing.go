@@ -1,14 +1,19 @@
 package handler
 
 import (
+	"bytes"
 	"fmt"
 	"gitlack/model"
 	"gitlack/resource/gitlab"
 	"gitlack/resource/slack"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/mock"
 
+	mHook "gitlack/handler/webhook/mocks"
 	mGitLab "gitlack/resource/gitlab/mocks"
 	mSlack "gitlack/resource/slack/mocks"
 	mDB "gitlack/store/mocks"
@@ -117,3 +122,89 @@ func getStubGetProjectGitLab(project []*model.Project) *mGitLab.GitLab {
 
 	return g
 }
+
+func getWebhookRouter(db *mDB.Store, hook *mHook.Webhook, secret string) *router {
+	m := &metrics{}
+	return &router{
+		db:      db,
+		hook:    hook,
+		secret:  secret,
+		queue:   newQueue(1, 10, m),
+		metrics: m,
+	}
+}
+
+// drainQueue processes the queued events without starting workers
+func drainQueue(r *router) {
+	for _, shard := range r.queue.shards {
+		for len(shard) != 0 {
+			e := <-shard
+			r.processEvent(e)
+			r.queue.done(e)
+		}
+	}
+}
+
+func getStubMergeRequestHook() *mHook.Webhook {
+	h := &mHook.Webhook{}
+	h.On("MergeRequestEvent", mock.Anything).Return(nil)
+
+	return h
+}
+
+func getStubGetProjectByIDDB(p *model.Project, err error) *mDB.Store {
+	db := &mDB.Store{}
+	db.On("GetProjectByID", mock.Anything).Return(p, err)
+	db.On("CountProjectWebhookSecrets").Return(0, nil)
+	db.On("CreateWebhookEvent", mock.Anything).Return(nil)
+
+	return db
+}
+
+func serveWebhook(r *router, token string) *httptest.ResponseRecorder {
+	return serveWebhookWithUUID(r, token, "")
+}
+
+func serveWebhookWithUUID(r *router, token, uuid string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.POST("/", r.Webhook)
+
+	body := []byte(`{"project": {"id": 1, "path_with_namespace": "fake/fake-project"}}`)
+	req, _ := http.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("X-Gitlab-Event", "Merge Request Hook")
+	if token != "" {
+		req.Header.Set("X-Gitlab-Token", token)
+	}
+	if uuid != "" {
+		req.Header.Set("X-Gitlab-Event-UUID", uuid)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	return w
+}
+
+func serveUpdateProject(r *router, query string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.PUT("/api/project/:namespace/*path", r.UpdateProject)
+
+	req, _ := http.NewRequest(http.MethodPut, "/api/project/fake/fake-project?"+query, nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	return w
+}
+
+func serveUpdateUser(r *router, query string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.PUT("/api/user/:email", r.UpdateUser)
+
+	req, _ := http.NewRequest(http.MethodPut, "/api/user/fake-user?"+query, nil)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	return w
+}
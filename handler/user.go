@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"gitlack/model"
@@ -39,18 +40,27 @@ func (r *router) GetUser(c *gin.Context) {
 
 func (r *router) UpdateUser(c *gin.Context) {
 	defaultChannel := c.Query("default_channel")
-	if defaultChannel == "" {
-		logrus.Debugln("Default channel not found")
+	notifyDM, hasNotifyDM := c.GetQuery("notify_dm")
+	if defaultChannel == "" && !hasNotifyDM {
+		logrus.Debugln("Default channel and notify DM not found")
 		c.JSON(http.StatusBadRequest, gin.H{
 			"ok":    false,
 			"error": fmt.Sprintf("Invalid \"default_channel\": %q", defaultChannel),
 		})
 		return
 	}
+	notify, err := strconv.ParseBool(notifyDM)
+	if hasNotifyDM && err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"ok":    false,
+			"error": fmt.Sprintf("Invalid \"notify_dm\": %q", notifyDM),
+		})
+		return
+	}
 
 	// check user exists
 	email := c.Param("email")
-	_, err := r.db.GetUserByEmail(email)
+	u, err := r.db.GetUserByEmail(email)
 	if err != nil {
 		if strings.Contains(err.Error(), "sql: no rows in result set") {
 			c.JSON(http.StatusNotFound, gin.H{
@@ -67,13 +77,31 @@ func (r *router) UpdateUser(c *gin.Context) {
 	}
 
 	// update user default channel
-	err = r.db.UpdateUserDefaultChannel(email, defaultChannel)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"ok":    false,
-			"error": "Server error",
-		})
-		return
+	if defaultChannel != "" {
+		err = r.db.UpdateUserDefaultChannel(email, defaultChannel)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"ok":    false,
+				"error": "Server error",
+			})
+			return
+		}
+	}
+
+	// notify_dm is the shorthand of delivery, mentions are sent both in channel and by direct message
+	if hasNotifyDM {
+		u.Delivery = model.DeliveryChannel
+		if notify {
+			u.Delivery = model.DeliveryBoth
+		}
+		err = r.db.UpdateUserPreferences(u)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"ok":    false,
+				"error": "Server error",
+			})
+			return
+		}
 	}
 	c.JSON(http.StatusOK, gin.H{
 		"ok":      true,
@@ -2,9 +2,15 @@ package handler
 
 import (
 	"fmt"
+	"net/http"
 	"testing"
 
+	"gitlack/model"
+
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mDB "gitlack/store/mocks"
 )
 
 func TestSyncUserWithFiveUsers(t *testing.T) {
@@ -104,3 +110,32 @@ func TestSyncUserWithCreateUserFail(t *testing.T) {
 	// assert
 	assert.Error(t, err, "Error should not be nil")
 }
+
+func TestUpdateUserNotifyDM(t *testing.T) {
+	// arrange
+	stubDB := &mDB.Store{}
+	stubDB.On("GetUserByEmail", "fake-user").Return(&model.User{Email: "fake-user", MutedEvents: "issue_assigned"}, nil)
+	stubDB.On("UpdateUserPreferences", &model.User{Email: "fake-user", Delivery: model.DeliveryBoth, MutedEvents: "issue_assigned"}).Return(nil)
+	router := getRouter(stubDB, nil, nil)
+
+	// act
+	w := serveUpdateUser(router, "notify_dm=true")
+
+	// assert
+	assert.Equal(t, http.StatusOK, w.Code, "Status code should be 200")
+	stubDB.AssertNumberOfCalls(t, "UpdateUserPreferences", 1)
+	stubDB.AssertNotCalled(t, "UpdateUserDefaultChannel", mock.Anything, mock.Anything)
+}
+
+func TestUpdateUserInvalidNotifyDM(t *testing.T) {
+	// arrange
+	stubDB := &mDB.Store{}
+	router := getRouter(stubDB, nil, nil)
+
+	// act
+	w := serveUpdateUser(router, "default_channel=fake-channel&notify_dm=sometimes")
+
+	// assert
+	assert.Equal(t, http.StatusBadRequest, w.Code, "Status code should be 400")
+	stubDB.AssertNotCalled(t, "UpdateUserDefaultChannel", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,183 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gitlack/model"
+	"gitlack/resource/gitlab"
+	"gitlack/resource/slack"
+
+	"github.com/sirupsen/logrus"
+)
+
+// action_id of the buttons on the root message of merge request
+const (
+	ActionApprove = "mr_approve"
+	ActionMerge   = "mr_merge"
+	ActionClose   = "mr_close"
+)
+
+// outcomeBlockID identifies the block showing the outcome of the last action on the root message
+const outcomeBlockID = "mr_outcome"
+
+// actionVerbs names the actions in the replies to user
+var actionVerbs = map[string]string{
+	ActionApprove: "approve",
+	ActionMerge:   "merge",
+	ActionClose:   "close",
+}
+
+// Action is a button on the root message of merge request clicked by the Slack user
+type Action struct {
+	Name        string
+	ProjectID   int
+	MRNum       int
+	SlackID     string
+	ResponseURL string
+}
+
+// mrActionButtons returns the buttons acting on the merge request as the user clicking them,
+// value is the project ID and the number of merge request
+func mrActionButtons(projectID, mrNum int) []*slack.Button {
+	value := fmt.Sprintf("%v:%v", projectID, mrNum)
+	return []*slack.Button{
+		slack.NewActionButton("Approve", ActionApprove, value, "primary"),
+		slack.NewActionButton("Merge when pipeline succeeds", ActionMerge, value, ""),
+		slack.NewActionButton("Close", ActionClose, value, "danger"),
+	}
+}
+
+// MergeRequestAction performs the action on merge request in GitLab as the user linked to the Slack user,
+// the outcome is shown on the root message, or replied to the user only if it fails
+func (h *hook) MergeRequestAction(a *Action) {
+	verb, ok := actionVerbs[a.Name]
+	if !ok {
+		logrus.Warnf("unknown action %v of merge request %v!%v", a.Name, a.ProjectID, a.MRNum)
+		return
+	}
+	u, err := h.db.GetUserBySlackID(a.SlackID)
+	if err != nil {
+		if strings.Contains(err.Error(), "sql: no rows in result set") {
+			h.respond(a, "Your Slack account isn't linked to a GitLab user yet, users are synchronized by email every midnight.")
+			return
+		}
+		h.respond(a, "Something went wrong, please try again later.")
+		return
+	}
+	mr, err := h.db.GetMergeRequest(a.ProjectID, a.MRNum)
+	if err != nil {
+		h.respond(a, "Something went wrong, please try again later.")
+		return
+	}
+
+	var outcome string
+	switch a.Name {
+	case ActionApprove:
+		err = h.g.ApproveMergeRequest(a.ProjectID, a.MRNum, u.GitLabID)
+		outcome = "Approved by <@%v>"
+	case ActionMerge:
+		var gmr *gitlab.MergeRequest
+		gmr, err = h.g.MergeMergeRequest(a.ProjectID, a.MRNum, u.GitLabID)
+		outcome = "Set to merge when pipeline succeeds by <@%v>"
+		if err == nil && gmr.State == mrMerged {
+			mr.State = mrMerged
+			outcome = "Merged by <@%v>"
+		}
+	case ActionClose:
+		err = h.g.CloseMergeRequest(a.ProjectID, a.MRNum, u.GitLabID)
+		mr.State = mrClosed
+		outcome = "Closed by <@%v>"
+	}
+	if err != nil {
+		h.respond(a, actionFailure(verb, err))
+		return
+	}
+	logrus.Infof("merge request %v!%v: %v by %v from Slack", a.ProjectID, a.MRNum, verb, u.Email)
+	h.recordOutcome(mr, fmt.Sprintf(outcome, a.SlackID))
+}
+
+// recordOutcome shows the outcome of action on the root message of merge request in place of the previous one,
+// it's posted in thread instead if the blocks of root message are unknown
+func (h *hook) recordOutcome(mr *model.MergeRequest, outcome string) {
+	var blocks []slack.Block
+	if mr.Blocks != "" {
+		err := json.Unmarshal([]byte(mr.Blocks), &blocks)
+		if err != nil {
+			logrus.Errorln(err)
+		}
+	}
+	if mr.Text == "" || len(blocks) == 0 {
+		h.post(&message{
+			channel:   mr.Channel,
+			text:      outcome,
+			threadTS:  mr.ThreadTS,
+			projectID: mr.ProjectID,
+		})
+		h.updateRootMR(mr)
+		return
+	}
+
+	var kept []slack.Block
+	for _, b := range blocks {
+		if b.BlockID != outcomeBlockID {
+			kept = append(kept, b)
+		}
+	}
+	block := slack.ContextBlock(slack.Markdown(outcome))
+	block.BlockID = outcomeBlockID
+	b, err := json.Marshal(append(kept, block))
+	if err != nil {
+		logrus.Errorln(err)
+		return
+	}
+	mr.Blocks = string(b)
+	h.updateRootMR(mr)
+}
+
+// actionFailure explains to the user why the action on merge request fails
+func actionFailure(verb string, err error) string {
+	switch err {
+	case gitlab.ErrAlreadyApproved:
+		return "You have approved this merge request already."
+	case gitlab.ErrSudoDenied:
+		return fmt.Sprintf("Gitlack isn't allowed to %v merge requests as you, please ask the administrator to check its GitLab token.", verb)
+	case gitlab.ErrForbidden:
+		return fmt.Sprintf("You don't have permission to %v this merge request in GitLab.", verb)
+	}
+	return fmt.Sprintf("Failed to %v this merge request: %v", verb, err)
+}
+
+// respond replies to the user clicking the button
+func (h *hook) respond(a *Action, text string) {
+	err := h.s.RespondEphemeral(a.ResponseURL, text)
+	if err != nil {
+		logrus.Errorln(err)
+	}
+}
+
+// withoutActions removes the buttons acting on merge request, which is no longer open,
+// the elements of blocks decoded from the database are maps
+func withoutActions(blocks []slack.Block) []slack.Block {
+	var kept []slack.Block
+	for _, b := range blocks {
+		if b.Type == "actions" {
+			var elements []interface{}
+			for _, e := range b.Elements {
+				if m, ok := e.(map[string]interface{}); ok {
+					if _, ok := actionVerbs[fmt.Sprint(m["action_id"])]; ok {
+						continue
+					}
+				}
+				elements = append(elements, e)
+			}
+			if len(elements) == 0 {
+				continue
+			}
+			b.Elements = elements
+		}
+		kept = append(kept, b)
+	}
+	return kept
+}
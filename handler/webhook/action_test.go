@@ -0,0 +1,147 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"gitlack/model"
+	"gitlack/resource/gitlab"
+	"gitlack/resource/slack"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mGitLab "gitlack/resource/gitlab/mocks"
+	mSlack "gitlack/resource/slack/mocks"
+	mDB "gitlack/store/mocks"
+)
+
+func TestMergeRequestAction(t *testing.T) {
+	input := []struct {
+		name    string
+		merged  bool
+		state   string
+		outcome string
+	}{
+		{ActionApprove, false, mrOpened, "Approved by <@fake-slack-id>"},
+		{ActionMerge, false, mrOpened, "Set to merge when pipeline succeeds by <@fake-slack-id>"},
+		{ActionMerge, true, mrMerged, "Merged by <@fake-slack-id>"},
+		{ActionClose, false, mrClosed, "Closed by <@fake-slack-id>"},
+	}
+	for _, in := range input {
+		// arrange
+		previous := slack.ContextBlock(slack.Markdown("Approved by <@fake-other-id>"))
+		previous.BlockID = outcomeBlockID
+		b, _ := json.Marshal([]slack.Block{slack.HeaderBlock("!1 fake-title"), previous})
+		mockedMR := &model.MergeRequest{ProjectID: 1, MergeRequestNum: 2, Channel: "fake-channel", ThreadTS: "fake-ts",
+			Text: "fake-text", State: mrOpened, Blocks: string(b)}
+		gmr := &gitlab.MergeRequest{State: mrOpened}
+		if in.merged {
+			gmr.State = mrMerged
+		}
+		mockedGitLab := &mGitLab.GitLab{}
+		mockedGitLab.On("ApproveMergeRequest", 1, 2, 3).Return(nil)
+		mockedGitLab.On("MergeMergeRequest", 1, 2, 3).Return(gmr, nil)
+		mockedGitLab.On("CloseMergeRequest", 1, 2, 3).Return(nil)
+		mockedDB := &mDB.Store{}
+		mockedDB.On("GetUserBySlackID", "fake-slack-id").Return(&model.User{GitLabID: 3}, nil)
+		mockedDB.On("GetMergeRequest", 1, 2).Return(mockedMR, nil)
+		mockedDB.On("UpdateMergeRequest", mockedMR).Return(nil)
+		mockedDB.On("ListThreads", mock.Anything, mock.Anything, mock.Anything).Return([]*model.Thread{}, nil)
+		mockedSlack := &mSlack.Slack{}
+		mockedSlack.On("UpdateSlackMessage", "fake-channel", "fake-ts", "fake-text", mock.Anything, mock.Anything).Return(nil, nil)
+		w := &hook{
+			db: mockedDB,
+			g:  mockedGitLab,
+			s:  mockedSlack,
+		}
+
+		// act
+		w.MergeRequestAction(&Action{Name: in.name, ProjectID: 1, MRNum: 2, SlackID: "fake-slack-id", ResponseURL: "fake-url"})
+
+		// assert
+		block := slack.ContextBlock(slack.Markdown(in.outcome))
+		block.BlockID = outcomeBlockID
+		expected, _ := json.Marshal([]slack.Block{slack.HeaderBlock("!1 fake-title"), block})
+		assert.Equal(t, string(expected), mockedMR.Blocks, in.outcome)
+		assert.Equal(t, in.state, mockedMR.State, in.outcome)
+		mockedSlack.AssertNumberOfCalls(t, "UpdateSlackMessage", 1)
+		mockedSlack.AssertNotCalled(t, "RespondEphemeral", mock.Anything, mock.Anything)
+	}
+}
+
+func TestMergeRequestActionForbidden(t *testing.T) {
+	// arrange
+	mockedGitLab := &mGitLab.GitLab{}
+	mockedGitLab.On("CloseMergeRequest", 1, 2, 3).Return(gitlab.ErrForbidden)
+	mockedDB := &mDB.Store{}
+	mockedDB.On("GetUserBySlackID", "fake-slack-id").Return(&model.User{GitLabID: 3}, nil)
+	mockedDB.On("GetMergeRequest", 1, 2).Return(&model.MergeRequest{State: mrOpened}, nil)
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("RespondEphemeral", "fake-url", "You don't have permission to close this merge request in GitLab.").Return(nil)
+	w := &hook{
+		db: mockedDB,
+		g:  mockedGitLab,
+		s:  mockedSlack,
+	}
+
+	// act
+	w.MergeRequestAction(&Action{Name: ActionClose, ProjectID: 1, MRNum: 2, SlackID: "fake-slack-id", ResponseURL: "fake-url"})
+
+	// assert
+	mockedSlack.AssertExpectations(t)
+	mockedDB.AssertNotCalled(t, "UpdateMergeRequest", mock.Anything)
+}
+
+func TestActionFailure(t *testing.T) {
+	input := map[error]string{
+		gitlab.ErrAlreadyApproved: "You have approved this merge request already.",
+		gitlab.ErrSudoDenied:      "Gitlack isn't allowed to approve merge requests as you, please ask the administrator to check its GitLab token.",
+		gitlab.ErrForbidden:       "You don't have permission to approve this merge request in GitLab.",
+		errors.New("fake-error"):  "Failed to approve this merge request: fake-error",
+	}
+	for err, expected := range input {
+		// act
+		actual := actionFailure("approve", err)
+
+		// assert
+		assert.Equal(t, expected, actual, err.Error())
+	}
+}
+
+func TestMergeRequestActionUnlinkedUser(t *testing.T) {
+	// arrange
+	mockedGitLab := &mGitLab.GitLab{}
+	mockedDB := &mDB.Store{}
+	mockedDB.On("GetUserBySlackID", "fake-slack-id").Return(nil, errors.New("sql: no rows in result set"))
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("RespondEphemeral", "fake-url", mock.Anything).Return(nil)
+	w := &hook{
+		db: mockedDB,
+		g:  mockedGitLab,
+		s:  mockedSlack,
+	}
+
+	// act
+	w.MergeRequestAction(&Action{Name: ActionApprove, ProjectID: 1, MRNum: 2, SlackID: "fake-slack-id", ResponseURL: "fake-url"})
+
+	// assert
+	mockedSlack.AssertNumberOfCalls(t, "RespondEphemeral", 1)
+	mockedGitLab.AssertNotCalled(t, "ApproveMergeRequest", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWithoutActions(t *testing.T) {
+	var blocks []slack.Block
+	b, _ := json.Marshal([]slack.Block{
+		slack.HeaderBlock("!1 fake-title"),
+		slack.ActionsBlock(append([]*slack.Button{slack.NewButton("View merge request", "http://fake/1")}, mrActionButtons(1, 1)...)...),
+	})
+	json.Unmarshal(b, &blocks)
+
+	kept := withoutActions(blocks)
+
+	assert.Len(t, kept, 2)
+	assert.Len(t, kept[1].Elements, 1)
+	assert.Equal(t, "http://fake/1", kept[1].Elements[0].(map[string]interface{})["url"])
+}
@@ -0,0 +1,134 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+
+	"gitlack/model"
+	"gitlack/resource/slack"
+)
+
+// limits of Slack blocks
+// see: https://api.slack.com/reference/block-kit/blocks
+const (
+	headerMaxLength  = 150
+	sectionMaxLength = 3000
+)
+
+// mrBlocks renders the root message of merge request
+func mrBlocks(mr MergeRequestEvent, author, assignee *model.User) []slack.Block {
+	return []slack.Block{
+		slack.HeaderBlock(truncate(fmt.Sprintf("!%v %v", mr.ObjAttr.ObjectNum, mr.ObjAttr.Title), headerMaxLength)),
+		slack.ContextBlock(append(
+			userElements("Author", author),
+			userElements("Assignee", assignee)...,
+		)...),
+		slack.FieldsBlock(
+			"*Source*\n`"+mr.ObjAttr.SourceBranch+"`",
+			"*Target*\n`"+mr.ObjAttr.TargetBranch+"`",
+			"*Project*\n"+link(mr.ProjectInfo.WebURL, mr.ProjectInfo.PathWithNamespace),
+			"*Labels*\n"+labelText(mr.Labels),
+		),
+		slack.ActionsBlock(append(
+			[]*slack.Button{slack.NewButton("View merge request", mr.ObjAttr.ObjectURL)},
+			mrActionButtons(mr.ProjectInfo.ID, mr.ObjAttr.ObjectNum)...,
+		)...),
+	}
+}
+
+// issueBlocks renders the root message of issue
+func issueBlocks(issue IssuesEvent, author *model.User) []slack.Block {
+	blocks := []slack.Block{
+		slack.HeaderBlock(truncate(fmt.Sprintf("#%v %v", issue.ObjAttr.ObjectNum, issue.ObjAttr.Title), headerMaxLength)),
+		slack.ContextBlock(userElements("Author", author)...),
+	}
+	if desc := strings.TrimSpace(issue.ObjAttr.Description); desc != "" {
+		blocks = append(blocks, slack.SectionBlock(truncate(markdownToSlack(desc), sectionMaxLength)))
+	}
+	return append(blocks,
+		slack.FieldsBlock(
+			"*Project*\n"+link(issue.ProjectInfo.WebURL, issue.ProjectInfo.PathWithNamespace),
+			"*Labels*\n"+labelText(issue.Labels),
+		),
+		slack.ActionsBlock(slack.NewButton("View issue", issue.ObjAttr.ObjectURL)),
+	)
+}
+
+// tagBlocks renders the message of tag push, the changelog is kept in attachment
+func tagBlocks(tagPushInfo TagPushEvent, author *model.User, tagName, note, tagURL string) []slack.Block {
+	blocks := []slack.Block{
+		slack.HeaderBlock(truncate(fmt.Sprintf("%v %v", tagPushInfo.ProjectInfo.PathWithNamespace, tagName), headerMaxLength)),
+		slack.ContextBlock(userElements("Pushed by", author)...),
+	}
+	if note = strings.TrimSpace(note); note != "" {
+		blocks = append(blocks, slack.SectionBlock(truncate(markdownToSlack(note), sectionMaxLength)))
+	}
+	return append(blocks, slack.ActionsBlock(slack.NewButton("View tag", tagURL)))
+}
+
+// commentBlocks renders the comment posted in thread
+func commentBlocks(comment CommentsEvent, author *model.User) []slack.Block {
+	return []slack.Block{
+		slack.ContextBlock(userElements("Comment by", author)...),
+		slack.SectionBlock(truncate(markdownToSlack(comment.ObjAttr.Note), sectionMaxLength)),
+		slack.ActionsBlock(slack.NewButton("View comment", comment.ObjAttr.ObjectURL)),
+	}
+}
+
+// withBody shows the text rendered by the template of project or group in blocks, in place of
+// the built-in body section, it's put after the header and context so that block clients see the template too
+func withBody(blocks []slack.Block, text string, custom bool) []slack.Block {
+	if !custom {
+		return blocks
+	}
+	body := slack.SectionBlock(truncate(text, sectionMaxLength))
+	i := 0
+	for i < len(blocks) && (blocks[i].Type == "header" || blocks[i].Type == "context") {
+		i++
+	}
+	if i < len(blocks) && blocks[i].Type == "section" && blocks[i].Fields == nil {
+		blocks[i] = body
+		return blocks
+	}
+	return append(blocks[:i], append([]slack.Block{body}, blocks[i:]...)...)
+}
+
+// userElements returns the avatar and mention of user for context block
+func userElements(title string, u *model.User) []interface{} {
+	name := u.Name
+	if u.SlackID != "" {
+		name = fmt.Sprintf("<@%v>", u.SlackID)
+	}
+	var elements []interface{}
+	if u.AvatarURL != "" {
+		elements = append(elements, slack.NewImage(u.AvatarURL, u.Name))
+	}
+	return append(elements, slack.Markdown(fmt.Sprintf("%v: %v", title, name)))
+}
+
+func labelText(labels []Label) string {
+	if len(labels) == 0 {
+		return "-"
+	}
+	var titles []string
+	for _, l := range labels {
+		titles = append(titles, "`"+l.Title+"`")
+	}
+	return strings.Join(titles, " ")
+}
+
+func link(url, text string) string {
+	if url == "" {
+		return text
+	}
+	return fmt.Sprintf("<%v|%v>", url, text)
+}
+
+// truncate cuts s to at most max characters
+func truncate(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max-1]) + "…"
+}
@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+
+	"gitlack/model"
+	"gitlack/resource/slack"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMRBlocks(t *testing.T) {
+	var mr MergeRequestEvent
+	mr.ObjAttr.ObjectNum = 1
+	mr.ObjAttr.Title = "fake-title"
+	mr.ObjAttr.SourceBranch = "fake-source"
+	mr.ObjAttr.TargetBranch = "fake-target"
+	mr.ObjAttr.ObjectURL = "http://fake.com/fake/project/merge_requests/1"
+	mr.ProjectInfo.PathWithNamespace = "fake/project"
+	mr.ProjectInfo.WebURL = "http://fake.com/fake/project"
+	mr.Labels = []Label{{Title: "bug"}, {Title: "backend"}}
+	author := &model.User{Name: "fake-author", SlackID: "fake-author-slack-id", AvatarURL: "http://fake.com/a.png"}
+	assignee := &model.User{Name: "fake-assignee"}
+
+	blocks := mrBlocks(mr, author, assignee)
+
+	assert.Equal(t, slack.HeaderBlock("!1 fake-title"), blocks[0])
+	assert.Equal(t, []interface{}{
+		slack.NewImage("http://fake.com/a.png", "fake-author"),
+		slack.Markdown("Author: <@fake-author-slack-id>"),
+		slack.Markdown("Assignee: fake-assignee"),
+	}, blocks[1].Elements)
+	assert.Equal(t, slack.FieldsBlock(
+		"*Source*\n`fake-source`",
+		"*Target*\n`fake-target`",
+		"*Project*\n<http://fake.com/fake/project|fake/project>",
+		"*Labels*\n`bug` `backend`",
+	), blocks[2])
+	assert.Equal(t, slack.ActionsBlock(
+		slack.NewButton("View merge request", mr.ObjAttr.ObjectURL),
+		slack.NewActionButton("Approve", ActionApprove, "0:1", "primary"),
+		slack.NewActionButton("Merge when pipeline succeeds", ActionMerge, "0:1", ""),
+		slack.NewActionButton("Close", ActionClose, "0:1", "danger"),
+	), blocks[3])
+}
+
+func TestIssueBlocksWithoutDescription(t *testing.T) {
+	var issue IssuesEvent
+	issue.ObjAttr.ObjectNum = 1
+	issue.ObjAttr.Title = "fake-title"
+	issue.ObjAttr.Description = "  "
+
+	blocks := issueBlocks(issue, &model.User{Name: "fake-author"})
+
+	assert.Len(t, blocks, 4)
+	assert.Equal(t, "*Labels*\n-", blocks[2].Fields[1].Text)
+}
+
+func TestCommentBlocks(t *testing.T) {
+	var comment CommentsEvent
+	comment.ObjAttr.Note = "**LGTM**"
+	comment.ObjAttr.ObjectURL = "http://fake.com/note_1"
+
+	blocks := commentBlocks(comment, &model.User{Name: "fake-author"})
+
+	assert.Equal(t, slack.SectionBlock("*LGTM*"), blocks[1])
+	assert.Equal(t, "http://fake.com/note_1", blocks[2].Elements[0].(*slack.Button).URL)
+}
+
+func TestWithBody(t *testing.T) {
+	var comment CommentsEvent
+	comment.ObjAttr.Note = "fake-note"
+	var issue IssuesEvent
+	issue.ObjAttr.Title = "fake-title"
+
+	commented := withBody(commentBlocks(comment, &model.User{Name: "fake-author"}), "fake-custom", true)
+	opened := withBody(issueBlocks(issue, &model.User{Name: "fake-author"}), "fake-custom", true)
+	unchanged := withBody(commentBlocks(comment, &model.User{Name: "fake-author"}), "fake-custom", false)
+
+	assert.Len(t, commented, 3)
+	assert.Equal(t, slack.SectionBlock("fake-custom"), commented[1])
+	assert.Len(t, opened, 5)
+	assert.Equal(t, slack.SectionBlock("fake-custom"), opened[2])
+	assert.Equal(t, slack.SectionBlock("fake-note"), unchanged[1])
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "fake", truncate("fake", 4))
+	assert.Equal(t, "fa…", truncate("fake", 3))
+	assert.Equal(t, headerMaxLength, len([]rune(truncate(strings.Repeat("字", 200), headerMaxLength))))
+}
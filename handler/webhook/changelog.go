@@ -0,0 +1,111 @@
+package webhook
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gitlack/resource/gitlab"
+)
+
+// maxChangelogEntries is the number of entries listed in a changelog
+const maxChangelogEntries = 50
+
+var (
+	conventionalCommit = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?!?:\s*(.+)$`)
+	mergeRequestRef    = regexp.MustCompile(`See merge request \S*!(\d+)`)
+)
+
+// changelogSections defines the order and heading of conventional commit types,
+// the types not listed here are put into "Other Changes"
+var changelogSections = []struct {
+	Types   []string
+	Heading string
+}{
+	{[]string{"feat"}, "Features"},
+	{[]string{"fix"}, "Bug Fixes"},
+	{[]string{"perf"}, "Performance"},
+	{[]string{"refactor"}, "Refactoring"},
+	{[]string{"docs"}, "Documentation"},
+	{[]string{"test"}, "Tests"},
+	{[]string{"build", "ci"}, "Build"},
+	{[]string{"chore", "style", "revert"}, "Chores"},
+}
+
+// changelog lists the merged merge requests and commit subjects grouped by conventional commit type
+func changelog(commits []*gitlab.Commit, webURL string) string {
+	grouped := make(map[string][]string)
+	count := 0
+	for _, c := range commits {
+		title := c.Title
+		var suffix string
+		if m := mergeRequestRef.FindStringSubmatch(c.Message); m != nil {
+			// the title of merge request is the first paragraph after "Merge branch ..."
+			title = mergeRequestTitle(c.Message)
+			suffix = fmt.Sprintf(" (<%v/merge_requests/%v|!%v>)", webURL, m[1], m[1])
+		} else if strings.HasPrefix(title, "Merge branch ") {
+			continue
+		}
+		if title == "" {
+			continue
+		}
+
+		commitType := "other"
+		if m := conventionalCommit.FindStringSubmatch(title); m != nil {
+			commitType = strings.ToLower(m[1])
+			title = m[3]
+			if m[2] != "" {
+				title = fmt.Sprintf("*%v:* %v", m[2], title)
+			}
+		}
+		grouped[sectionOf(commitType)] = append(grouped[sectionOf(commitType)], "• "+title+suffix)
+		count++
+	}
+
+	var headings []string
+	for _, s := range changelogSections {
+		headings = append(headings, s.Heading)
+	}
+	headings = append(headings, "Other Changes")
+
+	var sections []string
+	listed := 0
+	for _, h := range headings {
+		entries := grouped[h]
+		if len(entries) == 0 || listed >= maxChangelogEntries {
+			continue
+		}
+		if listed+len(entries) > maxChangelogEntries {
+			entries = entries[:maxChangelogEntries-listed]
+		}
+		listed += len(entries)
+		sections = append(sections, fmt.Sprintf("*%v*\n%v", h, strings.Join(entries, "\n")))
+	}
+	if count > listed {
+		sections = append(sections, fmt.Sprintf("and %v more change(s)", count-listed))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+func sectionOf(commitType string) string {
+	for _, s := range changelogSections {
+		for _, t := range s.Types {
+			if t == commitType {
+				return s.Heading
+			}
+		}
+	}
+	return "Other Changes"
+}
+
+func mergeRequestTitle(message string) string {
+	paragraphs := strings.Split(strings.Replace(message, "\r\n", "\n", -1), "\n\n")
+	if len(paragraphs) < 2 {
+		return ""
+	}
+	title := strings.TrimSpace(paragraphs[1])
+	if strings.HasPrefix(title, "See merge request") {
+		return ""
+	}
+	return strings.Split(title, "\n")[0]
+}
@@ -1,10 +1,10 @@
 package webhook
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"text/template"
+
+	"gitlack/model"
 
 	"github.com/sirupsen/logrus"
 )
@@ -19,68 +19,80 @@ type CommentsEvent struct {
 	MergeRequestInfo MergeRequest     `json:"merge_request"`
 }
 
-func (h *hook) CommentsEvent(b []byte) {
+func (h *hook) CommentsEvent(b []byte) error {
 	var comment CommentsEvent
 	err := json.Unmarshal(b, &comment)
 	if err != nil {
 		logrus.Errorln(err)
-		return
+		return err
 	}
-
-	if comment.ObjAttr.NoteableType == "Issue" {
-		issuesComment(comment, h)
-	} else if comment.ObjAttr.NoteableType == "MergeRequest" {
-		mrComment(comment, h)
-	} else {
-		logrus.Infoln(fmt.Sprintf("comment type not supported: %v", comment.ObjAttr.NoteableType))
-		return
+	if comment.ObjAttr.NoteableType == "MergeRequest" {
+		h.touchMR(comment.ProjectInfo.ID, comment.MergeRequestInfo.Num)
 	}
-}
 
-func issuesComment(comment CommentsEvent, h *hook) {
-	// get author of comment
-	author, err := h.db.GetUserByID(comment.ObjAttr.AuthorID)
+	m, err := h.commentMessage(comment)
 	if err != nil {
-		return
+		return failure(err)
 	}
-
-	// get issue thread ts
-	issue, err := h.db.GetIssue(comment.ProjectInfo.ID, comment.IssueInfo.Num)
-	if err != nil {
-		return
+	h.post(m)
+	h.postCopies(m)
+	if m.notice != nil {
+		h.notify(m.notice)
 	}
+	return nil
+}
 
-	// prepare Slack text
-	data := map[string]interface{}{
-		"Author": author.Name,
-		"Link":   comment.ObjAttr.ObjectURL,
-		"Desc":   comment.ObjAttr.Note,
+// commentMessage returns the comment posted in the thread of issue or merge request
+func (h *hook) commentMessage(comment CommentsEvent) (*message, error) {
+	if comment.ObjAttr.NoteableType != "Issue" && comment.ObjAttr.NoteableType != "MergeRequest" {
+		err := skip("comment type not supported: %v", comment.ObjAttr.NoteableType)
+		logrus.Infoln(err)
+		return nil, err
 	}
-	t, err := template.New("slack").Parse(commentTemplate)
-	if err != nil {
-		logrus.Errorln(err)
-		return
-	}
-	slackText := &bytes.Buffer{}
-	err = t.Execute(slackText, data)
-	if err != nil {
-		logrus.Errorln(err)
-		return
+	// the reply is in the thread already
+	if h.fromSlack(comment.ObjAttr.Note, comment.ObjAttr.AuthorID) {
+		err := skip("comment %v is sent from Slack", comment.ObjAttr.ObjectURL)
+		logrus.Infoln(err)
+		return nil, err
 	}
-	h.s.PostSlackMessage(issue.Channel, slackText.String(), author, nil, issue.ThreadTS)
-}
 
-func mrComment(comment CommentsEvent, h *hook) {
 	// get author of comment
 	author, err := h.db.GetUserByID(comment.ObjAttr.AuthorID)
 	if err != nil {
-		return
+		return nil, err
 	}
 
-	// get issue thread ts
-	mr, err := h.db.GetMergeRequest(comment.ProjectInfo.ID, comment.MergeRequestInfo.Num)
-	if err != nil {
-		return
+	// get thread ts, the comment waits in outbox if the root message is still there
+	var channel, threadTS, threadKind string
+	var num int
+	var mirrors []*model.Thread
+	var owner *model.User
+	if comment.ObjAttr.NoteableType == "Issue" {
+		num = comment.IssueInfo.Num
+		issue, err := h.db.GetIssue(comment.ProjectInfo.ID, num)
+		if err == nil {
+			channel, threadTS = issue.Channel, issue.ThreadTS
+		} else if channel, err = h.pendingThread(kindIssue, comment.ProjectInfo.ID, num); err == nil {
+			threadKind = kindIssue
+		} else {
+			return nil, missingThread(err)
+		}
+		mirrors = h.mirrors(kindIssue, comment.ProjectInfo.ID, num)
+	} else {
+		num = comment.MergeRequestInfo.Num
+		mr, err := h.db.GetMergeRequest(comment.ProjectInfo.ID, num)
+		if err == nil {
+			channel, threadTS = mr.Channel, mr.ThreadTS
+		} else if channel, err = h.pendingThread(kindMergeRequest, comment.ProjectInfo.ID, num); err == nil {
+			threadKind = kindMergeRequest
+		} else {
+			return nil, missingThread(err)
+		}
+		mirrors = h.mirrors(kindMergeRequest, comment.ProjectInfo.ID, num)
+		// the author of merge request is notified of the comments of others
+		if id := comment.MergeRequestInfo.AuthorID; id != 0 && id != comment.ObjAttr.AuthorID {
+			owner, _ = h.db.GetUserByID(id)
+		}
 	}
 
 	// prepare Slack text
@@ -89,16 +101,29 @@ func mrComment(comment CommentsEvent, h *hook) {
 		"Link":   comment.ObjAttr.ObjectURL,
 		"Desc":   comment.ObjAttr.Note,
 	}
-	t, err := template.New("slack").Parse(commentTemplate)
+	slackText, custom, err := h.render(TemplateComment, comment.ProjectInfo.PathWithNamespace, data)
 	if err != nil {
-		logrus.Errorln(err)
-		return
+		return nil, err
 	}
-	slackText := &bytes.Buffer{}
-	err = t.Execute(slackText, data)
-	if err != nil {
-		logrus.Errorln(err)
-		return
+	m := &message{
+		channel:    channel,
+		text:       slackText,
+		blocks:     withBody(commentBlocks(comment, author), slackText, custom),
+		threadTS:   threadTS,
+		threadKind: threadKind,
+		mirrors:    mirrors,
+		projectID:  comment.ProjectInfo.ID,
+		objectNum:  num,
+	}
+	// comments of issue are posted as the author
+	if comment.ObjAttr.NoteableType == "Issue" {
+		m.author = author
+	}
+	if owner != nil {
+		ccOwner(m, owner, model.MentionCommentOnMyMR, comment.ProjectInfo.PathWithNamespace, func() string {
+			return fmt.Sprintf("%v <%v|commented> on your merge request %v!%v",
+				author.Name, comment.ObjAttr.ObjectURL, comment.ProjectInfo.PathWithNamespace, comment.MergeRequestInfo.Num)
+		})
 	}
-	h.s.PostSlackMessage(mr.Channel, slackText.String(), nil, nil, mr.ThreadTS)
+	return m, nil
 }
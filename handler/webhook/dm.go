@@ -0,0 +1,223 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gitlack/model"
+	"gitlack/resource/slack"
+
+	"github.com/sirupsen/logrus"
+)
+
+// notice mentions users for event in the thread of merge request or issue
+type notice struct {
+	event     string
+	path      string
+	projectID int
+	channel   string
+	threadTS  string
+	// threadKind and objectNum are set instead of threadTS if the thread is still in outbox
+	threadKind string
+	objectNum  int
+	// actor is the user who causes the event, who is never notified
+	actor int
+	// text is posted in thread after the mentions, users aren't mentioned in thread if it's empty
+	text string
+	// dmText returns the text sent by direct message, it's called only if anyone is sent one
+	dmText func() string
+	users  []*model.User
+}
+
+// mention returns whether user is mentioned in channel and by direct message for event of project at path,
+// neither is if the event, the project or any group of it is muted
+func mention(u *model.User, event, path string) (bool, bool) {
+	if containsAny(splitList(u.MutedEvents), []string{event}) || containsAny(splitList(u.MutedProjects), TemplatePaths(path)) {
+		return false, false
+	}
+	switch u.Delivery {
+	case model.DeliveryDM:
+		return false, u.SlackID != ""
+	case model.DeliveryBoth:
+		return true, u.SlackID != ""
+	}
+	return true, false
+}
+
+// mentioned returns user to render in the message of event,
+// the name is shown instead of mention if user doesn't want it in channel or it's in quiet hours of user
+func mentioned(u *model.User, event, path string) *model.User {
+	if inChannel, _ := mention(u, event, path); inChannel && !inQuietHours(u, time.Now()) {
+		return u
+	}
+	quiet := *u
+	quiet.SlackID = ""
+	return &quiet
+}
+
+// notify mentions users in thread and sends them direct messages linking to the thread as they prefer,
+// the notifications to users in quiet hours are queued for the digest instead
+func (h *hook) notify(n *notice) {
+	var mentions []string
+	var text string
+	// the text is the same for all users
+	dmText := func() string {
+		if text == "" {
+			text = n.dmText() + "\n" + h.threadLink(n.channel, n.threadTS)
+		}
+		return text
+	}
+	notified := map[int]bool{n.actor: true}
+	for _, u := range n.users {
+		if notified[u.GitLabID] {
+			continue
+		}
+		notified[u.GitLabID] = true
+
+		inChannel, inDM := mention(u, n.event, n.path)
+		if (inChannel || inDM) && u.SlackID != "" && inQuietHours(u, time.Now()) {
+			h.queue(u, dmText())
+			continue
+		}
+		if inChannel && u.SlackID != "" {
+			mentions = append(mentions, fmt.Sprintf("<@%v>", u.SlackID))
+		}
+		if !inDM {
+			continue
+		}
+		dm, err := h.s.OpenConversation(u.SlackID)
+		if err != nil {
+			continue
+		}
+		h.post(&message{
+			channel:   dm,
+			text:      dmText(),
+			projectID: n.projectID,
+		})
+		logrus.Infof("%v of %v sent to %v", n.event, n.path, u.Email)
+	}
+
+	if n.text == "" || len(mentions) == 0 {
+		return
+	}
+	h.post(&message{
+		channel:    n.channel,
+		text:       strings.Join(mentions, " ") + " " + n.text,
+		threadTS:   n.threadTS,
+		threadKind: n.threadKind,
+		projectID:  n.projectID,
+		objectNum:  n.objectNum,
+	})
+}
+
+// ccOwner copies owner of merge request on the message m in thread as preferred for event,
+// the mention is appended to the message and the direct message is sent by the notice of m
+func ccOwner(m *message, owner *model.User, event, path string, dmText func() string) {
+	if inChannel, _ := mention(owner, event, path); inChannel && owner.SlackID != "" && !inQuietHours(owner, time.Now()) {
+		cc := fmt.Sprintf("cc <@%v>", owner.SlackID)
+		m.text += "\n" + cc
+		if m.blocks != nil {
+			m.blocks = append(m.blocks, slack.ContextBlock(slack.Markdown(cc)))
+		}
+	}
+	m.notice = &notice{
+		event:      event,
+		path:       path,
+		projectID:  m.projectID,
+		channel:    m.channel,
+		threadTS:   m.threadTS,
+		threadKind: m.threadKind,
+		objectNum:  m.objectNum,
+		dmText:     dmText,
+		users:      []*model.User{owner},
+	}
+}
+
+// threadLink links to the thread ts in channel, the channel is mentioned instead if the permalink isn't available
+func (h *hook) threadLink(channel, ts string) string {
+	if ts == "" {
+		return fmt.Sprintf("Discussion in <#%v>", channel)
+	}
+	if permalink, err := h.s.GetPermalink(channel, ts); err == nil && permalink != "" {
+		return fmt.Sprintf("<%v|View the thread>", permalink)
+	}
+	return fmt.Sprintf("Discussion in <#%v>", channel)
+}
+
+// requestReview notifies the users newly assigned to or requested to review merge request
+func requestReview(mr MergeRequestEvent, h *hook) error {
+	mrThread, err := h.db.GetMergeRequest(mr.ProjectInfo.ID, mr.ObjAttr.ObjectNum)
+	if err != nil {
+		return missingThread(err)
+	}
+	assignees := h.users(addedUsers(Changes{Assignees: mr.Changes.Assignees}))
+	h.notifyReviewers(mr, assignees, model.MentionMRAssigned, mrThread.Channel, mrThread.ThreadTS, "you are assigned to review this merge request.")
+	reviewers := h.users(addedUsers(Changes{Reviewers: mr.Changes.Reviewers}))
+	h.notifyReviewers(mr, reviewers, model.MentionReviewRequested, mrThread.Channel, mrThread.ThreadTS, "your review is requested.")
+	return nil
+}
+
+// notifyReviewers notifies users of event in the thread ts of channel,
+// the author is never notified of the own merge request
+func (h *hook) notifyReviewers(mr MergeRequestEvent, users []*model.User, event, channel, ts, text string) {
+	h.notify(&notice{
+		event:     event,
+		path:      mr.ProjectInfo.PathWithNamespace,
+		projectID: mr.ProjectInfo.ID,
+		channel:   channel,
+		threadTS:  ts,
+		actor:     mr.ObjAttr.AuthorID,
+		text:      text,
+		dmText:    func() string { return h.reviewText(mr) },
+		users:     users,
+	})
+}
+
+// reviewText returns the compact notification of merge request sent by direct message
+func (h *hook) reviewText(mr MergeRequestEvent) string {
+	return fmt.Sprintf("%v requested your review on <%v|%v!%v> %v",
+		h.userName(mr.ObjAttr.AuthorID), mr.ObjAttr.ObjectURL, mr.ProjectInfo.PathWithNamespace, mr.ObjAttr.ObjectNum, mr.ObjAttr.Title)
+}
+
+// userName returns the mention of user, or the name if user isn't in Slack
+func (h *hook) userName(id int) string {
+	u, err := h.db.GetUserByID(id)
+	if err != nil {
+		return "Someone"
+	}
+	if u.SlackID != "" {
+		return fmt.Sprintf("<@%v>", u.SlackID)
+	}
+	return u.Name
+}
+
+// users returns the users in store, the ones not found are skipped
+func (h *hook) users(infos []UserInfo) []*model.User {
+	var users []*model.User
+	for _, info := range infos {
+		u, err := h.db.GetUserByID(info.ID)
+		if err != nil {
+			continue
+		}
+		users = append(users, u)
+	}
+	return users
+}
+
+// addedUsers returns the users who are newly assigned or requested to review
+func addedUsers(changes Changes) []UserInfo {
+	var added []UserInfo
+	for _, c := range []UsersChange{changes.Assignees, changes.Reviewers} {
+		previous := map[int]bool{}
+		for _, u := range c.Previous {
+			previous[u.ID] = true
+		}
+		for _, u := range c.Current {
+			if !previous[u.ID] {
+				added = append(added, u)
+			}
+		}
+	}
+	return added
+}
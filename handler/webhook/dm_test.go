@@ -0,0 +1,260 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"gitlack/model"
+	"gitlack/resource/gitlab"
+	"gitlack/resource/slack"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mGitLab "gitlack/resource/gitlab/mocks"
+	mSlack "gitlack/resource/slack/mocks"
+	mDB "gitlack/store/mocks"
+)
+
+func getDMFakeMR() MergeRequestEvent {
+	var mr MergeRequestEvent
+	mr.ObjAttr.AuthorID = 1
+	mr.ObjAttr.ObjectNum = 2
+	mr.ObjAttr.Title = "fake-title"
+	mr.ObjAttr.ObjectURL = "http://fake.com/fake/project/merge_requests/2"
+	mr.ProjectInfo.ID = 999
+	mr.ProjectInfo.PathWithNamespace = "fake/project"
+	return mr
+}
+
+func TestMention(t *testing.T) {
+	input := []struct {
+		user      *model.User
+		inChannel bool
+		inDM      bool
+	}{
+		{&model.User{SlackID: "fake"}, true, false},
+		{&model.User{SlackID: "fake", Delivery: model.DeliveryDM}, false, true},
+		{&model.User{SlackID: "fake", Delivery: model.DeliveryBoth}, true, true},
+		{&model.User{Delivery: model.DeliveryBoth}, true, false},
+		{&model.User{SlackID: "fake", Delivery: model.DeliveryBoth, MutedEvents: "issue_assigned, review_requested"}, false, false},
+		{&model.User{SlackID: "fake", Delivery: model.DeliveryBoth, MutedProjects: "other,fake"}, false, false},
+		{&model.User{SlackID: "fake", Delivery: model.DeliveryBoth, MutedProjects: "fake/project/sub"}, true, true},
+	}
+	for _, i := range input {
+		inChannel, inDM := mention(i.user, model.MentionReviewRequested, "fake/project")
+
+		assert.Equal(t, i.inChannel, inChannel, i.user)
+		assert.Equal(t, i.inDM, inDM, i.user)
+	}
+}
+
+func TestNotifyReviewers(t *testing.T) {
+	mr := getDMFakeMR()
+	users := []*model.User{
+		{GitLabID: 3, Email: "fake-reviewer", SlackID: "fake-reviewer-slack-id", Delivery: model.DeliveryBoth},
+		{GitLabID: 3, Email: "fake-reviewer", SlackID: "fake-reviewer-slack-id", Delivery: model.DeliveryBoth},
+		{GitLabID: 4, Email: "fake-channel-only", SlackID: "fake-channel-only-slack-id"},
+		{GitLabID: 5, Email: "fake-no-slack", Delivery: model.DeliveryDM},
+		{GitLabID: 6, Email: "fake-muted", SlackID: "fake-muted-slack-id", MutedEvents: model.MentionReviewRequested},
+		{GitLabID: 1, Email: "fake-author", SlackID: "fake-author-slack-id", Delivery: model.DeliveryBoth},
+	}
+	expectedText := "<@fake-author-slack-id> requested your review on <http://fake.com/fake/project/merge_requests/2|fake/project!2> fake-title\n" +
+		"<https://fake.slack.com/archives/fake-channel/p1|View the thread>"
+
+	var nilUser *model.User
+	var nilAtm *slack.Attachment
+	mockedDB := &mDB.Store{}
+	mockedDB.On("GetUserByID", 1).Return(&model.User{Name: "fake-author", SlackID: "fake-author-slack-id"}, nil)
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("OpenConversation", "fake-reviewer-slack-id").Return("fake-dm", nil)
+	mockedSlack.On("GetPermalink", "fake-channel", "fake-ts").Return("https://fake.slack.com/archives/fake-channel/p1", nil)
+	mockedSlack.On("PostSlackMessage", "fake-dm", expectedText, nilUser, nilAtm).Return(&slack.MessageResponse{OK: true}, nil)
+	mockedSlack.On("PostSlackMessage", "fake-channel", "<@fake-reviewer-slack-id> <@fake-channel-only-slack-id> your review is requested.", nilUser, nilAtm, "fake-ts").
+		Return(&slack.MessageResponse{OK: true}, nil)
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+
+	w.notifyReviewers(mr, users, model.MentionReviewRequested, "fake-channel", "fake-ts", "your review is requested.")
+
+	mockedSlack.AssertNumberOfCalls(t, "OpenConversation", 1)
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 2)
+}
+
+func TestNotifyReviewersWithoutPermalink(t *testing.T) {
+	mr := getDMFakeMR()
+
+	mockedDB := &mDB.Store{}
+	mockedDB.On("GetUserByID", 1).Return(nil, errors.New("sql: no rows in result set"))
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("OpenConversation", mock.Anything).Return("fake-dm", nil)
+	mockedSlack.On("GetPermalink", mock.Anything, mock.Anything).Return("", errors.New("fake error"))
+	mockedSlack.On("PostSlackMessage", "fake-dm", mock.Anything, mock.Anything, mock.Anything).Return(&slack.MessageResponse{OK: true}, nil)
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+
+	w.notifyReviewers(mr, []*model.User{{GitLabID: 3, SlackID: "fake-slack-id", Delivery: model.DeliveryDM}}, model.MentionMRAssigned, "fake-channel", "fake-ts", "")
+
+	mockedSlack.AssertCalled(t, "PostSlackMessage", "fake-dm",
+		"Someone requested your review on <http://fake.com/fake/project/merge_requests/2|fake/project!2> fake-title\nDiscussion in <#fake-channel>",
+		mock.Anything, mock.Anything)
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1)
+}
+
+func TestRequestReviewOnUpdate(t *testing.T) {
+	fakeData := getMRFakeData()
+	fakeData["Action"] = "update"
+	var mr MergeRequestEvent
+	assert.Nil(t, json.Unmarshal(genMRBody(fakeData), &mr))
+	mr.Changes.Reviewers = UsersChange{
+		Previous: []UserInfo{{ID: 3}},
+		Current:  []UserInfo{{ID: 3}, {ID: 4}},
+	}
+
+	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedDB.On("GetMergeRequest", mr.ProjectInfo.ID, mr.ObjAttr.ObjectNum).Return(&model.MergeRequest{Channel: "fake-channel", ThreadTS: "fake-ts"}, nil)
+	mockedDB.On("GetUserByID", 4).Return(&model.User{GitLabID: 4, SlackID: "fake-new-reviewer", Delivery: model.DeliveryDM}, nil)
+	mockedDB.On("GetUserByID", fakeData["AuthorID"].(int)).Return(&model.User{Name: "fake-author"}, nil)
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("OpenConversation", "fake-new-reviewer").Return("fake-dm", nil)
+	mockedSlack.On("GetPermalink", "fake-channel", "fake-ts").Return("https://fake.slack.com/p1", nil)
+	mockedSlack.On("PostSlackMessage", "fake-dm", mock.Anything, mock.Anything, mock.Anything).Return(&slack.MessageResponse{OK: true}, nil)
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+
+	b, _ := json.Marshal(mr)
+	w.MergeRequestEvent(b)
+
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1)
+	mockedDB.AssertNotCalled(t, "GetUserByID", 3)
+}
+
+func TestCommentMentionsMRAuthor(t *testing.T) {
+	var comment CommentsEvent
+	comment.ObjAttr.AuthorID = 2
+	comment.ObjAttr.NoteableType = "MergeRequest"
+	comment.ObjAttr.Note = "fake-note"
+	comment.ObjAttr.ObjectURL = "http://fake.com/fake/project/merge_requests/3#note_1"
+	comment.ProjectInfo.ID = 999
+	comment.ProjectInfo.PathWithNamespace = "fake/project"
+	comment.MergeRequestInfo.Num = 3
+	comment.MergeRequestInfo.AuthorID = 1
+
+	var nilUser *model.User
+	var nilAtm *slack.Attachment
+	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedDB.On("GetUserByID", 2).Return(&model.User{GitLabID: 2, Name: "fake-commenter"}, nil)
+	mockedDB.On("GetUserByID", 1).Return(&model.User{GitLabID: 1, SlackID: "fake-author-slack-id", Delivery: model.DeliveryBoth}, nil)
+	mockedDB.On("GetMergeRequest", 999, 3).Return(&model.MergeRequest{Channel: "fake-channel", ThreadTS: "fake-ts"}, nil)
+	mockedDB.On("ListThreads", mock.Anything, mock.Anything, mock.Anything).Return([]*model.Thread{}, nil)
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("PostSlackBlocks", "fake-channel", mock.MatchedBy(func(text string) bool {
+		return strings.HasSuffix(text, "\ncc <@fake-author-slack-id>")
+	}), nilUser, nilAtm, mock.Anything, "fake-ts").Return(&slack.MessageResponse{OK: true}, nil)
+	mockedSlack.On("OpenConversation", "fake-author-slack-id").Return("fake-dm", nil)
+	mockedSlack.On("GetPermalink", "fake-channel", "fake-ts").Return("https://fake.slack.com/p1", nil)
+	mockedSlack.On("PostSlackMessage", "fake-dm",
+		"fake-commenter <http://fake.com/fake/project/merge_requests/3#note_1|commented> on your merge request fake/project!3\n<https://fake.slack.com/p1|View the thread>",
+		nilUser, nilAtm).Return(&slack.MessageResponse{OK: true}, nil)
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+
+	b, _ := json.Marshal(comment)
+	w.CommentsEvent(b)
+
+	mockedSlack.AssertExpectations(t)
+}
+
+func TestPipelineFailedMentionsMRAuthor(t *testing.T) {
+	fakeData := getPipelineFakeData()
+
+	var nilUser *model.User
+	var nilAtm *slack.Attachment
+	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedDB.On("GetMergeRequest", 999, 1).Return(&model.MergeRequest{Channel: "fake-channel", ThreadTS: "fake-ts"}, nil)
+	mockedDB.On("ListThreads", mock.Anything, mock.Anything, mock.Anything).Return([]*model.Thread{}, nil)
+	mockedDB.On("UpdateMergeRequest", mock.Anything).Return(nil)
+	mockedDB.On("GetProjectByID", 999).Return(&model.Project{}, nil)
+	mockedDB.On("GetUserByID", 5).Return(&model.User{GitLabID: 5, SlackID: "fake-author-slack-id", Delivery: model.DeliveryDM}, nil)
+	stubGitLab := &mGitLab.GitLab{}
+	stubGitLab.On("GetMergeRequest", 999, 1).Return(&gitlab.MergeRequest{IID: 1, Title: "fake-title", WebURL: "http://fake.com/mr/1", Author: gitlab.GitLabUser{ID: 5}}, nil)
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("PostSlackMessage", "fake-channel", "Pipeline <http://fake.com/fake/fake-gitlab-project/pipelines/31|#31> failed!", nilUser, nilAtm, "fake-ts").
+		Return(&slack.MessageResponse{OK: true}, nil)
+	mockedSlack.On("UpdateSlackMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	mockedSlack.On("AddReaction", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedSlack.On("RemoveReaction", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedSlack.On("OpenConversation", "fake-author-slack-id").Return("fake-dm", nil)
+	mockedSlack.On("GetPermalink", "fake-channel", "fake-ts").Return("https://fake.slack.com/p1", nil)
+	mockedSlack.On("PostSlackMessage", "fake-dm",
+		"Pipeline <http://fake.com/fake/fake-gitlab-project/pipelines/31|#31> failed on your merge request <http://fake.com/mr/1|fake/fake-gitlab-project!1> fake-title\n"+
+			"<https://fake.slack.com/p1|View the thread>",
+		nilUser, nilAtm).Return(&slack.MessageResponse{OK: true}, nil)
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+		g:  stubGitLab,
+	}
+
+	w.PipelineEvent(genPipelineBody(fakeData))
+
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 2)
+	mockedSlack.AssertCalled(t, "OpenConversation", "fake-author-slack-id")
+}
+
+func TestAssignIssue(t *testing.T) {
+	var issue IssuesEvent
+	issue.ObjAttr.Action = "update"
+	issue.ObjAttr.AuthorID = 1
+	issue.ObjAttr.ObjectNum = 2
+	issue.ProjectInfo.ID = 999
+	issue.ProjectInfo.PathWithNamespace = "fake/project"
+	issue.Changes.Assignees = UsersChange{
+		Previous: []UserInfo{{ID: 3}},
+		Current:  []UserInfo{{ID: 3}, {ID: 4}, {ID: 5}},
+	}
+
+	var nilUser *model.User
+	var nilAtm *slack.Attachment
+	mockedDB := &mDB.Store{}
+	mockedDB.On("GetIssue", 999, 2).Return(&model.Issue{Channel: "fake-channel", ThreadTS: "fake-ts"}, nil)
+	mockedDB.On("GetUserByID", 4).Return(&model.User{GitLabID: 4, SlackID: "fake-assignee"}, nil)
+	mockedDB.On("GetUserByID", 5).Return(&model.User{GitLabID: 5, SlackID: "fake-muted", MutedProjects: "fake"}, nil)
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("PostSlackMessage", "fake-channel", "<@fake-assignee> you are assigned to this issue.", nilUser, nilAtm, "fake-ts").
+		Return(&slack.MessageResponse{OK: true}, nil)
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+
+	b, _ := json.Marshal(issue)
+	w.IssuesEvent(b)
+
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1)
+	mockedDB.AssertNotCalled(t, "GetUserByID", 3)
+}
+
+func TestAddedUsers(t *testing.T) {
+	changes := Changes{
+		Assignees: UsersChange{Current: []UserInfo{{ID: 1}}},
+		Reviewers: UsersChange{Previous: []UserInfo{{ID: 2}}, Current: []UserInfo{{ID: 3}}},
+	}
+
+	assert.Equal(t, []UserInfo{{ID: 1}, {ID: 3}}, addedUsers(changes))
+	assert.Empty(t, addedUsers(Changes{}))
+}
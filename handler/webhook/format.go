@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	mdImage  = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)\)`)
+	mdLink   = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	mdHeader = regexp.MustCompile(`(?m)^#{1,6}\s+(.+?)\s*#*$`)
+	mdList   = regexp.MustCompile(`(?m)^(\s*)[-*+]\s+`)
+	mdBold   = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	mdStrike = regexp.MustCompile(`~~(.+?)~~`)
+
+	slackMention = regexp.MustCompile(`<@([A-Z0-9]+)(\|[^>]*)?>`)
+	slackChannel = regexp.MustCompile(`<#[A-Z0-9]+\|([^>]*)>`)
+	slackLink    = regexp.MustCompile(`<((?:https?|mailto):[^|>]+)\|([^>]+)>`)
+	slackURL     = regexp.MustCompile(`<((?:https?|mailto):[^|>]+)>`)
+	slackBold    = regexp.MustCompile(`(^|\s)\*([^*\s](?:[^*\n]*[^*\s])?)\*`)
+	slackStrike  = regexp.MustCompile(`(^|\s)~([^~\s](?:[^~\n]*[^~\s])?)~`)
+)
+
+// markdownToSlack converts the common GitLab markdown syntax to Slack mrkdwn
+// see: https://api.slack.com/reference/surfaces/formatting
+func markdownToSlack(md string) string {
+	md = strings.Replace(md, "\r\n", "\n", -1)
+
+	// leave code blocks untouched
+	parts := strings.Split(md, "```")
+	for i := 0; i < len(parts); i += 2 {
+		p := parts[i]
+		p = mdImage.ReplaceAllString(p, "<$2|$1>")
+		p = mdLink.ReplaceAllString(p, "<$2|$1>")
+		p = mdHeader.ReplaceAllString(p, "*$1*")
+		p = mdList.ReplaceAllString(p, "$1• ")
+		p = mdBold.ReplaceAllString(p, "*$1$2*")
+		p = mdStrike.ReplaceAllString(p, "~$1~")
+		parts[i] = p
+	}
+	return strings.Join(parts, "```")
+}
+
+// slackToMarkdown converts Slack mrkdwn of message to GitLab markdown,
+// name returns how the Slack user mentioned is shown
+func slackToMarkdown(text string, name func(string) string) string {
+	parts := strings.Split(text, "```")
+	for i := 0; i < len(parts); i += 2 {
+		p := parts[i]
+		p = slackMention.ReplaceAllStringFunc(p, func(m string) string {
+			return name(slackMention.FindStringSubmatch(m)[1])
+		})
+		p = slackChannel.ReplaceAllString(p, "#$1")
+		p = slackLink.ReplaceAllString(p, "[$2]($1)")
+		p = slackURL.ReplaceAllString(p, "$1")
+		p = slackBold.ReplaceAllString(p, "$1**$2**")
+		p = slackStrike.ReplaceAllString(p, "$1~~$2~~")
+		parts[i] = p
+	}
+	// Slack escapes only these characters
+	// see: https://api.slack.com/reference/surfaces/formatting#escaping
+	r := strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&")
+	return r.Replace(strings.Join(parts, "```"))
+}
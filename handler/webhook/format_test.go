@@ -0,0 +1,42 @@
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdownToSlack(t *testing.T) {
+	input := map[string]string{
+		"# Title":                         "*Title*",
+		"### Title ###":                   "*Title*",
+		"**bold** and __bold__":           "*bold* and *bold*",
+		"[link](http://fake.com)":         "<http://fake.com|link>",
+		"![image](http://fake.com/a.png)": "<http://fake.com/a.png|image>",
+		"- a\n* b\n  + c":                 "• a\n• b\n  • c",
+		"~~strike~~":                      "~strike~",
+		"```\n# not a title\n```":         "```\n# not a title\n```",
+	}
+
+	for md, expected := range input {
+		assert.Equal(t, expected, markdownToSlack(md), "Markdown should be converted: %q", md)
+	}
+}
+
+func TestSlackToMarkdown(t *testing.T) {
+	input := map[string]string{
+		"<@U0123456789> please check": "fake-name please check",
+		"see <#C0123456789|general>":  "see #general",
+		"<http://fake.com|link>":      "[link](http://fake.com)",
+		"<http://fake.com>":           "http://fake.com",
+		"*bold* and ~strike~":         "**bold** and ~~strike~~",
+		"a &lt;b&gt; &amp; c":         "a <b> & c",
+		"```\n*not bold*\n``` *bold*": "```\n*not bold*\n``` **bold**",
+		"2 * 3 * 4":                   "2 * 3 * 4",
+	}
+	name := func(id string) string { return "fake-name" }
+
+	for text, expected := range input {
+		assert.Equal(t, expected, slackToMarkdown(text, name), "mrkdwn should be converted: %q", text)
+	}
+}
@@ -1,13 +1,10 @@
 package webhook
 
 import (
-	"bytes"
 	"encoding/json"
+	"fmt"
+
 	"gitlack/model"
-	"gitlack/resource/slack"
-	"regexp"
-	"strings"
-	"text/template"
 
 	"github.com/sirupsen/logrus"
 )
@@ -18,115 +15,149 @@ const issueTemplate = "<@{{.Author}}> has opened <{{.Link}}|{{.Path}}#{{.IssueNu
 type IssuesEvent struct {
 	ObjAttr     ObjectAttributes `json:"object_attributes"`
 	ProjectInfo Project          `json:"project"`
+	Labels      []Label          `json:"labels"`
+	Assignees   []UserInfo       `json:"assignees"`
+	Changes     Changes          `json:"changes"`
 }
 
-func (h *hook) IssuesEvent(b []byte) {
+func (h *hook) IssuesEvent(b []byte) error {
 	var issue IssuesEvent
 	err := json.Unmarshal(b, &issue)
 	if err != nil {
 		logrus.Errorln(err)
-		return
+		return err
 	}
 
 	if issue.ObjAttr.Action == "open" || issue.ObjAttr.Action == "reopen" {
-		activeIssue(issue, h)
+		err = activeIssue(issue, h)
 	} else if issue.ObjAttr.Action == "close" {
-		deactiveIssue(issue, h)
+		err = deactiveIssue(issue, h)
+	} else if issue.ObjAttr.Action == "update" && len(addedUsers(Changes{Assignees: issue.Changes.Assignees})) != 0 {
+		err = assignIssue(issue, h)
 	} else {
-		logrus.Infoln("action is NOT one of open, reopen or close")
-		return
+		logrus.Infoln("action is NOT one of open, reopen, close or assignee update")
+		return nil
 	}
+	return failure(err)
 }
 
-func activeIssue(issue IssuesEvent, h *hook) {
-	author, err := h.db.GetUserByID(issue.ObjAttr.AuthorID)
+func activeIssue(issue IssuesEvent, h *hook) error {
+	m, err := h.issueMessage(issue)
 	if err != nil {
-		return
+		return err
 	}
-
-	// get target Slack channel, priority: description > project > user > #general
-	// get from issue description
-	var channel string
-	re, err := regexp.Compile("/gitlack:\\s?\\S+")
+	// the thread is recorded and the assignees are notified by outbox dispatcher if Slack fails
+	m.event = issue
+	smr, err := h.post(m)
+	h.postCopies(m)
 	if err != nil {
-		logrus.Errorln(err)
-		return
-	}
-	parsed := re.FindString(issue.ObjAttr.Description)
-	if parsed != "" {
-		channel = strings.TrimSpace(strings.Replace(parsed, "/gitlack:", "", 1))
+		return nil
 	}
 
-	// get from project default channel
-	if channel == "" {
-		proejct, err := h.db.GetProjectByID(issue.ProjectInfo.ID)
-		if err != nil {
-			return
-		}
-		if proejct.DefaultChannel != "" {
-			channel = proejct.DefaultChannel
-		}
+	// insert new issue
+	h.saveThread(kindIssue, issue.ProjectInfo.ID, issue.ObjAttr.ObjectNum, m.text, m.blocks, smr)
+
+	h.notifyAssignees(issue, h.users(issue.Assignees), smr.Channel, smr.TS)
+	return nil
+}
 
+// assignIssue notifies the users newly assigned to issue
+func assignIssue(issue IssuesEvent, h *hook) error {
+	issueThread, err := h.db.GetIssue(issue.ProjectInfo.ID, issue.ObjAttr.ObjectNum)
+	if err != nil {
+		return missingThread(err)
 	}
+	added := addedUsers(Changes{Assignees: issue.Changes.Assignees})
+	h.notifyAssignees(issue, h.users(added), issueThread.Channel, issueThread.ThreadTS)
+	return nil
+}
+
+// notifyAssignees notifies users assigned to issue in the thread ts of channel,
+// the author isn't notified of assigning the own issue
+func (h *hook) notifyAssignees(issue IssuesEvent, users []*model.User, channel, ts string) {
+	h.notify(&notice{
+		event:     model.MentionIssueAssigned,
+		path:      issue.ProjectInfo.PathWithNamespace,
+		projectID: issue.ProjectInfo.ID,
+		channel:   channel,
+		threadTS:  ts,
+		actor:     issue.ObjAttr.AuthorID,
+		text:      "you are assigned to this issue.",
+		dmText: func() string {
+			return fmt.Sprintf("You are assigned to <%v|%v#%v> %v",
+				issue.ObjAttr.ObjectURL, issue.ProjectInfo.PathWithNamespace, issue.ObjAttr.ObjectNum, issue.ObjAttr.Title)
+		},
+		users: users,
+	})
+}
 
-	// get from assignee default channel
-	if channel == "" {
-		if author.DefaultChannel != "" {
-			channel = author.DefaultChannel
-		}
+// issueMessage returns the root message of issue and the channel it's posted to
+func (h *hook) issueMessage(issue IssuesEvent) (*message, error) {
+	author, err := h.db.GetUserByID(issue.ObjAttr.AuthorID)
+	if err != nil {
+		return nil, err
 	}
 
-	if channel == "" {
-		channel = "general"
+	route, err := h.route(&RouteTarget{
+		Event:       RouteIssue,
+		ProjectID:   issue.ProjectInfo.ID,
+		Path:        issue.ProjectInfo.PathWithNamespace,
+		Description: issue.ObjAttr.Description,
+		Labels:      labelTitles(issue.Labels),
+		Author:      author,
+		Owner:       author,
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// prepare Slack text
-	attachment := &slack.Attachment{
-		Color: slack.AttachmentColor,
-		Title: issue.ObjAttr.Title,
-		Text:  issue.ObjAttr.Description,
-	}
 	data := map[string]interface{}{
 		"Author":   author.SlackID,
 		"Path":     issue.ProjectInfo.PathWithNamespace,
 		"Link":     issue.ObjAttr.ObjectURL,
 		"IssueNum": issue.ObjAttr.ObjectNum,
 	}
-	t, err := template.New("slack").Parse(issueTemplate)
+	slackText, custom, err := h.render(TemplateIssue, issue.ProjectInfo.PathWithNamespace, data)
 	if err != nil {
-		logrus.Errorln(err)
-		return
-	}
-	slackText := &bytes.Buffer{}
-	err = t.Execute(slackText, data)
-	if err != nil {
-		logrus.Errorln(err)
-		return
-	}
-	smr, err := h.s.PostSlackMessage(channel, slackText.String(), author, attachment)
-	if err != nil {
-		logrus.Errorln(err)
-		return
-	}
+		return nil, err
+	}
+	return &message{
+		channel:    route.Channels[0],
+		copies:     route.Channels[1:],
+		text:       slackText,
+		author:     author,
+		blocks:     withBody(issueBlocks(issue, author), slackText, custom),
+		projectID:  issue.ProjectInfo.ID,
+		objectKind: kindIssue,
+		objectNum:  issue.ObjAttr.ObjectNum,
+	}, nil
+}
 
-	// insert new issue
-	newIssue := &model.Issue{
-		ProjectID: issue.ProjectInfo.ID,
-		IssueNum:  issue.ObjAttr.ObjectNum,
-		ThreadTS:  smr.TS,
-		Channel:   smr.Channel,
+func deactiveIssue(issue IssuesEvent, h *hook) error {
+	m := &message{
+		text:      "This issue has been closed.",
+		mirrors:   h.mirrors(kindIssue, issue.ProjectInfo.ID, issue.ObjAttr.ObjectNum),
+		projectID: issue.ProjectInfo.ID,
+		objectNum: issue.ObjAttr.ObjectNum,
 	}
-	err = h.db.CreateIssue(newIssue)
-	if err != nil {
-		return
+	issueThread, err := h.db.GetIssue(issue.ProjectInfo.ID, issue.ObjAttr.ObjectNum)
+	if err == nil {
+		m.channel, m.threadTS = issueThread.Channel, issueThread.ThreadTS
+	} else if m.channel, err = h.pendingThread(kindIssue, issue.ProjectInfo.ID, issue.ObjAttr.ObjectNum); err == nil {
+		m.threadKind = kindIssue
+	} else {
+		return missingThread(err)
 	}
-}
 
-func deactiveIssue(issue IssuesEvent, h *hook) {
-	issueThread, err := h.db.GetIssue(issue.ProjectInfo.ID, issue.ObjAttr.ObjectNum)
-	if err != nil {
-		return
+	h.post(m)
+	h.postCopies(m)
+	// the root message isn't recorded to react until it's delivered
+	if issueThread == nil {
+		return nil
+	}
+	for _, t := range m.threads() {
+		h.react(issue.ProjectInfo.ID, t.Channel, t.ThreadTS, reactIssueClose)
 	}
-	slackText := "This issue has been closed."
-	h.s.PostSlackMessage(issueThread.Channel, slackText, nil, nil, issueThread.ThreadTS)
+	return nil
 }
@@ -7,6 +7,8 @@ import (
 	mSlack "gitlack/resource/slack/mocks"
 	mDB "gitlack/store/mocks"
 	"testing"
+
+	"github.com/stretchr/testify/mock"
 )
 
 const issuesBodyTemplate = `
@@ -66,16 +68,14 @@ func TestIssuesEvent(t *testing.T) {
 	}
 
 	mockedDB := &mDB.Store{}
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
 	mockedDB.On("GetUserByID", fakeData["AuthorID"].(int)).Return(mockedAuthor, nil)
 	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(mockedProject, nil)
 	mockedDB.On("CreateIssue", mockedIssue).Return(nil)
 
 	// assert Slack text format
-	expectedAtm := &slack.Attachment{
-		Color: slack.AttachmentColor,
-		Title: fakeData["Title"].(string),
-		Text:  fakeData["Desc"].(string),
-	}
+	var expectedAtm *slack.Attachment
 	expectedUser := &model.User{
 		SlackID: "fake-author-slack-id",
 	}
@@ -87,7 +87,7 @@ func TestIssuesEvent(t *testing.T) {
 	}
 	slackExpected := renderTemplate(issueTemplate, expected)
 	mockedSlack := &mSlack.Slack{}
-	mockedSlack.On("PostSlackMessage", "general", slackExpected.String(), expectedUser, expectedAtm).Return(mockedMessageReponse, nil)
+	mockedSlack.On("PostSlackBlocks", "general", slackExpected.String(), expectedUser, expectedAtm, mock.Anything).Return(mockedMessageReponse, nil)
 
 	w := &hook{
 		db: mockedDB,
@@ -99,7 +99,7 @@ func TestIssuesEvent(t *testing.T) {
 	mockedDB.AssertNumberOfCalls(t, "GetUserByID", 1)
 	mockedDB.AssertNumberOfCalls(t, "GetProjectByID", 1)
 	mockedDB.AssertNumberOfCalls(t, "CreateIssue", 1)
-	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1)
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackBlocks", 1)
 }
 
 func TestIssuesChannelFromDescription(t *testing.T) {
@@ -125,6 +125,8 @@ func TestIssuesChannelFromDescription(t *testing.T) {
 	}
 
 	mockedDB := &mDB.Store{}
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
 	mockedDB.On("GetUserByID", fakeData["AuthorID"].(int)).Return(mockedAuthor, nil)
 	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(mockedProject, nil)
 	mockedDB.On("CreateIssue", mockedIssue).Return(nil)
@@ -136,11 +138,7 @@ func TestIssuesChannelFromDescription(t *testing.T) {
 		"Link":     fmt.Sprintf("http://fake.com/%v/issues/1", fakeData["Path"].(string)),
 		"IssueNum": fakeData["ObjectNum"].(int),
 	}
-	expectedAtm := &slack.Attachment{
-		Color: slack.AttachmentColor,
-		Title: fakeData["Title"].(string),
-		Text:  fakeData["Desc"].(string),
-	}
+	var expectedAtm *slack.Attachment
 
 	mockedSlack := &mSlack.Slack{}
 
@@ -156,19 +154,18 @@ func TestIssuesChannelFromDescription(t *testing.T) {
 
 		// should use `\\` as escape in JSON
 		fakeData["Desc"] = fmt.Sprintf("a\\nb\\n%v", d)
-		expectedAtm.Text = fmt.Sprintf("a\nb\n%v", d)
 		slackExpected := renderTemplate(issueTemplate, expected)
 		expectedUser := &model.User{
 			SlackID: "fake-author-slack-id",
 		}
-		mockedSlack.On("PostSlackMessage", c, slackExpected.String(), expectedUser, expectedAtm).Return(mockedMessageReponse, nil)
+		mockedSlack.On("PostSlackBlocks", c, slackExpected.String(), expectedUser, expectedAtm, mock.Anything).Return(mockedMessageReponse, nil)
 		w.IssuesEvent(genIssuesBody(fakeData))
 	}
 
 	mockedDB.AssertNotCalled(t, "GetProjectByID")
 	mockedDB.AssertNumberOfCalls(t, "GetUserByID", 1*len(input))
 	mockedDB.AssertNumberOfCalls(t, "CreateIssue", 1*len(input))
-	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1*len(input))
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackBlocks", 1*len(input))
 }
 
 func TestIssuesChannelFromProject(t *testing.T) {
@@ -195,16 +192,14 @@ func TestIssuesChannelFromProject(t *testing.T) {
 	}
 
 	mockedDB := &mDB.Store{}
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
 	mockedDB.On("GetUserByID", fakeData["AuthorID"].(int)).Return(mockedAuthor, nil)
 	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(mockedProject, nil)
 	mockedDB.On("CreateIssue", mockedIssue).Return(nil)
 
 	// assert Slack text format
-	expectedAtm := &slack.Attachment{
-		Color: slack.AttachmentColor,
-		Title: fakeData["Title"].(string),
-		Text:  fakeData["Desc"].(string),
-	}
+	var expectedAtm *slack.Attachment
 	expected := map[string]interface{}{
 		"Author":   mockedAuthor.SlackID,
 		"Path":     fakeData["Path"].(string),
@@ -216,7 +211,7 @@ func TestIssuesChannelFromProject(t *testing.T) {
 		SlackID: "fake-author-slack-id",
 	}
 	mockedSlack := &mSlack.Slack{}
-	mockedSlack.On("PostSlackMessage", mockedProject.DefaultChannel, slackExpected.String(), expectedUser, expectedAtm).Return(mockedMessageReponse, nil)
+	mockedSlack.On("PostSlackBlocks", mockedProject.DefaultChannel, slackExpected.String(), expectedUser, expectedAtm, mock.Anything).Return(mockedMessageReponse, nil)
 
 	w := &hook{
 		db: mockedDB,
@@ -228,7 +223,7 @@ func TestIssuesChannelFromProject(t *testing.T) {
 	mockedDB.AssertNumberOfCalls(t, "GetUserByID", 1)
 	mockedDB.AssertNumberOfCalls(t, "GetProjectByID", 1)
 	mockedDB.AssertNumberOfCalls(t, "CreateIssue", 1)
-	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1)
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackBlocks", 1)
 }
 
 func TestIssuesChannelFromAuthor(t *testing.T) {
@@ -255,16 +250,14 @@ func TestIssuesChannelFromAuthor(t *testing.T) {
 	}
 
 	mockedDB := &mDB.Store{}
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
 	mockedDB.On("GetUserByID", fakeData["AuthorID"].(int)).Return(mockedAuthor, nil)
 	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(mockedProject, nil)
 	mockedDB.On("CreateIssue", mockedIssue).Return(nil)
 
 	// assert Slack text format
-	expectedAtm := &slack.Attachment{
-		Color: slack.AttachmentColor,
-		Title: fakeData["Title"].(string),
-		Text:  fakeData["Desc"].(string),
-	}
+	var expectedAtm *slack.Attachment
 	expected := map[string]interface{}{
 		"Author":   mockedAuthor.SlackID,
 		"Path":     fakeData["Path"].(string),
@@ -277,7 +270,7 @@ func TestIssuesChannelFromAuthor(t *testing.T) {
 		DefaultChannel: "fake-default-author-channel",
 	}
 	mockedSlack := &mSlack.Slack{}
-	mockedSlack.On("PostSlackMessage", mockedAuthor.DefaultChannel, slackExpected.String(), expectedUser, expectedAtm).Return(mockedMessageReponse, nil)
+	mockedSlack.On("PostSlackBlocks", mockedAuthor.DefaultChannel, slackExpected.String(), expectedUser, expectedAtm, mock.Anything).Return(mockedMessageReponse, nil)
 
 	w := &hook{
 		db: mockedDB,
@@ -289,7 +282,7 @@ func TestIssuesChannelFromAuthor(t *testing.T) {
 	mockedDB.AssertNumberOfCalls(t, "GetUserByID", 1)
 	mockedDB.AssertNumberOfCalls(t, "GetProjectByID", 1)
 	mockedDB.AssertNumberOfCalls(t, "CreateIssue", 1)
-	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1)
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackBlocks", 1)
 }
 
 func TestIssuesChannelOverwrite(t *testing.T) {
@@ -321,16 +314,14 @@ func TestIssuesChannelOverwrite(t *testing.T) {
 	}
 
 	mockedDB := &mDB.Store{}
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
 	mockedDB.On("GetUserByID", fakeData["AuthorID"].(int)).Return(mockedAuthor, nil)
 	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(mockedProject, nil)
 	mockedDB.On("CreateIssue", mockedIssue).Return(nil)
 
 	// assert Slack text format
-	expectedAtm := &slack.Attachment{
-		Color: slack.AttachmentColor,
-		Title: fakeData["Title"].(string),
-		Text:  expectedDesc,
-	}
+	var expectedAtm *slack.Attachment
 	expected := map[string]interface{}{
 		"Author":   mockedAuthor.SlackID,
 		"Path":     fakeData["Path"].(string),
@@ -343,7 +334,9 @@ func TestIssuesChannelOverwrite(t *testing.T) {
 		DefaultChannel: "fake-default-author-channel",
 	}
 	mockedSlack := &mSlack.Slack{}
-	mockedSlack.On("PostSlackMessage", "fake-description-channel", slackExpected.String(), expectedUser, expectedAtm).Return(mockedMessageReponse, nil)
+	mockedSlack.On("PostSlackBlocks", "fake-description-channel", slackExpected.String(), expectedUser, expectedAtm, mock.MatchedBy(func(b []slack.Block) bool {
+		return b[2].Text.Text == expectedDesc
+	})).Return(mockedMessageReponse, nil)
 
 	w := &hook{
 		db: mockedDB,
@@ -355,7 +348,7 @@ func TestIssuesChannelOverwrite(t *testing.T) {
 	mockedDB.AssertNotCalled(t, "GetProjectByID")
 	mockedDB.AssertNumberOfCalls(t, "GetUserByID", 1)
 	mockedDB.AssertNumberOfCalls(t, "CreateIssue", 1)
-	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1)
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackBlocks", 1)
 }
 
 func TestDeactiveIssues(t *testing.T) {
@@ -371,12 +364,19 @@ func TestDeactiveIssues(t *testing.T) {
 	}
 
 	mockedDB := &mDB.Store{}
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
 	mockedDB.On("GetIssue", fakeData["ProjectID"].(int), fakeData["ObjectNum"].(int)).Return(mockedIssue, nil)
+	mockedDB.On("ListThreads", mock.Anything, mock.Anything, mock.Anything).Return([]*model.Thread{}, nil)
+	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(&model.Project{}, nil)
 	mockedSlack := &mSlack.Slack{}
 
 	w := &hook{db: mockedDB, s: mockedSlack}
 	var nilUser *model.User
 	var nilAtm *slack.Attachment
 	mockedSlack.On("PostSlackMessage", mockedIssue.Channel, "This issue has been closed.", nilUser, nilAtm, mockedIssue.ThreadTS).Return(nil, nil)
+	mockedSlack.On("AddReaction", mockedIssue.Channel, mockedIssue.ThreadTS, "heavy_check_mark").Return(nil)
 	w.IssuesEvent(genIssuesBody(fakeData))
+
+	mockedSlack.AssertNumberOfCalls(t, "AddReaction", 1)
 }
@@ -1,13 +1,7 @@
 package webhook
 
 import (
-	"bytes"
 	"encoding/json"
-	"fmt"
-	"regexp"
-	"strings"
-	"text/template"
-	"time"
 
 	"gitlack/model"
 
@@ -18,84 +12,131 @@ const mrTemplate = "<@{{.Assignee}}> you are assigned to review <{{.Link}}|{{.Pa
 	"Title: {{.Title}}\n" +
 	"Action: request to merge `{{.Source}}` into `{{.Target}}`\n"
 
+var errSamePerson = skip("author and assignee are the same person")
+
 type MergeRequestEvent struct {
 	ObjAttr     ObjectAttributes `json:"object_attributes"`
 	ProjectInfo Project          `json:"project"`
+	Changes     Changes          `json:"changes"`
+	Labels      []Label          `json:"labels"`
+	Reviewers   []UserInfo       `json:"reviewers"`
 }
 
-func (h *hook) MergeRequestEvent(b []byte) {
+func (h *hook) MergeRequestEvent(b []byte) error {
 	var mr MergeRequestEvent
 	err := json.Unmarshal(b, &mr)
 	if err != nil {
 		logrus.Errorln(err)
-		return
+		return err
 	}
+	// every event of merge request is an activity, the thread of a new one records it when it's started
+	h.touchMR(mr.ProjectInfo.ID, mr.ObjAttr.ObjectNum)
 
-	if mr.ObjAttr.Action == "open" || mr.ObjAttr.Action == "reopen" {
-		activeMR(mr, h)
-	} else if mr.ObjAttr.Action == "update" {
-		go trackPipelineStatus(mr, h)
+	if mr.ObjAttr.Action == "open" {
+		err = activeMR(mr, h)
+	} else if mr.ObjAttr.Action == "reopen" {
+		err = reopenMR(mr, h)
 	} else if mr.ObjAttr.Action == "merge" || mr.ObjAttr.Action == "close" {
-		deactiveMR(mr, h)
+		err = deactiveMR(mr, h)
+	} else if mr.ObjAttr.Action == "update" && (mr.Changes.Title.Current != "" || len(addedUsers(mr.Changes)) != 0) {
+		if mr.Changes.Title.Current != "" {
+			err = retitleMR(mr, h)
+		}
+		if len(addedUsers(mr.Changes)) != 0 && failure(err) == nil {
+			err = requestReview(mr, h)
+		}
 	} else {
-		logrus.Infoln("action is NOT one of open, reopen, merge or close")
+		logrus.Infoln("action is NOT one of open, reopen, merge, close, title or reviewer update")
 		logrus.Debugf("action: %v", mr.ObjAttr.Action)
+		return nil
+	}
+	return failure(err)
+}
+
+func activeMR(mr MergeRequestEvent, h *hook) error {
+	m, err := h.mrMessage(mr)
+	if err != nil {
+		return err
+	}
+	// the thread is recorded and the reviewers are notified by outbox dispatcher if Slack fails
+	m.event = mr
+	smr, err := h.post(m)
+	h.postCopies(m)
+	if err != nil {
+		return nil
+	}
+
+	// insert new merge request
+	h.saveThread(kindMergeRequest, mr.ProjectInfo.ID, mr.ObjAttr.ObjectNum, m.text, m.blocks, smr)
+	h.notifyRequested(mr, m.reviewers, smr.Channel, smr.TS)
+	return nil
+}
+
+// notifyRequested notifies the reviewers of new merge request in its thread ts of channel,
+// reviewers are the assignee followed by the users requested to review
+func (h *hook) notifyRequested(mr MergeRequestEvent, reviewers []*model.User, channel, ts string) {
+	if len(reviewers) == 0 {
 		return
 	}
+	// the assignee is mentioned in the root message already
+	h.notifyReviewers(mr, reviewers[:1], model.MentionMRAssigned, channel, ts, "")
+	h.notifyReviewers(mr, reviewers[1:], model.MentionReviewRequested, channel, ts, "your review is requested.")
 }
 
-func activeMR(mr MergeRequestEvent, h *hook) {
+// mrMessage returns the root message of merge request and the channel it's posted to
+func (h *hook) mrMessage(mr MergeRequestEvent) (*message, error) {
 	// if author and assignee are the same person, do nothing
 	if mr.ObjAttr.AuthorID == mr.ObjAttr.AssigneeID {
-		logrus.Infoln("author and assignee are the same person")
-		return
+		logrus.Infoln(errSamePerson)
+		return nil, errSamePerson
 	}
 
 	author, err := h.db.GetUserByID(mr.ObjAttr.AuthorID)
 	if err != nil {
-		return
+		return nil, err
 	}
 	assignee, err := h.db.GetUserByID(mr.ObjAttr.AssigneeID)
 	if err != nil {
-		return
-	}
-
-	// get target Slack channel, priority: description > project > user > #general
-	// get from mr description
-	var channel string
-	re, err := regexp.Compile("/gitlack:\\s?\\S+")
+		return nil, err
+	}
+
+	route, err := h.route(&RouteTarget{
+		Event:        RouteMergeRequest,
+		ProjectID:    mr.ProjectInfo.ID,
+		Path:         mr.ProjectInfo.PathWithNamespace,
+		Description:  mr.ObjAttr.Description,
+		Labels:       labelTitles(mr.Labels),
+		SourceBranch: mr.ObjAttr.SourceBranch,
+		TargetBranch: mr.ObjAttr.TargetBranch,
+		Author:       author,
+		Owner:        assignee,
+		MRNum:        mr.ObjAttr.ObjectNum,
+	})
 	if err != nil {
-		logrus.Errorln(err)
-		return
-	}
-	parsed := re.FindString(mr.ObjAttr.Description)
-	if parsed != "" {
-		channel = strings.TrimSpace(strings.Replace(parsed, "/gitlack:", "", 1))
-	}
-
-	// get from project default channel
-	if channel == "" {
-		proejct, err := h.db.GetProjectByID(mr.ProjectInfo.ID)
-		if err != nil {
-			return
-		}
-		if proejct.DefaultChannel != "" {
-			channel = proejct.DefaultChannel
-		}
-
+		return nil, err
 	}
 
-	// get from assignee default channel
-	if channel == "" {
-		if assignee.DefaultChannel != "" {
-			channel = assignee.DefaultChannel
-		}
-	}
-
-	if channel == "" {
-		channel = "general"
-	}
+	// the assignee is named without mention if not wanted in channel
+	shown := mentioned(assignee, model.MentionMRAssigned, mr.ProjectInfo.PathWithNamespace)
+	slackText, custom, err := h.renderMR(mr, author, shown)
+	if err != nil {
+		return nil, err
+	}
+	return &message{
+		channel:    route.Channels[0],
+		copies:     route.Channels[1:],
+		text:       slackText,
+		attachment: mrAttachment(&model.MergeRequest{State: mrOpened}),
+		blocks:     withBody(mrBlocks(mr, author, shown), slackText, custom),
+		reviewers:  append([]*model.User{assignee}, h.users(mr.Reviewers)...),
+		projectID:  mr.ProjectInfo.ID,
+		objectKind: kindMergeRequest,
+		objectNum:  mr.ObjAttr.ObjectNum,
+	}, nil
+}
 
+// renderMR returns the text of root message of merge request, custom is true if it's rendered by the template of project or group
+func (h *hook) renderMR(mr MergeRequestEvent, author, assignee *model.User) (string, bool, error) {
 	// if user doesn't exist in Slack, use the name of user in GitLab instead
 	authorID := author.SlackID
 	if author.SlackID == "" {
@@ -106,7 +147,6 @@ func activeMR(mr MergeRequestEvent, h *hook) {
 		assigneeID = assignee.Name
 	}
 
-	// prepare slack text
 	data := map[string]interface{}{
 		"Assignee": assigneeID,
 		"Author":   authorID,
@@ -117,80 +157,98 @@ func activeMR(mr MergeRequestEvent, h *hook) {
 		"Link":     mr.ObjAttr.ObjectURL,
 		"MRNum":    mr.ObjAttr.ObjectNum,
 	}
-	t, err := template.New("slack").Parse(mrTemplate)
+	return h.render(TemplateMergeRequest, mr.ProjectInfo.PathWithNamespace, data)
+}
+
+// reopenMR marks the existing thread open again, a new thread is started if there is none
+func reopenMR(mr MergeRequestEvent, h *hook) error {
+	mrThread, err := h.db.GetMergeRequest(mr.ProjectInfo.ID, mr.ObjAttr.ObjectNum)
 	if err != nil {
-		logrus.Errorln(err)
-		return
+		if failure(missingThread(err)) != nil {
+			return err
+		}
+		return activeMR(mr, h)
+	}
+
+	m := &message{
+		channel:   mrThread.Channel,
+		text:      "This merge request has been reopened.",
+		threadTS:  mrThread.ThreadTS,
+		mirrors:   h.mirrors(kindMergeRequest, mr.ProjectInfo.ID, mr.ObjAttr.ObjectNum),
+		projectID: mr.ProjectInfo.ID,
+	}
+	h.post(m)
+	h.postCopies(m)
+	mrThread.State = mrOpened
+	mrThread.PipelineStatus = ""
+	h.updateRootMR(mrThread)
+	for _, t := range m.threads() {
+		h.unreact(mr.ProjectInfo.ID, t.Channel, t.ThreadTS, reactMerge, reactClose, reactPipelineFailed, reactPipelineSuccess)
 	}
-	slackText := &bytes.Buffer{}
-	err = t.Execute(slackText, data)
+	return nil
+}
+
+// retitleMR rewrites the root message with the new title
+func retitleMR(mr MergeRequestEvent, h *hook) error {
+	mrThread, err := h.db.GetMergeRequest(mr.ProjectInfo.ID, mr.ObjAttr.ObjectNum)
 	if err != nil {
-		logrus.Errorln(err)
-		return
+		return missingThread(err)
 	}
-	smr, err := h.s.PostSlackMessage(channel, slackText.String(), nil, nil)
+	author, err := h.db.GetUserByID(mr.ObjAttr.AuthorID)
 	if err != nil {
-		logrus.Errorln(err)
-		return
+		return err
 	}
+	assignee, err := h.db.GetUserByID(mr.ObjAttr.AssigneeID)
+	if err != nil {
+		return err
+	}
+	assignee = mentioned(assignee, model.MentionMRAssigned, mr.ProjectInfo.PathWithNamespace)
 
-	// insert new merge request
-	newMR := &model.MergeRequest{
-		ProjectID:       mr.ProjectInfo.ID,
-		MergeRequestNum: mr.ObjAttr.ObjectNum,
-		ThreadTS:        smr.TS,
-		Channel:         smr.Channel,
+	slackText, custom, err := h.renderMR(mr, author, assignee)
+	if err != nil {
+		return err
 	}
-	err = h.db.CreateMergeRequest(newMR)
+	b, err := json.Marshal(withBody(mrBlocks(mr, author, assignee), slackText, custom))
 	if err != nil {
-		return
+		logrus.Errorln(err)
+		return err
 	}
-
-	// track pipeline status after
-	// sent Slack message and created MR record
-	go trackPipelineStatus(mr, h)
-
+	mrThread.Text = slackText
+	mrThread.Blocks = string(b)
+	h.updateRootMR(mrThread)
+	return nil
 }
 
-func deactiveMR(mr MergeRequestEvent, h *hook) {
-	mrThread, err := h.db.GetMergeRequest(mr.ProjectInfo.ID, mr.ObjAttr.ObjectNum)
-	if err != nil {
-		return
-	}
-	slackText := "This merge request has been "
+func deactiveMR(mr MergeRequestEvent, h *hook) error {
+	state, slackText, reaction := mrClosed, "This merge request has been closed.", reactClose
 	if mr.ObjAttr.Action == "merge" {
-		slackText += "merged."
+		state, slackText, reaction = mrMerged, "This merge request has been merged.", reactMerge
+	}
+	m := &message{
+		text:      slackText,
+		mirrors:   h.mirrors(kindMergeRequest, mr.ProjectInfo.ID, mr.ObjAttr.ObjectNum),
+		projectID: mr.ProjectInfo.ID,
+		objectNum: mr.ObjAttr.ObjectNum,
+	}
+	mrThread, err := h.db.GetMergeRequest(mr.ProjectInfo.ID, mr.ObjAttr.ObjectNum)
+	if err == nil {
+		m.channel, m.threadTS = mrThread.Channel, mrThread.ThreadTS
+	} else if m.channel, err = h.pendingThread(kindMergeRequest, mr.ProjectInfo.ID, mr.ObjAttr.ObjectNum); err == nil {
+		m.threadKind = kindMergeRequest
 	} else {
-		slackText += "closed."
+		return missingThread(err)
 	}
 
-	h.s.PostSlackMessage(mrThread.Channel, slackText, nil, nil, mrThread.ThreadTS)
-}
-
-// for easy writing test
-var sleep = time.Sleep
-
-func trackPipelineStatus(mr MergeRequestEvent, h *hook) {
-	// check every 30 seconds and timeout after an hour
-	for i := 0; i < 120; i++ {
-		commit, err := h.g.GetSingleCommit(mr.ProjectInfo.ID, mr.ObjAttr.LastCommit.ID)
-		if err != nil {
-			return
-		}
-
-		if commit.LastPipeline.Status == "failed" {
-			mrThread, err := h.db.GetMergeRequest(mr.ProjectInfo.ID, mr.ObjAttr.ObjectNum)
-			if err != nil {
-				return
-			}
-			slackText := fmt.Sprintf("Pipeline <%v|#%v> failed!", commit.LastPipeline.WebURL, commit.LastPipeline.ID)
-			h.s.PostSlackMessage(mrThread.Channel, slackText, nil, nil, mrThread.ThreadTS)
-			return
-		} else if commit.LastPipeline.Status == "success" {
-			return
-		}
-
-		// wait 30 seconds for next checking
-		sleep(time.Second * 30)
+	h.post(m)
+	h.postCopies(m)
+	// the root message isn't recorded to update and react until it's delivered
+	if mrThread == nil {
+		return nil
+	}
+	mrThread.State = state
+	h.updateRootMR(mrThread)
+	for _, t := range m.threads() {
+		h.react(mr.ProjectInfo.ID, t.Channel, t.ThreadTS, reaction)
 	}
+	return nil
 }
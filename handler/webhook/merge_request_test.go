@@ -3,18 +3,19 @@ package webhook
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"gitlack/resource/slack"
+	"reflect"
 	"testing"
 	"text/template"
 	"time"
 
-	"github.com/stretchr/testify/assert"
-
 	"gitlack/model"
-	"gitlack/resource/gitlab"
 
-	mGitLab "gitlack/resource/gitlab/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
 	mSlack "gitlack/resource/slack/mocks"
 	mDB "gitlack/store/mocks"
 )
@@ -70,6 +71,22 @@ func genMRBody(data map[string]interface{}) []byte {
 	return body.Bytes()
 }
 
+// recordedMR matches the merge request recorded with its thread, of which the activity is the time it's recorded
+func recordedMR(expected *model.MergeRequest) interface{} {
+	return mock.MatchedBy(func(mr *model.MergeRequest) bool {
+		actual := *mr
+		actual.LastActivityAt = time.Time{}
+		return !mr.LastActivityAt.IsZero() && reflect.DeepEqual(*expected, actual)
+	})
+}
+
+func expectedMRBlocks(body []byte, author, assignee *model.User) string {
+	var mr MergeRequestEvent
+	json.Unmarshal(body, &mr)
+	b, _ := json.Marshal(mrBlocks(mr, author, assignee))
+	return string(b)
+}
+
 func TestActiveMR(t *testing.T) {
 	// prepare fake input
 	fakeData := getMRFakeData()
@@ -93,20 +110,17 @@ func TestActiveMR(t *testing.T) {
 		MergeRequestNum: fakeData["ObjectNum"].(int),
 		ThreadTS:        mockedMessageReponse.TS,
 		Channel:         mockedMessageReponse.Channel,
+		State:           mrOpened,
 	}
 
-	mockedCommit := &gitlab.Commit{
-		LastPipeline: gitlab.Pipeline{Status: "success"},
-	}
-
-	mockedGitLab := &mGitLab.GitLab{}
-	mockedGitLab.On("GetSingleCommit", fakeData["ProjectID"].(int), fakeData["Sha"].(string)).Return(mockedCommit, nil)
-
 	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
 	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(mockedProject, nil)
 	mockedDB.On("GetUserByID", fakeData["AssigneeID"].(int)).Return(mockedAssignee, nil)
 	mockedDB.On("GetUserByID", fakeData["AuthorID"].(int)).Return(mockedAuthor, nil)
-	mockedDB.On("CreateMergeRequest", mockedMR).Return(nil)
+	mockedDB.On("CreateMergeRequest", recordedMR(mockedMR)).Return(nil)
 
 	// assert Slack text format
 	expected := map[string]interface{}{
@@ -120,35 +134,24 @@ func TestActiveMR(t *testing.T) {
 		"MRNum":    fakeData["ObjectNum"].(int),
 	}
 	slackExpected := renderTemplate(mrTemplate, expected)
+	mockedMR.Text = slackExpected.String()
+	mockedMR.Blocks = expectedMRBlocks(genMRBody(fakeData), mockedAuthor, mockedAssignee)
 	var nilUser *model.User
-	var nilAtm *slack.Attachment
+	openAtm := mrAttachment(&model.MergeRequest{State: mrOpened})
 	mockedSlack := &mSlack.Slack{}
-	mockedSlack.On("PostSlackMessage", "general", slackExpected.String(), nilUser, nilAtm).Return(mockedMessageReponse, nil)
+	mockedSlack.On("PostSlackBlocks", "general", slackExpected.String(), nilUser, openAtm, mock.Anything).Return(mockedMessageReponse, nil)
 
 	w := &hook{
 		db: mockedDB,
 		s:  mockedSlack,
-		g:  mockedGitLab,
-	}
-
-	// mock sleep function
-	sleep = func(d time.Duration) {
-		// do nothing here
 	}
 
 	w.MergeRequestEvent(genMRBody(fakeData))
 
-	// wait for goroutine, work around
-	time.Sleep(time.Millisecond * 100)
-
 	mockedDB.AssertNumberOfCalls(t, "GetProjectByID", 1)
 	mockedDB.AssertNumberOfCalls(t, "GetUserByID", 2)
 	mockedDB.AssertNumberOfCalls(t, "CreateMergeRequest", 1)
-	mockedGitLab.AssertNumberOfCalls(t, "GetSingleCommit", 1)
-	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1)
-
-	// clean up
-	sleep = time.Sleep
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackBlocks", 1)
 }
 
 func TestMRSamePerson(t *testing.T) {
@@ -158,11 +161,12 @@ func TestMRSamePerson(t *testing.T) {
 
 	mockedSlack := &mSlack.Slack{}
 	mockedDB := &mDB.Store{}
-	mockedGitLab := &mGitLab.GitLab{}
+	mockedDB.On("UpdateMergeRequestActivity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
 	w := &hook{
 		db: mockedDB,
 		s:  mockedSlack,
-		g:  mockedGitLab,
 	}
 
 	w.MergeRequestEvent(genMRBody(fakeData))
@@ -170,7 +174,7 @@ func TestMRSamePerson(t *testing.T) {
 	mockedDB.AssertNumberOfCalls(t, "GetProjectByID", 0)
 	mockedDB.AssertNumberOfCalls(t, "GetUserByID", 0)
 	mockedDB.AssertNumberOfCalls(t, "CreateMergeRequest", 0)
-	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 0)
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackBlocks", 0)
 }
 
 func TestMRChannelFromDescription(t *testing.T) {
@@ -196,19 +200,17 @@ func TestMRChannelFromDescription(t *testing.T) {
 		MergeRequestNum: fakeData["ObjectNum"].(int),
 		ThreadTS:        mockedMessageReponse.TS,
 		Channel:         mockedMessageReponse.Channel,
-	}
-	mockedCommit := &gitlab.Commit{
-		LastPipeline: gitlab.Pipeline{Status: "success"},
+		State:           mrOpened,
 	}
 
-	mockedGitLab := &mGitLab.GitLab{}
-	mockedGitLab.On("GetSingleCommit", fakeData["ProjectID"].(int), fakeData["Sha"].(string)).Return(mockedCommit, nil)
-
 	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
 	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(mockedProject, nil)
 	mockedDB.On("GetUserByID", fakeData["AssigneeID"].(int)).Return(mockedAssignee, nil)
 	mockedDB.On("GetUserByID", fakeData["AuthorID"].(int)).Return(mockedAuthor, nil)
-	mockedDB.On("CreateMergeRequest", mockedMR).Return(nil)
+	mockedDB.On("CreateMergeRequest", recordedMR(mockedMR)).Return(nil)
 
 	// assert Slack text format
 	expected := map[string]interface{}{
@@ -222,43 +224,32 @@ func TestMRChannelFromDescription(t *testing.T) {
 		"MRNum":    fakeData["ObjectNum"].(int),
 	}
 	slackExpected := renderTemplate(mrTemplate, expected)
+	mockedMR.Text = slackExpected.String()
+	mockedMR.Blocks = expectedMRBlocks(genMRBody(fakeData), mockedAuthor, mockedAssignee)
 	mockedSlack := &mSlack.Slack{}
 
 	var nilUser *model.User
-	var nilAtm *slack.Attachment
+	openAtm := mrAttachment(&model.MergeRequest{State: mrOpened})
 	input := map[string]string{
 		"/gitlack: fake-channel-1": "fake-channel-1",
 		"/gitlack:fake-channel-2":  "fake-channel-2",
 		"/gitlack: fake_channel-3": "fake_channel-3",
 	}
 
-	// mock sleep function
-	sleep = func(d time.Duration) {
-		// do nothing here
-	}
-
 	var w *hook
 	for d, c := range input {
 		w = &hook{
 			db: mockedDB,
 			s:  mockedSlack,
-			g:  mockedGitLab,
 		}
 
 		fakeData["Desc"] = fmt.Sprintf("a\\nb\\n%v", d)
-		mockedSlack.On("PostSlackMessage", c, slackExpected.String(), nilUser, nilAtm).Return(mockedMessageReponse, nil)
+		mockedSlack.On("PostSlackBlocks", c, slackExpected.String(), nilUser, openAtm, mock.Anything).Return(mockedMessageReponse, nil)
 		w.MergeRequestEvent(genMRBody(fakeData))
-
-		// wait for goroutine, work around
-		time.Sleep(time.Millisecond * 100)
 	}
 	mockedDB.AssertNotCalled(t, "GetProjectByID")
 	mockedDB.AssertNumberOfCalls(t, "GetUserByID", 2*len(input))
-	mockedGitLab.AssertNumberOfCalls(t, "GetSingleCommit", 1*len(input))
-	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1*len(input))
-
-	// clean up
-	sleep = time.Sleep
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackBlocks", 1*len(input))
 }
 
 func TestMRChannelFromProject(t *testing.T) {
@@ -285,19 +276,17 @@ func TestMRChannelFromProject(t *testing.T) {
 		MergeRequestNum: fakeData["ObjectNum"].(int),
 		ThreadTS:        mockedMessageReponse.TS,
 		Channel:         mockedMessageReponse.Channel,
+		State:           mrOpened,
 	}
-	mockedCommit := &gitlab.Commit{
-		LastPipeline: gitlab.Pipeline{Status: "success"},
-	}
-
-	mockedGitLab := &mGitLab.GitLab{}
-	mockedGitLab.On("GetSingleCommit", fakeData["ProjectID"].(int), fakeData["Sha"].(string)).Return(mockedCommit, nil)
 
 	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
 	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(mockedProject, nil)
 	mockedDB.On("GetUserByID", fakeData["AssigneeID"].(int)).Return(mockedAssignee, nil)
 	mockedDB.On("GetUserByID", fakeData["AuthorID"].(int)).Return(mockedAuthor, nil)
-	mockedDB.On("CreateMergeRequest", mockedMR).Return(nil)
+	mockedDB.On("CreateMergeRequest", recordedMR(mockedMR)).Return(nil)
 
 	// assert Slack text format
 	expected := map[string]interface{}{
@@ -311,35 +300,24 @@ func TestMRChannelFromProject(t *testing.T) {
 		"MRNum":    fakeData["ObjectNum"].(int),
 	}
 	slackExpected := renderTemplate(mrTemplate, expected)
+	mockedMR.Text = slackExpected.String()
+	mockedMR.Blocks = expectedMRBlocks(genMRBody(fakeData), mockedAuthor, mockedAssignee)
 	var nilUser *model.User
-	var nilAtm *slack.Attachment
+	openAtm := mrAttachment(&model.MergeRequest{State: mrOpened})
 	mockedSlack := &mSlack.Slack{}
-	mockedSlack.On("PostSlackMessage", mockedProject.DefaultChannel, slackExpected.String(), nilUser, nilAtm).Return(mockedMessageReponse, nil)
+	mockedSlack.On("PostSlackBlocks", mockedProject.DefaultChannel, slackExpected.String(), nilUser, openAtm, mock.Anything).Return(mockedMessageReponse, nil)
 
 	w := &hook{
 		db: mockedDB,
 		s:  mockedSlack,
-		g:  mockedGitLab,
-	}
-
-	// mock sleep function
-	sleep = func(d time.Duration) {
-		// do nothing here
 	}
 
 	w.MergeRequestEvent(genMRBody(fakeData))
 
-	// wait for goroutine, work around
-	time.Sleep(time.Millisecond * 100)
-
 	mockedDB.AssertNumberOfCalls(t, "GetProjectByID", 1)
 	mockedDB.AssertNumberOfCalls(t, "GetUserByID", 2)
 	mockedDB.AssertNumberOfCalls(t, "CreateMergeRequest", 1)
-	mockedGitLab.AssertNumberOfCalls(t, "GetSingleCommit", 1)
-	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1)
-
-	// clean up
-	sleep = time.Sleep
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackBlocks", 1)
 }
 
 func TestMRChannelFromAssignee(t *testing.T) {
@@ -366,19 +344,17 @@ func TestMRChannelFromAssignee(t *testing.T) {
 		MergeRequestNum: fakeData["ObjectNum"].(int),
 		ThreadTS:        mockedMessageReponse.TS,
 		Channel:         mockedMessageReponse.Channel,
+		State:           mrOpened,
 	}
-	mockedCommit := &gitlab.Commit{
-		LastPipeline: gitlab.Pipeline{Status: "success"},
-	}
-
-	mockedGitLab := &mGitLab.GitLab{}
-	mockedGitLab.On("GetSingleCommit", fakeData["ProjectID"].(int), fakeData["Sha"].(string)).Return(mockedCommit, nil)
 
 	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
 	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(mockedProject, nil)
 	mockedDB.On("GetUserByID", fakeData["AssigneeID"].(int)).Return(mockedAssignee, nil)
 	mockedDB.On("GetUserByID", fakeData["AuthorID"].(int)).Return(mockedAuthor, nil)
-	mockedDB.On("CreateMergeRequest", mockedMR).Return(nil)
+	mockedDB.On("CreateMergeRequest", recordedMR(mockedMR)).Return(nil)
 
 	// assert Slack text format
 	expected := map[string]interface{}{
@@ -392,35 +368,24 @@ func TestMRChannelFromAssignee(t *testing.T) {
 		"MRNum":    fakeData["ObjectNum"].(int),
 	}
 	slackExpected := renderTemplate(mrTemplate, expected)
+	mockedMR.Text = slackExpected.String()
+	mockedMR.Blocks = expectedMRBlocks(genMRBody(fakeData), mockedAuthor, mockedAssignee)
 	var nilUser *model.User
-	var nilAtm *slack.Attachment
+	openAtm := mrAttachment(&model.MergeRequest{State: mrOpened})
 	mockedSlack := &mSlack.Slack{}
-	mockedSlack.On("PostSlackMessage", mockedAssignee.DefaultChannel, slackExpected.String(), nilUser, nilAtm).Return(mockedMessageReponse, nil)
+	mockedSlack.On("PostSlackBlocks", mockedAssignee.DefaultChannel, slackExpected.String(), nilUser, openAtm, mock.Anything).Return(mockedMessageReponse, nil)
 
 	w := &hook{
 		db: mockedDB,
 		s:  mockedSlack,
-		g:  mockedGitLab,
-	}
-
-	// mock sleep function
-	sleep = func(d time.Duration) {
-		// do nothing here
 	}
 
 	w.MergeRequestEvent(genMRBody(fakeData))
 
-	// wait for goroutine, work around
-	time.Sleep(time.Millisecond * 100)
-
 	mockedDB.AssertNumberOfCalls(t, "GetProjectByID", 1)
 	mockedDB.AssertNumberOfCalls(t, "GetUserByID", 2)
 	mockedDB.AssertNumberOfCalls(t, "CreateMergeRequest", 1)
-	mockedGitLab.AssertNumberOfCalls(t, "GetSingleCommit", 1)
-	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1)
-
-	// clean up
-	sleep = time.Sleep
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackBlocks", 1)
 }
 
 func TestMRChannelOverwrite(t *testing.T) {
@@ -450,19 +415,17 @@ func TestMRChannelOverwrite(t *testing.T) {
 		MergeRequestNum: fakeData["ObjectNum"].(int),
 		ThreadTS:        mockedMessageReponse.TS,
 		Channel:         mockedMessageReponse.Channel,
+		State:           mrOpened,
 	}
-	mockedCommit := &gitlab.Commit{
-		LastPipeline: gitlab.Pipeline{Status: "success"},
-	}
-
-	mockedGitLab := &mGitLab.GitLab{}
-	mockedGitLab.On("GetSingleCommit", fakeData["ProjectID"].(int), fakeData["Sha"].(string)).Return(mockedCommit, nil)
 
 	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
 	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(mockedProject, nil)
 	mockedDB.On("GetUserByID", fakeData["AssigneeID"].(int)).Return(mockedAssignee, nil)
 	mockedDB.On("GetUserByID", fakeData["AuthorID"].(int)).Return(mockedAuthor, nil)
-	mockedDB.On("CreateMergeRequest", mockedMR).Return(nil)
+	mockedDB.On("CreateMergeRequest", recordedMR(mockedMR)).Return(nil)
 
 	// assert Slack text format
 	expected := map[string]interface{}{
@@ -476,35 +439,24 @@ func TestMRChannelOverwrite(t *testing.T) {
 		"MRNum":    fakeData["ObjectNum"].(int),
 	}
 	slackExpected := renderTemplate(mrTemplate, expected)
+	mockedMR.Text = slackExpected.String()
+	mockedMR.Blocks = expectedMRBlocks(genMRBody(fakeData), mockedAuthor, mockedAssignee)
 	var nilUser *model.User
-	var nilAtm *slack.Attachment
+	openAtm := mrAttachment(&model.MergeRequest{State: mrOpened})
 	mockedSlack := &mSlack.Slack{}
-	mockedSlack.On("PostSlackMessage", "fake-description-channel", slackExpected.String(), nilUser, nilAtm).Return(mockedMessageReponse, nil)
+	mockedSlack.On("PostSlackBlocks", "fake-description-channel", slackExpected.String(), nilUser, openAtm, mock.Anything).Return(mockedMessageReponse, nil)
 
 	w := &hook{
 		db: mockedDB,
 		s:  mockedSlack,
-		g:  mockedGitLab,
-	}
-
-	// mock sleep function
-	sleep = func(d time.Duration) {
-		// do nothing here
 	}
 
 	w.MergeRequestEvent(genMRBody(fakeData))
 
-	// wait for goroutine, work around
-	time.Sleep(time.Millisecond * 100)
-
 	mockedDB.AssertNotCalled(t, "GetProjectByID")
 	mockedDB.AssertNumberOfCalls(t, "GetUserByID", 2)
 	mockedDB.AssertNumberOfCalls(t, "CreateMergeRequest", 1)
-	mockedGitLab.AssertNumberOfCalls(t, "GetSingleCommit", 1)
-	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1)
-
-	// clean up
-	sleep = time.Sleep
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackBlocks", 1)
 }
 
 func TestDeactiveMR(t *testing.T) {
@@ -524,8 +476,15 @@ func TestDeactiveMR(t *testing.T) {
 	}
 
 	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
 	mockedDB.On("GetMergeRequest", fakeData["ProjectID"].(int), fakeData["ObjectNum"].(int)).Return(mockedMR, nil)
+	mockedDB.On("ListThreads", mock.Anything, mock.Anything, mock.Anything).Return([]*model.Thread{}, nil)
+	mockedDB.On("UpdateMergeRequest", mockedMR).Return(nil)
+	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(&model.Project{}, nil)
 	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("AddReaction", mockedMR.Channel, mockedMR.ThreadTS, mock.Anything).Return(nil)
 
 	var nilUser *model.User
 	var nilAtm *slack.Attachment
@@ -538,137 +497,43 @@ func TestDeactiveMR(t *testing.T) {
 		mockedSlack.On("PostSlackMessage", mockedMR.Channel, e, nilUser, nilAtm, mockedMR.ThreadTS).Return(nil, nil)
 		w.MergeRequestEvent(genMRBody(fakeData))
 	}
-}
-
-func TestTrackPipelineStatusSuccess(t *testing.T) {
-	fakeData := getMRFakeData()
-
-	mockedCommit := &gitlab.Commit{
-		LastPipeline: gitlab.Pipeline{Status: "success"},
-	}
-
-	mockedGitLab := &mGitLab.GitLab{}
-	mockedGitLab.On("GetSingleCommit", fakeData["ProjectID"].(int), fakeData["Sha"].(string)).Return(mockedCommit, nil)
-
-	mockedDB := &mDB.Store{}
-	mockedSlack := &mSlack.Slack{}
 
-	w := &hook{
-		db: mockedDB,
-		s:  mockedSlack,
-		g:  mockedGitLab,
-	}
-
-	assert := assert.New(t)
-	var mr MergeRequestEvent
-	err := json.Unmarshal(genMRBody(fakeData), &mr)
-	assert.Nil(err)
-
-	// mock sleep function
-	sleep = func(d time.Duration) {
-		// do nothing here
-	}
-
-	trackPipelineStatus(mr, w)
-
-	mockedGitLab.AssertNumberOfCalls(t, "GetSingleCommit", 1)
-	mockedDB.AssertNotCalled(t, "GetMergeRequest")
-	mockedSlack.AssertNotCalled(t, "PostSlackMessage")
-
-	// clean up
-	sleep = time.Sleep
+	// root message is unknown, only the state is saved
+	mockedDB.AssertNumberOfCalls(t, "UpdateMergeRequest", len(input))
+	mockedSlack.AssertNotCalled(t, "UpdateSlackMessage")
+	mockedSlack.AssertCalled(t, "AddReaction", mockedMR.Channel, mockedMR.ThreadTS, "white_check_mark")
+	mockedSlack.AssertCalled(t, "AddReaction", mockedMR.Channel, mockedMR.ThreadTS, "no_entry")
 }
 
-func TestTrackPipelineStatusFail(t *testing.T) {
-	fakeData := getMRFakeData()
-
-	mockedCommit := &gitlab.Commit{
-		LastPipeline: gitlab.Pipeline{
-			ID:     fakeData["ProjectID"].(int),
-			Status: "failed",
-			WebURL: fakeData["Path"].(string),
-		},
-	}
-	mockedMR := &model.MergeRequest{
-		ProjectID:       fakeData["ProjectID"].(int),
-		MergeRequestNum: fakeData["ObjectNum"].(int),
-		ThreadTS:        "1234567890.123456",
-		Channel:         "fake-channel",
-	}
-
-	mockedGitLab := &mGitLab.GitLab{}
-	mockedGitLab.On("GetSingleCommit", fakeData["ProjectID"].(int), fakeData["Sha"].(string)).Return(mockedCommit, nil)
+func TestMergeRequestEventError(t *testing.T) {
+	samePerson := getMRFakeData()
+	samePerson["AuthorID"] = samePerson["AssigneeID"]
+	closed := getMRFakeData()
+	closed["Action"] = "close"
+	closed["ObjectNum"] = 2
+	unknownAuthor := getMRFakeData()
+	unknownAuthor["AuthorID"] = 3
 
 	mockedDB := &mDB.Store{}
-	mockedDB.On("GetMergeRequest", fakeData["ProjectID"].(int), fakeData["ObjectNum"].(int)).Return(mockedMR, nil)
-
-	slackExpected := fmt.Sprintf("Pipeline <%v|#%v> failed!", fakeData["Path"].(string), fakeData["ProjectID"].(int))
-	var nilUser *model.User
-	var nilAtm *slack.Attachment
-	mockedSlack := &mSlack.Slack{}
-	mockedSlack.On("PostSlackMessage", mockedMR.Channel, slackExpected, nilUser, nilAtm, mockedMR.ThreadTS).Return(nil, nil)
-
+	mockedDB.On("UpdateMergeRequestActivity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedDB.On("ListThreads", mock.Anything, mock.Anything, mock.Anything).Return([]*model.Thread{}, nil)
+	mockedDB.On("GetMergeRequest", 999, 2).Return(nil, errors.New("sql: no rows in result set"))
+	mockedDB.On("GetPendingOutboxMessage", kindMergeRequest, 999, 2).Return(nil, errors.New("sql: no rows in result set"))
+	mockedDB.On("GetUserByID", 3).Return(nil, errors.New("sql: no rows in result set"))
 	w := &hook{
 		db: mockedDB,
-		s:  mockedSlack,
-		g:  mockedGitLab,
-	}
-
-	// mock sleep function
-	sleep = func(d time.Duration) {
-		// do nothing here
-	}
-
-	assert := assert.New(t)
-	var mr MergeRequestEvent
-	err := json.Unmarshal(genMRBody(fakeData), &mr)
-	assert.Nil(err)
-
-	trackPipelineStatus(mr, w)
-
-	mockedGitLab.AssertNumberOfCalls(t, "GetSingleCommit", 1)
-	mockedDB.AssertNotCalled(t, "GetMergeRequest")
-	mockedSlack.AssertNotCalled(t, "PostSlackMessage")
-
-	// clean up
-	sleep = time.Sleep
-}
-
-func TestTrackPipelineStatusTimeout(t *testing.T) {
-	fakeData := getMRFakeData()
-
-	mockedCommit := &gitlab.Commit{
-		LastPipeline: gitlab.Pipeline{Status: "running"},
+		s:  &mSlack.Slack{},
 	}
 
-	mockedGitLab := &mGitLab.GitLab{}
-	mockedGitLab.On("GetSingleCommit", fakeData["ProjectID"].(int), fakeData["Sha"].(string)).Return(mockedCommit, nil)
-
-	mockedDB := &mDB.Store{}
-	mockedSlack := &mSlack.Slack{}
-
-	w := &hook{
-		db: mockedDB,
-		s:  mockedSlack,
-		g:  mockedGitLab,
+	input := [][]byte{
+		[]byte("not-json"),
+		genMRBody(samePerson),
+		genMRBody(closed),
+		genMRBody(unknownAuthor),
 	}
-
-	assert := assert.New(t)
-	var mr MergeRequestEvent
-	err := json.Unmarshal(genMRBody(fakeData), &mr)
-	assert.Nil(err)
-
-	// mock sleep function
-	sleep = func(d time.Duration) {
-		// do nothing here
+	failed := []bool{true, false, false, true}
+	for i, b := range input {
+		err := w.MergeRequestEvent(b)
+		assert.Equal(t, failed[i], err != nil, string(b))
 	}
-
-	trackPipelineStatus(mr, w)
-
-	mockedGitLab.AssertNumberOfCalls(t, "GetSingleCommit", 120)
-	mockedDB.AssertNotCalled(t, "GetMergeRequest")
-	mockedSlack.AssertNotCalled(t, "PostSlackMessage")
-
-	// clean up
-	sleep = time.Sleep
 }
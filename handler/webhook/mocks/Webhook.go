@@ -3,6 +3,7 @@
 package mocks
 
 import mock "github.com/stretchr/testify/mock"
+import webhook "gitlack/handler/webhook"
 
 // Webhook is an autogenerated mock type for the Webhook type
 type Webhook struct {
@@ -10,21 +11,175 @@ type Webhook struct {
 }
 
 // CommentsEvent provides a mock function with given fields: _a0
-func (_m *Webhook) CommentsEvent(_a0 []byte) {
-	_m.Called(_a0)
+func (_m *Webhook) CommentsEvent(_a0 []byte) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]byte) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DispatchDigests provides a mock function with given fields:
+func (_m *Webhook) DispatchDigests() {
+	_m.Called()
+}
+
+// DispatchOutbox provides a mock function with given fields:
+func (_m *Webhook) DispatchOutbox() {
+	_m.Called()
+}
+
+// DispatchReviewDigests provides a mock function with given fields:
+func (_m *Webhook) DispatchReviewDigests() {
+	_m.Called()
+}
+
+// DispatchStaleReminders provides a mock function with given fields:
+func (_m *Webhook) DispatchStaleReminders() {
+	_m.Called()
+}
+
+// ExplainRoute provides a mock function with given fields: _a0
+func (_m *Webhook) ExplainRoute(_a0 *webhook.RouteTarget) (*webhook.Route, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *webhook.Route
+	if rf, ok := ret.Get(0).(func(*webhook.RouteTarget) *webhook.Route); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*webhook.Route)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*webhook.RouteTarget) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // IssuesEvent provides a mock function with given fields: _a0
-func (_m *Webhook) IssuesEvent(_a0 []byte) {
-	_m.Called(_a0)
+func (_m *Webhook) IssuesEvent(_a0 []byte) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]byte) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
 }
 
 // MergeRequestEvent provides a mock function with given fields: _a0
-func (_m *Webhook) MergeRequestEvent(_a0 []byte) {
+func (_m *Webhook) MergeRequestEvent(_a0 []byte) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]byte) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MergeRequestAction provides a mock function with given fields: _a0
+func (_m *Webhook) MergeRequestAction(_a0 *webhook.Action) {
 	_m.Called(_a0)
 }
 
-// TagPushEvent provides a mock function with given fields: _a0
-func (_m *Webhook) TagPushEvent(_a0 []byte) {
+// PipelineEvent provides a mock function with given fields: _a0
+func (_m *Webhook) PipelineEvent(_a0 []byte) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]byte) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Preview provides a mock function with given fields: _a0, _a1
+func (_m *Webhook) Preview(_a0 string, _a1 []byte) (*webhook.Preview, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *webhook.Preview
+	if rf, ok := ret.Get(0).(func(string, []byte) *webhook.Preview); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*webhook.Preview)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []byte) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PushEvent provides a mock function with given fields: _a0
+func (_m *Webhook) PushEvent(_a0 []byte) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]byte) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReleaseEvent provides a mock function with given fields: _a0
+func (_m *Webhook) ReleaseEvent(_a0 []byte) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]byte) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SlackReply provides a mock function with given fields: _a0
+func (_m *Webhook) SlackReply(_a0 *webhook.Reply) {
 	_m.Called(_a0)
 }
+
+// TagPushEvent provides a mock function with given fields: _a0
+func (_m *Webhook) TagPushEvent(_a0 []byte) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]byte) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -28,12 +28,54 @@ type Commit struct {
 	ID string `json:"id"`
 }
 
+// PipelineAttributes represents the data structure of `object_attributes` in GitLab pipeline webhook request
+type PipelineAttributes struct {
+	ID     int    `json:"id"`
+	Ref    string `json:"ref"`
+	Tag    bool   `json:"tag"`
+	SHA    string `json:"sha"`
+	Status string `json:"status"`
+}
+
 // Issue represents the data structure of issue in comment events
 type Issue struct {
 	Num int `json:"iid"`
 }
 
-// MergeRequest represents the data structure of merge request in comment events
+// MergeRequest represents the data structure of merge request in comment and pipeline events,
+// `author_id` is only given in comment events
 type MergeRequest struct {
-	Num int `json:"iid"`
+	Num      int `json:"iid"`
+	AuthorID int `json:"author_id"`
+}
+
+// Changes represents the data structure of `changes` in GitLab merge request and issue webhook request
+type Changes struct {
+	Title     Change      `json:"title"`
+	Assignees UsersChange `json:"assignees"`
+	Reviewers UsersChange `json:"reviewers"`
+}
+
+// UserInfo represents the data structure of user in `assignees` and `reviewers` of GitLab webhook request
+type UserInfo struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+}
+
+// UsersChange represents the previous and current users of a changed attribute
+type UsersChange struct {
+	Previous []UserInfo `json:"previous"`
+	Current  []UserInfo `json:"current"`
+}
+
+// Change represents the previous and current value of a changed attribute
+type Change struct {
+	Previous string `json:"previous"`
+	Current  string `json:"current"`
+}
+
+// Label represents the data structure of label in GitLab merge request and issue webhook request
+type Label struct {
+	Title string `json:"title"`
+	Color string `json:"color"`
 }
@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"encoding/json"
+
+	"gitlack/model"
+	"gitlack/resource/slack"
+
+	"github.com/sirupsen/logrus"
+)
+
+// states of merge request
+const (
+	mrOpened = "opened"
+	mrMerged = "merged"
+	mrClosed = "closed"
+)
+
+type badge struct {
+	title string
+	color string
+}
+
+// mrBadges shows the state of merge request on its root message
+var mrBadges = map[string]badge{
+	mrOpened: {":large_blue_circle: Open", "#1F78D1"},
+	mrMerged: {":large_purple_circle: Merged", "#6B4FBB"},
+	mrClosed: {":red_circle: Closed", "#DB3B21"},
+}
+
+// pipelineBadges shows the status of the latest pipeline of merge request
+var pipelineBadges = map[string]string{
+	"failed":   ":x: Pipeline failed",
+	"success":  ":white_check_mark: Pipeline passed",
+	"canceled": ":no_entry_sign: Pipeline canceled",
+	"manual":   ":raised_hand: Pipeline waiting for a manual action",
+}
+
+// mrAttachment returns the badge of merge request, a failed pipeline turns an open merge request red
+func mrAttachment(mr *model.MergeRequest) *slack.Attachment {
+	b, ok := mrBadges[mr.State]
+	if !ok {
+		b = mrBadges[mrOpened]
+	}
+	atm := &slack.Attachment{
+		Color: b.color,
+		Title: b.title,
+		Text:  pipelineBadges[mr.PipelineStatus],
+	}
+	if mr.State == mrOpened && mr.PipelineStatus == "failed" {
+		atm.Color = mrBadges[mrClosed].color
+	}
+	return atm
+}
+
+// updateRootMR saves the state of merge request and rewrites its root message
+func (h *hook) updateRootMR(mr *model.MergeRequest) {
+	err := h.db.UpdateMergeRequest(mr)
+	if err != nil {
+		return
+	}
+	// threads started before the text is recorded can't be rewritten
+	if mr.Text == "" {
+		logrus.Debugf("root message of merge request %v!%v is unknown", mr.ProjectID, mr.MergeRequestNum)
+		return
+	}
+	var blocks []slack.Block
+	if mr.Blocks != "" {
+		err = json.Unmarshal([]byte(mr.Blocks), &blocks)
+		if err != nil {
+			logrus.Errorln(err)
+		}
+	}
+	// the buttons are kept in database in case the merge request is reopened
+	if mr.State != mrOpened && blocks != nil {
+		blocks = withoutActions(blocks)
+	}
+	// the first messages of mirror threads are the same as the primary one
+	threads := append([]*model.Thread{{Channel: mr.Channel, ThreadTS: mr.ThreadTS}}, h.mirrors(kindMergeRequest, mr.ProjectID, mr.MergeRequestNum)...)
+	for _, t := range threads {
+		_, err = h.s.UpdateSlackMessage(t.Channel, t.ThreadTS, mr.Text, mrAttachment(mr), blocks)
+		if err != nil {
+			logrus.Errorln(err)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gitlack/model"
+	"gitlack/resource/slack"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mSlack "gitlack/resource/slack/mocks"
+	mDB "gitlack/store/mocks"
+)
+
+func TestMRAttachment(t *testing.T) {
+	input := []*model.MergeRequest{
+		{State: mrOpened},
+		{State: mrOpened, PipelineStatus: "success"},
+		{State: mrOpened, PipelineStatus: "failed"},
+		{State: mrMerged, PipelineStatus: "success"},
+		{State: mrClosed},
+		{State: ""},
+	}
+	expected := []*slack.Attachment{
+		{Color: "#1F78D1", Title: ":large_blue_circle: Open"},
+		{Color: "#1F78D1", Title: ":large_blue_circle: Open", Text: ":white_check_mark: Pipeline passed"},
+		{Color: "#DB3B21", Title: ":large_blue_circle: Open", Text: ":x: Pipeline failed"},
+		{Color: "#6B4FBB", Title: ":large_purple_circle: Merged", Text: ":white_check_mark: Pipeline passed"},
+		{Color: "#DB3B21", Title: ":red_circle: Closed"},
+		{Color: "#1F78D1", Title: ":large_blue_circle: Open"},
+	}
+	for i, mr := range input {
+		assert.Equal(t, expected[i], mrAttachment(mr))
+	}
+}
+
+func TestDeactiveMRUpdatesRootMessage(t *testing.T) {
+	fakeData := getMRFakeData()
+	fakeData["Action"] = "merge"
+
+	mockedMR := &model.MergeRequest{
+		ProjectID:       fakeData["ProjectID"].(int),
+		MergeRequestNum: fakeData["ObjectNum"].(int),
+		ThreadTS:        "1234567890.123456",
+		Channel:         "fake-channel",
+		Text:            "fake-root-text",
+		State:           mrOpened,
+		PipelineStatus:  "success",
+	}
+	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedDB.On("GetMergeRequest", mockedMR.ProjectID, mockedMR.MergeRequestNum).Return(mockedMR, nil)
+	mockedDB.On("ListThreads", mock.Anything, mock.Anything, mock.Anything).Return([]*model.Thread{}, nil)
+	mockedDB.On("UpdateMergeRequest", mockedMR).Return(nil)
+
+	mergedAtm := &slack.Attachment{
+		Color: "#6B4FBB",
+		Title: ":large_purple_circle: Merged",
+		Text:  ":white_check_mark: Pipeline passed",
+	}
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("PostSlackMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	mockedSlack.On("UpdateSlackMessage", mockedMR.Channel, mockedMR.ThreadTS, mockedMR.Text, mergedAtm, mock.Anything).Return(nil, nil)
+	mockedSlack.On("AddReaction", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedDB.On("GetProjectByID", mockedMR.ProjectID).Return(&model.Project{}, nil)
+
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+	w.MergeRequestEvent(genMRBody(fakeData))
+
+	assert.Equal(t, mrMerged, mockedMR.State)
+	mockedDB.AssertNumberOfCalls(t, "UpdateMergeRequest", 1)
+	mockedSlack.AssertNumberOfCalls(t, "UpdateSlackMessage", 1)
+}
+
+func TestReopenMRWithThread(t *testing.T) {
+	fakeData := getMRFakeData()
+	fakeData["Action"] = "reopen"
+
+	mockedMR := &model.MergeRequest{
+		ProjectID:       fakeData["ProjectID"].(int),
+		MergeRequestNum: fakeData["ObjectNum"].(int),
+		ThreadTS:        "1234567890.123456",
+		Channel:         "fake-channel",
+		Text:            "fake-root-text",
+		State:           mrClosed,
+		PipelineStatus:  "failed",
+	}
+	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedDB.On("GetMergeRequest", mockedMR.ProjectID, mockedMR.MergeRequestNum).Return(mockedMR, nil)
+	mockedDB.On("ListThreads", mock.Anything, mock.Anything, mock.Anything).Return([]*model.Thread{}, nil)
+	mockedDB.On("UpdateMergeRequest", mockedMR).Return(nil)
+
+	var nilUser *model.User
+	var nilAtm *slack.Attachment
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("PostSlackMessage", mockedMR.Channel, "This merge request has been reopened.", nilUser, nilAtm, mockedMR.ThreadTS).Return(nil, nil)
+	mockedSlack.On("UpdateSlackMessage", mockedMR.Channel, mockedMR.ThreadTS, mockedMR.Text, mrBadgeOnly(mrOpened), mock.Anything).Return(nil, nil)
+	mockedSlack.On("RemoveReaction", mockedMR.Channel, mockedMR.ThreadTS, mock.Anything).Return(nil)
+	mockedDB.On("GetProjectByID", mockedMR.ProjectID).Return(&model.Project{}, nil)
+
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+	w.MergeRequestEvent(genMRBody(fakeData))
+
+	assert.Equal(t, mrOpened, mockedMR.State)
+	assert.Equal(t, "", mockedMR.PipelineStatus)
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1)
+	mockedSlack.AssertNumberOfCalls(t, "UpdateSlackMessage", 1)
+	mockedSlack.AssertCalled(t, "RemoveReaction", mockedMR.Channel, mockedMR.ThreadTS, "white_check_mark")
+	mockedSlack.AssertCalled(t, "RemoveReaction", mockedMR.Channel, mockedMR.ThreadTS, "no_entry")
+	mockedSlack.AssertCalled(t, "RemoveReaction", mockedMR.Channel, mockedMR.ThreadTS, "x")
+	mockedSlack.AssertNumberOfCalls(t, "RemoveReaction", 3)
+	mockedDB.AssertNotCalled(t, "CreateMergeRequest", mock.Anything)
+}
+
+func TestReopenMRWithoutThread(t *testing.T) {
+	fakeData := getMRFakeData()
+	fakeData["Action"] = "reopen"
+
+	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedDB.On("GetMergeRequest", fakeData["ProjectID"].(int), fakeData["ObjectNum"].(int)).Return(nil, errors.New("sql: no rows in result set"))
+	mockedDB.On("ListThreads", mock.Anything, mock.Anything, mock.Anything).Return([]*model.Thread{}, nil)
+	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(&model.Project{}, nil)
+	mockedDB.On("GetUserByID", mock.Anything).Return(&model.User{}, nil)
+	mockedDB.On("CreateMergeRequest", mock.Anything).Return(nil)
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
+
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("PostSlackBlocks", "general", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&slack.MessageResponse{}, nil)
+
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+	w.MergeRequestEvent(genMRBody(fakeData))
+
+	mockedDB.AssertNumberOfCalls(t, "CreateMergeRequest", 1)
+	mockedSlack.AssertNotCalled(t, "UpdateSlackMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRetitleMR(t *testing.T) {
+	fakeData := getMRFakeData()
+	fakeData["Action"] = "update"
+	fakeData["Title"] = "fake-new-title"
+	body := strings.Replace(string(genMRBody(fakeData)), `"project": {`, `"changes": {"title": {"previous": "fake-title", "current": "fake-new-title"}}, "project": {`, 1)
+
+	mockedMR := &model.MergeRequest{
+		ProjectID:       fakeData["ProjectID"].(int),
+		MergeRequestNum: fakeData["ObjectNum"].(int),
+		ThreadTS:        "1234567890.123456",
+		Channel:         "fake-channel",
+		Text:            "fake-root-text",
+		State:           mrOpened,
+	}
+	mockedAssignee := &model.User{
+		SlackID: "fake-assignee-slack-id",
+	}
+	mockedAuthor := &model.User{
+		SlackID: "fake-author-slack-id",
+	}
+	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedDB.On("GetMergeRequest", mockedMR.ProjectID, mockedMR.MergeRequestNum).Return(mockedMR, nil)
+	mockedDB.On("ListThreads", mock.Anything, mock.Anything, mock.Anything).Return([]*model.Thread{}, nil)
+	mockedDB.On("GetUserByID", fakeData["AssigneeID"].(int)).Return(mockedAssignee, nil)
+	mockedDB.On("GetUserByID", fakeData["AuthorID"].(int)).Return(mockedAuthor, nil)
+	mockedDB.On("UpdateMergeRequest", mockedMR).Return(nil)
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
+
+	expected := renderTemplate(mrTemplate, map[string]interface{}{
+		"Assignee": mockedAssignee.SlackID,
+		"Path":     fakeData["Path"].(string),
+		"Author":   mockedAuthor.SlackID,
+		"Title":    "fake-new-title",
+		"Source":   fakeData["Source"].(string),
+		"Target":   fakeData["Target"].(string),
+		"Link":     fmt.Sprintf("http://fake.com/%v/merge_requests/1", fakeData["Path"].(string)),
+		"MRNum":    fakeData["ObjectNum"].(int),
+	})
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("UpdateSlackMessage", mockedMR.Channel, mockedMR.ThreadTS, expected.String(), mrBadgeOnly(mrOpened), mock.Anything).Return(nil, nil)
+
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+	w.MergeRequestEvent([]byte(body))
+
+	assert.Equal(t, expected.String(), mockedMR.Text)
+	mockedSlack.AssertNumberOfCalls(t, "UpdateSlackMessage", 1)
+	mockedSlack.AssertNotCalled(t, "PostSlackMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMRUpdateWithoutTitleChangeIgnored(t *testing.T) {
+	fakeData := getMRFakeData()
+	fakeData["Action"] = "update"
+
+	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedSlack := &mSlack.Slack{}
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+	w.MergeRequestEvent(genMRBody(fakeData))
+
+	mockedDB.AssertNotCalled(t, "GetMergeRequest", mock.Anything, mock.Anything)
+}
+
+func mrBadgeOnly(state string) *slack.Attachment {
+	return mrAttachment(&model.MergeRequest{State: state})
+}
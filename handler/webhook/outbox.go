@@ -0,0 +1,306 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"gitlack/model"
+	"gitlack/resource/slack"
+
+	"github.com/sirupsen/logrus"
+)
+
+// kinds of GitLab object a Slack thread belongs to
+const (
+	kindMergeRequest = "merge_request"
+	kindIssue        = "issue"
+)
+
+// errThreadPending is returned when the reply is held in outbox until its thread is started
+var errThreadPending = errors.New("thread is still in outbox")
+
+const (
+	outboxBaseDelay   = 10 * time.Second
+	outboxMaxDelay    = time.Hour
+	outboxMaxAttempts = 10
+)
+
+// message is a Slack message about to be posted
+// objectKind and objectNum are set when the message starts a thread of merge request or issue,
+// threadKind and objectNum are set instead of threadTS when it replies to a thread which is still in outbox,
+// copies are the other routed channels and mirrors are the other threads which get the same message,
+// reviewers are the assignee followed by the reviewers notified once the message is posted,
+// event is the webhook event whose follow-ups are sent once the root message is delivered from outbox,
+// notice is sent once the message is posted in thread
+type message struct {
+	channel    string
+	copies     []string
+	mirrors    []*model.Thread
+	reviewers  []*model.User
+	event      interface{}
+	notice     *notice
+	text       string
+	author     *model.User
+	attachment *slack.Attachment
+	blocks     []slack.Block
+	threadTS   string
+	threadKind string
+	projectID  int
+	objectKind string
+	objectNum  int
+}
+
+// post sends message to Slack, the message is put into outbox if Slack fails,
+// the reply to a thread which isn't started yet is put into outbox at once
+func (h *hook) post(m *message) (*slack.MessageResponse, error) {
+	if m.threadKind != "" {
+		h.putOutbox(m, 0, "")
+		return nil, errThreadPending
+	}
+	res, err := h.send(m.channel, m.text, m.author, m.attachment, m.blocks, m.threadTS)
+	if err == nil {
+		return res, nil
+	}
+	logrus.Errorln(err)
+	h.putOutbox(m, 1, err.Error())
+	return nil, err
+}
+
+// putOutbox puts message into outbox after the attempts failed with lastError,
+// it's due with the root message if it replies to a thread which isn't started yet
+func (h *hook) putOutbox(m *message, attempts int, lastError string) {
+	now := time.Now()
+	msg := &model.OutboxMessage{
+		Channel:       m.channel,
+		Text:          m.text,
+		ThreadTS:      m.threadTS,
+		ProjectID:     m.projectID,
+		ObjectKind:    m.objectKind,
+		ObjectNum:     m.objectNum,
+		ThreadKind:    m.threadKind,
+		Status:        model.OutboxPending,
+		Attempts:      attempts,
+		NextAttemptAt: now.Add(outboxDelay(1)),
+		LastError:     lastError,
+		CreatedAt:     now,
+	}
+	if m.author != nil {
+		msg.AuthorID = m.author.GitLabID
+	}
+	if m.attachment != nil {
+		b, err := json.Marshal(m.attachment)
+		if err != nil {
+			logrus.Errorln(err)
+			return
+		}
+		msg.Attachment = string(b)
+	}
+	if m.blocks != nil {
+		b, err := json.Marshal(m.blocks)
+		if err != nil {
+			logrus.Errorln(err)
+			return
+		}
+		msg.Blocks = string(b)
+	}
+	if m.event != nil {
+		b, err := json.Marshal(m.event)
+		if err != nil {
+			logrus.Errorln(err)
+			return
+		}
+		msg.Event = string(b)
+	}
+	if err := h.db.CreateOutboxMessage(msg); err == nil {
+		logrus.Infof("message to %v is put into outbox, id: %v", m.channel, msg.ID)
+	}
+}
+
+// send posts message with blocks if there is any, plain text message otherwise
+func (h *hook) send(channel, text string, author *model.User, atm *slack.Attachment, blocks []slack.Block, threadTS string) (*slack.MessageResponse, error) {
+	var thread []string
+	if threadTS != "" {
+		thread = append(thread, threadTS)
+	}
+	if blocks != nil {
+		return h.s.PostSlackBlocks(channel, text, author, atm, blocks, thread...)
+	}
+	return h.s.PostSlackMessage(channel, text, author, atm, thread...)
+}
+
+// saveThread records the Slack thread of merge request or issue, text and blocks are the root message
+func (h *hook) saveThread(kind string, projectID, num int, text string, blocks []slack.Block, res *slack.MessageResponse) {
+	if res == nil {
+		return
+	}
+	switch kind {
+	case kindMergeRequest:
+		mr := &model.MergeRequest{
+			ProjectID:       projectID,
+			MergeRequestNum: num,
+			ThreadTS:        res.TS,
+			Channel:         res.Channel,
+			Text:            text,
+			State:           mrOpened,
+			LastActivityAt:  time.Now(),
+		}
+		if blocks != nil {
+			b, err := json.Marshal(blocks)
+			if err != nil {
+				logrus.Errorln(err)
+			} else {
+				mr.Blocks = string(b)
+			}
+		}
+		h.db.CreateMergeRequest(mr)
+	case kindIssue:
+		h.db.CreateIssue(&model.Issue{
+			ProjectID: projectID,
+			IssueNum:  num,
+			ThreadTS:  res.TS,
+			Channel:   res.Channel,
+		})
+	case kindMergeRequestMirror, kindIssueMirror:
+		h.saveMirror(kind, projectID, num, res.Channel, res.TS)
+	}
+}
+
+// DispatchOutbox retries the messages in outbox which are due
+func (h *hook) DispatchOutbox() {
+	if !atomic.CompareAndSwapInt32(&h.dispatching, 0, 1) {
+		logrus.Debugln("outbox is being dispatched")
+		return
+	}
+	defer atomic.StoreInt32(&h.dispatching, 0)
+
+	msgs, err := h.db.ListDueOutboxMessages(time.Now())
+	if err != nil {
+		return
+	}
+	for _, msg := range msgs {
+		h.deliver(msg)
+	}
+}
+
+func (h *hook) deliver(msg *model.OutboxMessage) {
+	if msg.ThreadKind != "" && !h.resolveThread(msg) {
+		return
+	}
+	var author *model.User
+	if msg.AuthorID != 0 {
+		u, err := h.db.GetUserByID(msg.AuthorID)
+		if err == nil {
+			author = u
+		}
+	}
+	var attachment *slack.Attachment
+	if msg.Attachment != "" {
+		attachment = &slack.Attachment{}
+		err := json.Unmarshal([]byte(msg.Attachment), attachment)
+		if err != nil {
+			logrus.Errorln(err)
+			return
+		}
+	}
+	var blocks []slack.Block
+	if msg.Blocks != "" {
+		err := json.Unmarshal([]byte(msg.Blocks), &blocks)
+		if err != nil {
+			logrus.Errorln(err)
+			return
+		}
+	}
+
+	msg.Attempts++
+	res, err := h.send(msg.Channel, msg.Text, author, attachment, blocks, msg.ThreadTS)
+	if err != nil {
+		msg.LastError = err.Error()
+		if msg.Attempts >= outboxMaxAttempts {
+			msg.Status = model.OutboxFailed
+			logrus.Warnf("message %v failed after %v attempts", msg.ID, msg.Attempts)
+		} else {
+			msg.NextAttemptAt = time.Now().Add(outboxDelay(msg.Attempts))
+		}
+		h.db.UpdateOutboxMessage(msg)
+		return
+	}
+
+	msg.Status = model.OutboxSent
+	msg.LastError = ""
+	err = h.db.UpdateOutboxMessage(msg)
+	if err != nil {
+		return
+	}
+	h.saveThread(msg.ObjectKind, msg.ProjectID, msg.ObjectNum, msg.Text, blocks, res)
+	h.followUp(msg, res)
+}
+
+// resolveThread sets the thread the message replies to once its root message is delivered,
+// the message waits for the root message still in outbox, and fails with it otherwise
+func (h *hook) resolveThread(msg *model.OutboxMessage) bool {
+	var err error
+	switch msg.ThreadKind {
+	case kindMergeRequest:
+		var mr *model.MergeRequest
+		if mr, err = h.db.GetMergeRequest(msg.ProjectID, msg.ObjectNum); err == nil {
+			msg.Channel, msg.ThreadTS = mr.Channel, mr.ThreadTS
+		}
+	case kindIssue:
+		var issue *model.Issue
+		if issue, err = h.db.GetIssue(msg.ProjectID, msg.ObjectNum); err == nil {
+			msg.Channel, msg.ThreadTS = issue.Channel, issue.ThreadTS
+		}
+	}
+	if err == nil && msg.ThreadTS != "" {
+		return true
+	}
+
+	root, err := h.db.GetPendingOutboxMessage(msg.ThreadKind, msg.ProjectID, msg.ObjectNum)
+	if err == nil {
+		msg.NextAttemptAt = root.NextAttemptAt
+	} else {
+		msg.Status = model.OutboxFailed
+		msg.LastError = "thread isn't started"
+		logrus.Warnf("message %v failed since thread of %v %v#%v isn't started", msg.ID, msg.ThreadKind, msg.ProjectID, msg.ObjectNum)
+	}
+	h.db.UpdateOutboxMessage(msg)
+	return false
+}
+
+// followUp sends the follow-ups of the root message of merge request or issue in the thread it starts
+func (h *hook) followUp(msg *model.OutboxMessage, res *slack.MessageResponse) {
+	if msg.Event == "" {
+		return
+	}
+	var err error
+	switch msg.ObjectKind {
+	case kindMergeRequest:
+		var mr MergeRequestEvent
+		if err = json.Unmarshal([]byte(msg.Event), &mr); err == nil {
+			reviewers := h.users(append([]UserInfo{{ID: mr.ObjAttr.AssigneeID}}, mr.Reviewers...))
+			h.notifyRequested(mr, reviewers, res.Channel, res.TS)
+		}
+	case kindIssue:
+		var issue IssuesEvent
+		if err = json.Unmarshal([]byte(msg.Event), &issue); err == nil {
+			h.notifyAssignees(issue, h.users(issue.Assignees), res.Channel, res.TS)
+		}
+	}
+	if err != nil {
+		logrus.Errorln(err)
+	}
+}
+
+// outboxDelay returns the delay before next attempt, it doubles every attempt
+func outboxDelay(attempts int) time.Duration {
+	d := outboxBaseDelay
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= outboxMaxDelay {
+			return outboxMaxDelay
+		}
+	}
+	return d
+}
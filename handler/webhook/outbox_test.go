@@ -0,0 +1,365 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"gitlack/model"
+	"gitlack/resource/slack"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mSlack "gitlack/resource/slack/mocks"
+	mDB "gitlack/store/mocks"
+)
+
+func TestPostEnqueue(t *testing.T) {
+	author := &model.User{
+		GitLabID: 2,
+		SlackID:  "fake-author-slack-id",
+	}
+	attachment := &slack.Attachment{
+		Color: slack.AttachmentColor,
+		Title: "fake-title",
+		Text:  "fake-text",
+	}
+	m := &message{
+		channel:    "fake-channel",
+		text:       "fake-slack-text",
+		author:     author,
+		attachment: attachment,
+		blocks:     []slack.Block{slack.DividerBlock()},
+		projectID:  999,
+		objectKind: kindIssue,
+		objectNum:  1,
+	}
+
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("PostSlackBlocks", m.channel, m.text, author, attachment, m.blocks).Return(nil, errors.New("fake-slack-error"))
+
+	var enqueued *model.OutboxMessage
+	mockedDB := &mDB.Store{}
+	mockedDB.On("CreateOutboxMessage", mock.Anything).Run(func(args mock.Arguments) {
+		enqueued = args.Get(0).(*model.OutboxMessage)
+	}).Return(nil)
+
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+	res, err := w.post(m)
+
+	assert.Nil(t, res)
+	assert.Error(t, err)
+	mockedDB.AssertNumberOfCalls(t, "CreateOutboxMessage", 1)
+	assert.Equal(t, m.channel, enqueued.Channel)
+	assert.Equal(t, m.text, enqueued.Text)
+	assert.Equal(t, author.GitLabID, enqueued.AuthorID)
+	assert.Equal(t, `{"color":"#FF5511","title":"fake-title","text":"fake-text"}`, enqueued.Attachment)
+	assert.Equal(t, `[{"type":"divider"}]`, enqueued.Blocks)
+	assert.Equal(t, "", enqueued.ThreadTS)
+	assert.Equal(t, kindIssue, enqueued.ObjectKind)
+	assert.Equal(t, 1, enqueued.ObjectNum)
+	assert.Equal(t, model.OutboxPending, enqueued.Status)
+	assert.Equal(t, 1, enqueued.Attempts)
+	assert.Equal(t, "fake-slack-error", enqueued.LastError)
+	assert.True(t, enqueued.NextAttemptAt.After(enqueued.CreatedAt))
+}
+
+func TestMRSlackFailed(t *testing.T) {
+	fakeData := getMRFakeData()
+	mockedProject := &model.Project{
+		ID:   fakeData["ProjectID"].(int),
+		Name: fakeData["Path"].(string),
+	}
+
+	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(mockedProject, nil)
+	mockedDB.On("GetUserByID", mock.Anything).Return(&model.User{}, nil)
+	mockedDB.On("CreateOutboxMessage", mock.Anything).Return(nil)
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
+
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("PostSlackBlocks", "general", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("fake-slack-error"))
+
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+	w.MergeRequestEvent(genMRBody(fakeData))
+
+	// thread is recorded by dispatcher after the message is delivered
+	mockedDB.AssertNumberOfCalls(t, "CreateOutboxMessage", 1)
+	mockedDB.AssertNotCalled(t, "CreateMergeRequest", mock.Anything)
+}
+
+func TestDispatchOutbox(t *testing.T) {
+	author := &model.User{
+		GitLabID: 2,
+		SlackID:  "fake-author-slack-id",
+	}
+	sent := &model.OutboxMessage{
+		ID:         1,
+		Channel:    "fake-channel",
+		Text:       "fake-mr-text",
+		Blocks:     `[{"type":"divider"}]`,
+		ProjectID:  999,
+		ObjectKind: kindMergeRequest,
+		ObjectNum:  1,
+		Status:     model.OutboxPending,
+		Attempts:   1,
+	}
+	retried := &model.OutboxMessage{
+		ID:         2,
+		Channel:    "fake-channel",
+		Text:       "fake-comment-text",
+		AuthorID:   author.GitLabID,
+		Attachment: `{"color":"#FF5511","title":"fake-title","text":"fake-text"}`,
+		ThreadTS:   "1234567890.000001",
+		ProjectID:  999,
+		Status:     model.OutboxPending,
+		Attempts:   1,
+	}
+	failed := &model.OutboxMessage{
+		ID:       3,
+		Channel:  "fake-channel",
+		Text:     "fake-push-text",
+		Status:   model.OutboxPending,
+		Attempts: outboxMaxAttempts - 1,
+	}
+	attachment := &slack.Attachment{
+		Color: slack.AttachmentColor,
+		Title: "fake-title",
+		Text:  "fake-text",
+	}
+	mockedMessageReponse := &slack.MessageResponse{
+		OK:      true,
+		Channel: "fake-channel-id",
+		TS:      "1234567890.123456",
+	}
+	mockedMR := &model.MergeRequest{
+		ProjectID:       999,
+		MergeRequestNum: 1,
+		ThreadTS:        mockedMessageReponse.TS,
+		Channel:         mockedMessageReponse.Channel,
+		Text:            sent.Text,
+		State:           mrOpened,
+		Blocks:          sent.Blocks,
+	}
+
+	var nilUser *model.User
+	var nilAtm *slack.Attachment
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("PostSlackBlocks", sent.Channel, sent.Text, nilUser, nilAtm, []slack.Block{slack.DividerBlock()}).Return(mockedMessageReponse, nil)
+	mockedSlack.On("PostSlackMessage", retried.Channel, retried.Text, author, attachment, retried.ThreadTS).Return(nil, errors.New("fake-slack-error"))
+	mockedSlack.On("PostSlackMessage", failed.Channel, failed.Text, nilUser, nilAtm).Return(nil, errors.New("fake-slack-error"))
+
+	mockedDB := &mDB.Store{}
+	mockedDB.On("ListDueOutboxMessages", mock.Anything).Return([]*model.OutboxMessage{sent, retried, failed}, nil)
+	mockedDB.On("GetUserByID", author.GitLabID).Return(author, nil)
+	mockedDB.On("UpdateOutboxMessage", mock.Anything).Return(nil)
+	mockedDB.On("CreateMergeRequest", recordedMR(mockedMR)).Return(nil)
+
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+	before := time.Now()
+	w.DispatchOutbox()
+
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackBlocks", 1)
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 2)
+	mockedDB.AssertNumberOfCalls(t, "UpdateOutboxMessage", 3)
+	mockedDB.AssertNumberOfCalls(t, "CreateMergeRequest", 1)
+
+	assert.Equal(t, model.OutboxSent, sent.Status)
+	assert.Equal(t, 2, sent.Attempts)
+
+	assert.Equal(t, model.OutboxPending, retried.Status)
+	assert.Equal(t, 2, retried.Attempts)
+	assert.Equal(t, "fake-slack-error", retried.LastError)
+	assert.True(t, !retried.NextAttemptAt.Before(before.Add(outboxDelay(2))))
+
+	assert.Equal(t, model.OutboxFailed, failed.Status)
+	assert.Equal(t, outboxMaxAttempts, failed.Attempts)
+}
+
+func TestCommentWaitsForPendingThread(t *testing.T) {
+	var comment CommentsEvent
+	comment.ObjAttr.AuthorID = 2
+	comment.ObjAttr.NoteableType = "MergeRequest"
+	comment.ObjAttr.Note = "fake-note"
+	comment.ProjectInfo.ID = 999
+	comment.MergeRequestInfo.Num = 3
+	root := &model.OutboxMessage{
+		ID:         1,
+		Channel:    "fake-channel",
+		ProjectID:  999,
+		ObjectKind: kindMergeRequest,
+		ObjectNum:  3,
+		Status:     model.OutboxPending,
+	}
+
+	var enqueued *model.OutboxMessage
+	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedDB.On("GetUserByID", 2).Return(&model.User{GitLabID: 2, Name: "fake-commenter"}, nil)
+	mockedDB.On("GetMergeRequest", 999, 3).Return(nil, errors.New("sql: no rows in result set"))
+	mockedDB.On("GetPendingOutboxMessage", kindMergeRequest, 999, 3).Return(root, nil)
+	mockedDB.On("ListThreads", mock.Anything, mock.Anything, mock.Anything).Return([]*model.Thread{}, nil)
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("CreateOutboxMessage", mock.Anything).Run(func(args mock.Arguments) {
+		enqueued = args.Get(0).(*model.OutboxMessage)
+	}).Return(nil)
+	mockedSlack := &mSlack.Slack{}
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+
+	b, _ := json.Marshal(comment)
+	w.CommentsEvent(b)
+
+	// the comment isn't posted out of the thread
+	mockedSlack.AssertNotCalled(t, "PostSlackBlocks", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockedDB.AssertNumberOfCalls(t, "CreateOutboxMessage", 1)
+	assert.Equal(t, "fake-channel", enqueued.Channel)
+	assert.Equal(t, "", enqueued.ThreadTS)
+	assert.Equal(t, kindMergeRequest, enqueued.ThreadKind)
+	assert.Equal(t, "", enqueued.ObjectKind)
+	assert.Equal(t, 3, enqueued.ObjectNum)
+	assert.Equal(t, 0, enqueued.Attempts)
+}
+
+func TestDispatchOutboxReplies(t *testing.T) {
+	resolved := &model.OutboxMessage{
+		ID:         2,
+		Channel:    "fake-channel",
+		Text:       "fake-comment-text",
+		ProjectID:  999,
+		ObjectNum:  1,
+		ThreadKind: kindMergeRequest,
+		Status:     model.OutboxPending,
+	}
+	waiting := &model.OutboxMessage{
+		ID:         3,
+		Channel:    "fake-channel",
+		Text:       "fake-issue-comment-text",
+		ProjectID:  999,
+		ObjectNum:  2,
+		ThreadKind: kindIssue,
+		Status:     model.OutboxPending,
+	}
+	orphan := &model.OutboxMessage{
+		ID:         4,
+		Channel:    "fake-channel",
+		Text:       "fake-pipeline-text",
+		ProjectID:  999,
+		ObjectNum:  3,
+		ThreadKind: kindMergeRequest,
+		Status:     model.OutboxPending,
+	}
+	root := &model.OutboxMessage{
+		ID:            1,
+		Status:        model.OutboxPending,
+		NextAttemptAt: time.Now().Add(time.Minute),
+	}
+
+	var nilUser *model.User
+	var nilAtm *slack.Attachment
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("PostSlackMessage", "fake-thread-channel", resolved.Text, nilUser, nilAtm, "fake-ts").Return(&slack.MessageResponse{OK: true}, nil)
+
+	mockedDB := &mDB.Store{}
+	mockedDB.On("ListDueOutboxMessages", mock.Anything).Return([]*model.OutboxMessage{resolved, waiting, orphan}, nil)
+	mockedDB.On("GetMergeRequest", 999, 1).Return(&model.MergeRequest{Channel: "fake-thread-channel", ThreadTS: "fake-ts"}, nil)
+	mockedDB.On("GetIssue", 999, 2).Return(nil, errors.New("sql: no rows in result set"))
+	mockedDB.On("GetPendingOutboxMessage", kindIssue, 999, 2).Return(root, nil)
+	mockedDB.On("GetMergeRequest", 999, 3).Return(nil, errors.New("sql: no rows in result set"))
+	mockedDB.On("GetPendingOutboxMessage", kindMergeRequest, 999, 3).Return(nil, errors.New("sql: no rows in result set"))
+	mockedDB.On("UpdateOutboxMessage", mock.Anything).Return(nil)
+
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+	w.DispatchOutbox()
+
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1)
+	mockedDB.AssertNumberOfCalls(t, "UpdateOutboxMessage", 3)
+
+	assert.Equal(t, model.OutboxSent, resolved.Status)
+
+	// the reply waits for the root message without using up the attempts
+	assert.Equal(t, model.OutboxPending, waiting.Status)
+	assert.Equal(t, 0, waiting.Attempts)
+	assert.Equal(t, root.NextAttemptAt, waiting.NextAttemptAt)
+
+	assert.Equal(t, model.OutboxFailed, orphan.Status)
+}
+
+func TestDispatchOutboxFollowUp(t *testing.T) {
+	var issue IssuesEvent
+	issue.ObjAttr.AuthorID = 1
+	issue.ObjAttr.ObjectNum = 2
+	issue.ProjectInfo.ID = 999
+	issue.Assignees = []UserInfo{{ID: 3}}
+	event, _ := json.Marshal(issue)
+	root := &model.OutboxMessage{
+		ID:         1,
+		Channel:    "fake-channel",
+		Text:       "fake-issue-text",
+		ProjectID:  999,
+		ObjectKind: kindIssue,
+		ObjectNum:  2,
+		Event:      string(event),
+		Status:     model.OutboxPending,
+		Attempts:   1,
+	}
+	mockedMessageReponse := &slack.MessageResponse{
+		OK:      true,
+		Channel: "fake-channel-id",
+		TS:      "1234567890.123456",
+	}
+
+	var nilUser *model.User
+	var nilAtm *slack.Attachment
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("PostSlackMessage", root.Channel, root.Text, nilUser, nilAtm).Return(mockedMessageReponse, nil)
+	mockedSlack.On("PostSlackMessage", mockedMessageReponse.Channel, "<@fake-assignee> you are assigned to this issue.", nilUser, nilAtm, mockedMessageReponse.TS).
+		Return(&slack.MessageResponse{OK: true}, nil)
+
+	mockedDB := &mDB.Store{}
+	mockedDB.On("ListDueOutboxMessages", mock.Anything).Return([]*model.OutboxMessage{root}, nil)
+	mockedDB.On("UpdateOutboxMessage", mock.Anything).Return(nil)
+	mockedDB.On("CreateIssue", mock.Anything).Return(nil)
+	mockedDB.On("GetUserByID", 3).Return(&model.User{GitLabID: 3, SlackID: "fake-assignee"}, nil)
+
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+	w.DispatchOutbox()
+
+	mockedDB.AssertNumberOfCalls(t, "CreateIssue", 1)
+	mockedSlack.AssertExpectations(t)
+}
+
+func TestOutboxDelay(t *testing.T) {
+	input := []int{1, 2, 3, 9, outboxMaxAttempts}
+	expected := []time.Duration{
+		10 * time.Second,
+		20 * time.Second,
+		40 * time.Second,
+		2560 * time.Second,
+		time.Hour,
+	}
+	for i, attempts := range input {
+		assert.Equal(t, expected[i], outboxDelay(attempts))
+	}
+}
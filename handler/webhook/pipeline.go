@@ -0,0 +1,136 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gitlack/model"
+
+	"github.com/sirupsen/logrus"
+)
+
+// pipelineTemplates maps the pipeline status to the text posted to the merge request thread,
+// other statuses such as pending or running are ignored
+var pipelineTemplates = map[string]string{
+	"failed":   "Pipeline <%v|#%v> failed!",
+	"success":  "Pipeline <%v|#%v> passed.",
+	"canceled": "Pipeline <%v|#%v> has been canceled.",
+	"manual":   "Pipeline <%v|#%v> is waiting for a manual action.",
+}
+
+// PipelineEvent represents the data structure of pipeline events
+type PipelineEvent struct {
+	ObjAttr          PipelineAttributes `json:"object_attributes"`
+	ProjectInfo      Project            `json:"project"`
+	MergeRequestInfo MergeRequest       `json:"merge_request"`
+}
+
+func (h *hook) PipelineEvent(b []byte) error {
+	var pipeline PipelineEvent
+	err := json.Unmarshal(b, &pipeline)
+	if err != nil {
+		logrus.Errorln(err)
+		return err
+	}
+	// pipelines of any status are activities of merge request
+	if pipeline.MergeRequestInfo.Num != 0 {
+		h.touchMR(pipeline.ProjectInfo.ID, pipeline.MergeRequestInfo.Num)
+	}
+
+	tpl, ok := pipelineTemplates[pipeline.ObjAttr.Status]
+	if !ok {
+		logrus.Debugf("pipeline status not supported: %v", pipeline.ObjAttr.Status)
+		return nil
+	}
+
+	// `merge_request` is null if the pipeline runs on a branch, it's of the open merge request from the branch
+	if pipeline.MergeRequestInfo.Num == 0 {
+		pipeline.MergeRequestInfo.Num, err = h.branchMergeRequest(pipeline)
+		if err != nil {
+			return failure(err)
+		}
+		h.touchMR(pipeline.ProjectInfo.ID, pipeline.MergeRequestInfo.Num)
+	}
+
+	pipelineURL := fmt.Sprintf("%v/pipelines/%v", pipeline.ProjectInfo.WebURL, pipeline.ObjAttr.ID)
+	slackText := fmt.Sprintf(tpl, pipelineURL, pipeline.ObjAttr.ID)
+	m := &message{
+		text:      slackText,
+		mirrors:   h.mirrors(kindMergeRequest, pipeline.ProjectInfo.ID, pipeline.MergeRequestInfo.Num),
+		projectID: pipeline.ProjectInfo.ID,
+		objectNum: pipeline.MergeRequestInfo.Num,
+	}
+	mrThread, err := h.db.GetMergeRequest(pipeline.ProjectInfo.ID, pipeline.MergeRequestInfo.Num)
+	if err == nil {
+		m.channel, m.threadTS = mrThread.Channel, mrThread.ThreadTS
+	} else if m.channel, err = h.pendingThread(kindMergeRequest, pipeline.ProjectInfo.ID, pipeline.MergeRequestInfo.Num); err == nil {
+		// the result waits in outbox for the root message
+		m.threadKind = kindMergeRequest
+	} else {
+		return failure(missingThread(err))
+	}
+	if pipeline.ObjAttr.Status == "failed" {
+		h.ccAuthor(m, pipeline)
+	}
+	h.post(m)
+	h.postCopies(m)
+	if m.notice != nil {
+		h.notify(m.notice)
+	}
+	// the root message isn't recorded to update and react until it's delivered
+	if mrThread == nil {
+		return nil
+	}
+	mrThread.PipelineStatus = pipeline.ObjAttr.Status
+	h.updateRootMR(mrThread)
+
+	// only the latest pipeline result is reacted
+	for _, t := range m.threads() {
+		switch pipeline.ObjAttr.Status {
+		case "failed":
+			h.react(pipeline.ProjectInfo.ID, t.Channel, t.ThreadTS, reactPipelineFailed, reactPipelineSuccess)
+		case "success":
+			h.react(pipeline.ProjectInfo.ID, t.Channel, t.ThreadTS, reactPipelineSuccess, reactPipelineFailed)
+		}
+	}
+	return nil
+}
+
+// branchMergeRequest returns the number of open merge request whose source branch and last commit are of the pipeline
+func (h *hook) branchMergeRequest(pipeline PipelineEvent) (int, error) {
+	if pipeline.ObjAttr.Tag {
+		err := skip("pipeline %v runs on tag %v", pipeline.ObjAttr.ID, pipeline.ObjAttr.Ref)
+		logrus.Infoln(err)
+		return 0, err
+	}
+	mrs, err := h.g.ListOpenMergeRequestsBySourceBranch(pipeline.ProjectInfo.ID, pipeline.ObjAttr.Ref)
+	if err != nil {
+		return 0, err
+	}
+	for _, mr := range mrs {
+		if mr.SHA == pipeline.ObjAttr.SHA {
+			return mr.IID, nil
+		}
+	}
+	err = skip("pipeline %v isn't on the last commit of an open merge request from %v", pipeline.ObjAttr.ID, pipeline.ObjAttr.Ref)
+	logrus.Infoln(err)
+	return 0, err
+}
+
+// ccAuthor copies the author of merge request on the failed pipeline,
+// the author isn't given by pipeline events so it's got from GitLab
+func (h *hook) ccAuthor(m *message, pipeline PipelineEvent) {
+	mr, err := h.g.GetMergeRequest(pipeline.ProjectInfo.ID, pipeline.MergeRequestInfo.Num)
+	if err != nil {
+		return
+	}
+	author, err := h.db.GetUserByID(mr.Author.ID)
+	if err != nil {
+		return
+	}
+	ccOwner(m, author, model.MentionPipelineFailed, pipeline.ProjectInfo.PathWithNamespace, func() string {
+		return fmt.Sprintf("Pipeline <%v/pipelines/%v|#%v> failed on your merge request <%v|%v!%v> %v",
+			pipeline.ProjectInfo.WebURL, pipeline.ObjAttr.ID, pipeline.ObjAttr.ID,
+			mr.WebURL, pipeline.ProjectInfo.PathWithNamespace, mr.IID, mr.Title)
+	})
+}
@@ -0,0 +1,188 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"gitlack/model"
+	"gitlack/resource/gitlab"
+	"gitlack/resource/slack"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mGitLab "gitlack/resource/gitlab/mocks"
+	mSlack "gitlack/resource/slack/mocks"
+	mDB "gitlack/store/mocks"
+)
+
+const pipelineBodyTemplate = `
+{
+	"object_attributes": {
+		"id": {{.PipelineID}},
+		"ref": "fake-source-branch",
+		"sha": "fake-commit-sha",
+		"status": "{{.Status}}"
+	},
+	"merge_request": {{if .MRNum}}{
+		"iid": {{.MRNum}}
+	}{{else}}null{{end}},
+	"project": {
+		"id": {{.ProjectID}},
+		"web_url": "http://fake.com/{{.Path}}",
+		"path_with_namespace": "{{.Path}}"
+	}
+}`
+
+func getPipelineFakeData() map[string]interface{} {
+	return map[string]interface{}{
+		"PipelineID": 31,
+		"Status":     "failed",
+		"MRNum":      1,
+		"ProjectID":  999,
+		"Path":       "fake/fake-gitlab-project",
+	}
+}
+
+func genPipelineBody(data map[string]interface{}) []byte {
+	body := renderTemplate(pipelineBodyTemplate, data)
+	return body.Bytes()
+}
+
+func TestPipelineEvent(t *testing.T) {
+	fakeData := getPipelineFakeData()
+	pipelineURL := fmt.Sprintf("http://fake.com/%v/pipelines/%v", fakeData["Path"].(string), fakeData["PipelineID"].(int))
+
+	input := map[string]string{
+		"failed":   fmt.Sprintf("Pipeline <%v|#%v> failed!", pipelineURL, fakeData["PipelineID"].(int)),
+		"success":  fmt.Sprintf("Pipeline <%v|#%v> passed.", pipelineURL, fakeData["PipelineID"].(int)),
+		"canceled": fmt.Sprintf("Pipeline <%v|#%v> has been canceled.", pipelineURL, fakeData["PipelineID"].(int)),
+		"manual":   fmt.Sprintf("Pipeline <%v|#%v> is waiting for a manual action.", pipelineURL, fakeData["PipelineID"].(int)),
+	}
+
+	mockedMR := &model.MergeRequest{
+		ProjectID:       fakeData["ProjectID"].(int),
+		MergeRequestNum: fakeData["MRNum"].(int),
+		ThreadTS:        "1234567890.123456",
+		Channel:         "fake-channel",
+		Text:            "fake-root-text",
+		Blocks:          `[{"type":"divider"}]`,
+		State:           mrOpened,
+	}
+
+	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedDB.On("GetMergeRequest", fakeData["ProjectID"].(int), fakeData["MRNum"].(int)).Return(mockedMR, nil)
+	mockedDB.On("ListThreads", mock.Anything, mock.Anything, mock.Anything).Return([]*model.Thread{}, nil)
+	mockedDB.On("UpdateMergeRequest", mockedMR).Return(nil)
+	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(&model.Project{}, nil)
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("AddReaction", mockedMR.Channel, mockedMR.ThreadTS, mock.Anything).Return(nil)
+	mockedSlack.On("RemoveReaction", mockedMR.Channel, mockedMR.ThreadTS, mock.Anything).Return(nil)
+	// the author of merge request is unknown
+	stubGitLab := &mGitLab.GitLab{}
+	stubGitLab.On("GetMergeRequest", fakeData["ProjectID"].(int), fakeData["MRNum"].(int)).Return(nil, errors.New("fake error"))
+
+	var nilUser *model.User
+	var nilAtm *slack.Attachment
+	for status, e := range input {
+		fakeData["Status"] = status
+		w := &hook{
+			db: mockedDB,
+			s:  mockedSlack,
+			g:  stubGitLab,
+		}
+		mockedSlack.On("PostSlackMessage", mockedMR.Channel, e, nilUser, nilAtm, mockedMR.ThreadTS).Return(nil, nil)
+		mockedSlack.On("UpdateSlackMessage", mockedMR.Channel, mockedMR.ThreadTS, mockedMR.Text, mock.Anything, mock.Anything).Return(nil, nil)
+		w.PipelineEvent(genPipelineBody(fakeData))
+
+		assert.Equal(t, status, mockedMR.PipelineStatus)
+		mockedSlack.AssertCalled(t, "UpdateSlackMessage", mockedMR.Channel, mockedMR.ThreadTS, mockedMR.Text, mrAttachment(mockedMR), []slack.Block{slack.DividerBlock()})
+	}
+
+	mockedDB.AssertNumberOfCalls(t, "GetMergeRequest", len(input))
+	mockedDB.AssertNumberOfCalls(t, "UpdateMergeRequest", len(input))
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", len(input))
+	mockedSlack.AssertNumberOfCalls(t, "UpdateSlackMessage", len(input))
+
+	// pipeline_success has no emoji by default
+	mockedSlack.AssertCalled(t, "AddReaction", mockedMR.Channel, mockedMR.ThreadTS, "x")
+	mockedSlack.AssertNumberOfCalls(t, "AddReaction", 1)
+	mockedSlack.AssertCalled(t, "RemoveReaction", mockedMR.Channel, mockedMR.ThreadTS, "x")
+	mockedSlack.AssertNumberOfCalls(t, "RemoveReaction", 1)
+}
+
+func TestPipelineEventIgnoredStatus(t *testing.T) {
+	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedSlack := &mSlack.Slack{}
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+
+	fakeData := getPipelineFakeData()
+	for _, status := range []string{"pending", "running", "created", "skipped"} {
+		fakeData["Status"] = status
+		w.PipelineEvent(genPipelineBody(fakeData))
+	}
+
+	mockedDB.AssertNotCalled(t, "GetMergeRequest")
+	mockedSlack.AssertNotCalled(t, "PostSlackMessage")
+}
+
+func TestPipelineEventOfBranch(t *testing.T) {
+	mockedMR := &model.MergeRequest{ProjectID: 999, MergeRequestNum: 2, Channel: "fake-channel", ThreadTS: "fake-ts"}
+	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", 999, 2, mock.Anything).Return(nil)
+	mockedDB.On("GetMergeRequest", 999, 2).Return(mockedMR, nil)
+	mockedDB.On("ListThreads", mock.Anything, mock.Anything, mock.Anything).Return([]*model.Thread{}, nil)
+	mockedDB.On("UpdateMergeRequest", mockedMR).Return(nil)
+	mockedDB.On("GetProjectByID", 999).Return(&model.Project{}, nil)
+	mockedGitLab := &mGitLab.GitLab{}
+	mockedGitLab.On("ListOpenMergeRequestsBySourceBranch", 999, "fake-source-branch").Return([]*gitlab.MergeRequest{
+		{IID: 1, SHA: "fake-older-sha"},
+		{IID: 2, SHA: "fake-commit-sha"},
+	}, nil)
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("PostSlackMessage", "fake-channel", mock.Anything, mock.Anything, mock.Anything, "fake-ts").Return(&slack.MessageResponse{OK: true}, nil)
+	mockedSlack.On("AddReaction", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedSlack.On("RemoveReaction", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	w := &hook{
+		db: mockedDB,
+		g:  mockedGitLab,
+		s:  mockedSlack,
+	}
+
+	fakeData := getPipelineFakeData()
+	fakeData["MRNum"] = 0
+	fakeData["Status"] = "success"
+	err := w.PipelineEvent(genPipelineBody(fakeData))
+
+	assert.Nil(t, err)
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1)
+	mockedDB.AssertCalled(t, "UpdateMergeRequestActivity", 999, 2, mock.Anything)
+}
+
+func TestPipelineEventWithoutMergeRequest(t *testing.T) {
+	mockedDB := &mDB.Store{}
+	mockedGitLab := &mGitLab.GitLab{}
+	mockedGitLab.On("ListOpenMergeRequestsBySourceBranch", 999, "fake-source-branch").Return([]*gitlab.MergeRequest{
+		{IID: 1, SHA: "fake-older-sha"},
+	}, nil)
+	mockedSlack := &mSlack.Slack{}
+	w := &hook{
+		db: mockedDB,
+		g:  mockedGitLab,
+		s:  mockedSlack,
+	}
+
+	fakeData := getPipelineFakeData()
+	fakeData["MRNum"] = 0
+	err := w.PipelineEvent(genPipelineBody(fakeData))
+
+	assert.Nil(t, err)
+	mockedDB.AssertNotCalled(t, "GetMergeRequest")
+	mockedSlack.AssertNotCalled(t, "PostSlackMessage")
+}
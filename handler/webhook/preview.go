@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gitlack/resource/slack"
+)
+
+// Preview is the message which would be posted to Slack for a webhook event
+type Preview struct {
+	Channel     string              `json:"channel"`
+	Copies      []string            `json:"copies,omitempty"`
+	Text        string              `json:"text"`
+	ThreadTS    string              `json:"thread_ts,omitempty"`
+	Username    string              `json:"username,omitempty"`
+	IconURL     string              `json:"icon_url,omitempty"`
+	Attachments []*slack.Attachment `json:"attachments,omitempty"`
+	Blocks      []slack.Block       `json:"blocks,omitempty"`
+}
+
+// Preview renders the message of merge request, issue, tag push and comment events
+// the same way as they're handled, but neither Slack nor thread is touched
+func (h *hook) Preview(event string, b []byte) (*Preview, error) {
+	var m *message
+	var err error
+	switch event {
+	case "Merge Request Hook":
+		var mr MergeRequestEvent
+		if err = json.Unmarshal(b, &mr); err == nil {
+			m, err = h.mrMessage(mr)
+		}
+	case "Issue Hook":
+		var issue IssuesEvent
+		if err = json.Unmarshal(b, &issue); err == nil {
+			m, err = h.issueMessage(issue)
+		}
+	case "Tag Push Hook":
+		var tagPushInfo TagPushEvent
+		if err = json.Unmarshal(b, &tagPushInfo); err == nil {
+			m, err = h.tagPushMessage(tagPushInfo)
+		}
+	case "Note Hook":
+		var comment CommentsEvent
+		if err = json.Unmarshal(b, &comment); err == nil {
+			m, err = h.commentMessage(comment)
+		}
+	default:
+		return nil, fmt.Errorf("event not supported: %q", event)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Preview{
+		Channel:  m.channel,
+		Copies:   m.copies,
+		Text:     m.text,
+		ThreadTS: m.threadTS,
+		Blocks:   m.blocks,
+	}
+	for _, t := range m.mirrors {
+		p.Copies = append(p.Copies, t.Channel)
+	}
+	if m.author != nil {
+		p.Username = m.author.Name + " (Gitlack)"
+		p.IconURL = m.author.AvatarURL
+	}
+	if m.attachment != nil {
+		p.Attachments = []*slack.Attachment{m.attachment}
+	}
+	return p, nil
+}
@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"testing"
+
+	"gitlack/model"
+	"gitlack/resource/slack"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mSlack "gitlack/resource/slack/mocks"
+	mDB "gitlack/store/mocks"
+)
+
+func TestPreviewMR(t *testing.T) {
+	fakeData := getMRFakeData()
+	mockedAssignee := &model.User{SlackID: "fake-assignee-slack-id", DefaultChannel: "fake-assignee-channel"}
+	mockedAuthor := &model.User{SlackID: "fake-author-slack-id"}
+	mockedDB := &mDB.Store{}
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
+	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(&model.Project{}, nil)
+	mockedDB.On("GetUserByID", fakeData["AssigneeID"].(int)).Return(mockedAssignee, nil)
+	mockedDB.On("GetUserByID", fakeData["AuthorID"].(int)).Return(mockedAuthor, nil)
+	mockedSlack := &mSlack.Slack{}
+
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+	p, err := w.Preview("Merge Request Hook", genMRBody(fakeData))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "fake-assignee-channel", p.Channel)
+	assert.Contains(t, p.Text, "<@fake-assignee-slack-id> you are assigned to review")
+	assert.Equal(t, []*slack.Attachment{mrAttachment(&model.MergeRequest{State: mrOpened})}, p.Attachments)
+	assert.Equal(t, mrBlocks(MergeRequestEvent{}, mockedAuthor, mockedAssignee)[1], p.Blocks[1])
+	mockedDB.AssertNotCalled(t, "CreateMergeRequest", mock.Anything)
+	assert.Empty(t, mockedSlack.Calls)
+}
+
+func TestPreviewComment(t *testing.T) {
+	body := []byte(`{
+		"project": {"id": 999, "path_with_namespace": "fake/project"},
+		"object_attributes": {"noteable_type": "Issue", "author_id": 2, "note": "fake-note", "url": "fake-link"},
+		"issue": {"iid": 1}
+	}`)
+	mockedAuthor := &model.User{Name: "fake-author", AvatarURL: "fake-avatar"}
+	mockedDB := &mDB.Store{}
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
+	mockedDB.On("GetUserByID", 2).Return(mockedAuthor, nil)
+	mockedDB.On("GetIssue", 999, 1).Return(&model.Issue{Channel: "fake-channel", ThreadTS: "fake-ts"}, nil)
+	mockedDB.On("ListThreads", mock.Anything, mock.Anything, mock.Anything).Return([]*model.Thread{}, nil)
+
+	w := &hook{db: mockedDB}
+	p, err := w.Preview("Note Hook", body)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "fake-channel", p.Channel)
+	assert.Equal(t, "fake-ts", p.ThreadTS)
+	assert.Equal(t, "fake-author has <fake-link|commented:>\nfake-note", p.Text)
+	assert.Equal(t, "fake-author (Gitlack)", p.Username)
+	assert.Equal(t, "fake-avatar", p.IconURL)
+}
+
+func TestPreviewSamePerson(t *testing.T) {
+	fakeData := getMRFakeData()
+	fakeData["AssigneeID"] = fakeData["AuthorID"]
+
+	w := &hook{db: &mDB.Store{}}
+	_, err := w.Preview("Merge Request Hook", genMRBody(fakeData))
+
+	assert.Equal(t, errSamePerson, err)
+}
+
+func TestPreviewUnsupportedEvent(t *testing.T) {
+	w := &hook{}
+	_, err := w.Preview("Push Hook", []byte(`{}`))
+
+	assert.EqualError(t, err, `event not supported: "Push Hook"`)
+}
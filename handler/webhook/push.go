@@ -0,0 +1,186 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const pushTemplate = "<@{{.Author}}> has pushed {{.Count}} commit(s) to `{{.Branch}}` of `{{.Path}}` (<{{.Link}}|compare>)\n" +
+	"{{range .Commits}}<{{.URL}}|`{{.ShortID}}`> {{.Title}}\n{{end}}" +
+	"{{if .More}}and {{.More}} more commit(s)\n{{end}}"
+
+// maxPushCommits is the number of commits listed in a push announcement
+const maxPushCommits = 10
+
+// mergeRequestTrailer ends the message of merge and squash commits created by GitLab, e.g. `See merge request group/project!1`
+const mergeRequestTrailer = "See merge request "
+
+// emptySHA is the `before` of a newly created branch and the `after` of a deleted branch
+const emptySHA = "0000000000000000000000000000000000000000"
+
+// PushEvent represents the data structure of push in GitLab webhook request
+type PushEvent struct {
+	Before       string       `json:"before"`
+	After        string       `json:"after"`
+	Ref          string       `json:"ref"`
+	AuthorID     int          `json:"user_id"`
+	ProjectInfo  Project      `json:"project"`
+	Commits      []PushCommit `json:"commits"`
+	TotalCommits int          `json:"total_commits_count"`
+}
+
+// PushCommit represents the data structure of `commits` in GitLab push webhook request
+type PushCommit struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Message string `json:"message"`
+	URL     string `json:"url"`
+}
+
+func (h *hook) PushEvent(b []byte) error {
+	var push PushEvent
+	err := json.Unmarshal(b, &push)
+	if err != nil {
+		logrus.Errorln(err)
+		return err
+	}
+
+	// branch is deleted or nothing new is pushed
+	if push.After == emptySHA || push.TotalCommits == 0 {
+		logrus.Infoln("push has no new commit")
+		return nil
+	}
+
+	project, err := h.db.GetProjectByID(push.ProjectInfo.ID)
+	if err != nil {
+		return err
+	}
+	branch := strings.TrimPrefix(push.Ref, "refs/heads/")
+	if !matchBranch(project.WatchedBranches, branch) {
+		logrus.Debugf("branch is not watched: %v", branch)
+		return nil
+	}
+	// merges are announced in the threads of merge requests
+	if h.mergedByMR(push, branch) {
+		logrus.Infof("push to %v of %v merges a merge request", branch, push.ProjectInfo.PathWithNamespace)
+		return nil
+	}
+
+	author, err := h.db.GetUserByID(push.AuthorID)
+	if err != nil {
+		return err
+	}
+
+	// the pushed branch works as target branch
+	route, err := h.route(&RouteTarget{
+		Event:        RoutePush,
+		ProjectID:    push.ProjectInfo.ID,
+		Path:         push.ProjectInfo.PathWithNamespace,
+		TargetBranch: branch,
+		Author:       author,
+		Owner:        author,
+	})
+	if err != nil {
+		return err
+	}
+
+	// if user doesn't exist in Slack, use the name of user in GitLab instead
+	authorID := author.SlackID
+	if author.SlackID == "" {
+		authorID = author.Name
+	}
+
+	// new branch has nothing to compare with
+	link := fmt.Sprintf("%v/compare/%v...%v", push.ProjectInfo.WebURL, push.Before, push.After)
+	if push.Before == emptySHA {
+		link = fmt.Sprintf("%v/commits/%v", push.ProjectInfo.WebURL, branch)
+	}
+
+	// GitLab sends the commits from oldest to newest, list the newest ones
+	commits := push.Commits
+	if len(commits) > maxPushCommits {
+		commits = commits[len(commits)-maxPushCommits:]
+	}
+	var listed []map[string]string
+	for _, c := range commits {
+		shortID := c.ID
+		if len(shortID) > 8 {
+			shortID = shortID[:8]
+		}
+		listed = append(listed, map[string]string{
+			"ShortID": shortID,
+			"Title":   c.Title,
+			"URL":     c.URL,
+		})
+	}
+
+	// prepare slack text
+	data := map[string]interface{}{
+		"Author":  authorID,
+		"Count":   push.TotalCommits,
+		"Branch":  branch,
+		"Path":    push.ProjectInfo.PathWithNamespace,
+		"Link":    link,
+		"Commits": listed,
+		"More":    push.TotalCommits - len(listed),
+	}
+	slackText, _, err := h.render(TemplatePush, push.ProjectInfo.PathWithNamespace, data)
+	if err != nil {
+		return err
+	}
+	m := &message{
+		channel:   route.Channels[0],
+		copies:    route.Channels[1:],
+		text:      slackText,
+		projectID: push.ProjectInfo.ID,
+	}
+	h.post(m)
+	h.postCopies(m)
+	return nil
+}
+
+// mergedByMR reports whether the head commit of push is added to branch by merging a merge request,
+// which is either a commit created by GitLab or the merge or squash commit of a merge request targeting branch
+func (h *hook) mergedByMR(push PushEvent, branch string) bool {
+	if len(push.Commits) != 0 && strings.Contains(push.Commits[len(push.Commits)-1].Message, mergeRequestTrailer) {
+		return true
+	}
+	mrs, err := h.g.ListCommitMergeRequests(push.ProjectInfo.ID, push.After)
+	if err != nil {
+		return false
+	}
+	for _, mr := range mrs {
+		if mr.TargetBranch != branch {
+			continue
+		}
+		// the head of merge request is the head of branch after a fast-forward merge
+		if mr.MergeCommitSHA == push.After || mr.SquashCommitSHA == push.After || mr.SHA == push.After {
+			return true
+		}
+	}
+	return false
+}
+
+// matchBranch reports whether branch matches one of the comma separated patterns,
+// pattern syntax is the same as path.Match, e.g. `main,release/*`
+func matchBranch(patterns, branch string) bool {
+	for _, p := range strings.Split(patterns, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		matched, err := path.Match(p, branch)
+		if err != nil {
+			logrus.Errorf("invalid branch pattern %q: %v", p, err)
+			continue
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,256 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gitlack/model"
+	"gitlack/resource/gitlab"
+	"gitlack/resource/slack"
+
+	mGitLab "gitlack/resource/gitlab/mocks"
+	mSlack "gitlack/resource/slack/mocks"
+	mDB "gitlack/store/mocks"
+)
+
+const pushBodyTemplate = `{
+	"before": "{{.Before}}",
+	"after": "{{.After}}",
+	"ref": "refs/heads/{{.Branch}}",
+	"user_id": {{.UserID}},
+	"project": {
+		"id": {{.ProjectID}},
+		"web_url": "http://fake.com/{{.Path}}",
+		"path_with_namespace": "{{.Path}}"
+	},
+	"commits": [{{range $i, $c := .Commits}}{{if $i}},{{end}}
+		{
+			"id": "{{$c}}",
+			"title": "fake-title-{{$c}}",
+			"message": "fake-title-{{$c}}{{if $.Trailer}}\n\nSee merge request {{$.Path}}!1{{end}}",
+			"url": "http://fake.com/{{$.Path}}/commit/{{$c}}"
+		}{{end}}
+	],
+	"total_commits_count": {{.Total}}
+}`
+
+func getPushFakeData(count int) map[string]interface{} {
+	var commits []string
+	for i := 0; i < count; i++ {
+		commits = append(commits, fmt.Sprintf("%040d", i+1))
+	}
+	return map[string]interface{}{
+		"Before":    "1111111111111111111111111111111111111111",
+		"After":     "2222222222222222222222222222222222222222",
+		"Branch":    "main",
+		"UserID":    1,
+		"ProjectID": 999,
+		"Path":      "fake/fake-gitlab-project",
+		"Commits":   commits,
+		"Total":     count,
+	}
+}
+
+func genPushBody(data map[string]interface{}) []byte {
+	body := renderTemplate(pushBodyTemplate, data)
+	return body.Bytes()
+}
+
+// getStubCommitGitLab returns GitLab where the pushed commits belong to no merge request
+func getStubCommitGitLab() *mGitLab.GitLab {
+	stubGitLab := &mGitLab.GitLab{}
+	stubGitLab.On("ListCommitMergeRequests", mock.Anything, mock.Anything).Return([]*gitlab.MergeRequest{}, nil)
+	return stubGitLab
+}
+
+func TestPushEvent(t *testing.T) {
+	fakeData := getPushFakeData(2)
+	mockedProject := &model.Project{
+		ID:              fakeData["ProjectID"].(int),
+		DefaultChannel:  "fake-project-channel",
+		WatchedBranches: "main, release/*",
+	}
+	mockedAuthor := &model.User{
+		SlackID: "fake-author-slack-id",
+	}
+
+	mockedDB := &mDB.Store{}
+	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(mockedProject, nil)
+	mockedDB.On("GetUserByID", fakeData["UserID"].(int)).Return(mockedAuthor, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+
+	path := fakeData["Path"].(string)
+	slackExpected := fmt.Sprintf("<@fake-author-slack-id> has pushed 2 commit(s) to `main` of `%v` (<http://fake.com/%v/compare/%v...%v|compare>)\n", path, path, fakeData["Before"], fakeData["After"]) +
+		fmt.Sprintf("<http://fake.com/%v/commit/%040d|`00000000`> fake-title-%040d\n", path, 1, 1) +
+		fmt.Sprintf("<http://fake.com/%v/commit/%040d|`00000000`> fake-title-%040d\n", path, 2, 2)
+	var nilUser *model.User
+	var nilAtm *slack.Attachment
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("PostSlackMessage", mockedProject.DefaultChannel, slackExpected, nilUser, nilAtm).Return(nil, nil)
+
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+		g:  getStubCommitGitLab(),
+	}
+	w.PushEvent(genPushBody(fakeData))
+
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1)
+}
+
+func TestPushEventRoutedByRule(t *testing.T) {
+	fakeData := getPushFakeData(1)
+	mockedDB := &mDB.Store{}
+	mockedDB.On("GetProjectByID", 999).Return(&model.Project{ID: 999, DefaultChannel: "fake-project-channel", WatchedBranches: "main"}, nil)
+	mockedDB.On("GetUserByID", 1).Return(&model.User{SlackID: "fake-author-slack-id"}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{
+		{Event: RouteTagPush, Channels: "fake-tag-channel"},
+		{Event: RoutePush, TargetBranch: "main", Channels: "fake-push-channel,fake-copy-channel"},
+	}, nil)
+	mockedDB.On("ListTemplates", TemplatePush, mock.Anything).Return([]*model.Template{
+		{Path: "fake", Event: TemplatePush, Text: "{{.Count}} pushed to {{.Branch}}"},
+	}, nil)
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("PostSlackMessage", mock.Anything, "1 pushed to main", mock.Anything, mock.Anything).Return(&slack.MessageResponse{OK: true}, nil)
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+		g:  getStubCommitGitLab(),
+	}
+
+	err := w.PushEvent(genPushBody(fakeData))
+
+	assert.Nil(t, err)
+	mockedSlack.AssertCalled(t, "PostSlackMessage", "fake-push-channel", "1 pushed to main", mock.Anything, mock.Anything)
+	mockedSlack.AssertCalled(t, "PostSlackMessage", "fake-copy-channel", "1 pushed to main", mock.Anything, mock.Anything)
+}
+
+func TestPushEventBranchNotWatched(t *testing.T) {
+	fakeData := getPushFakeData(1)
+	fakeData["Branch"] = "feature/fake"
+	mockedProject := &model.Project{
+		ID:              fakeData["ProjectID"].(int),
+		WatchedBranches: "main,release/*",
+	}
+
+	mockedDB := &mDB.Store{}
+	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(mockedProject, nil)
+	mockedSlack := &mSlack.Slack{}
+
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+	w.PushEvent(genPushBody(fakeData))
+
+	mockedDB.AssertNotCalled(t, "GetUserByID", mock.Anything)
+	mockedSlack.AssertNotCalled(t, "PostSlackMessage")
+}
+
+func TestPushEventBranchDeleted(t *testing.T) {
+	fakeData := getPushFakeData(0)
+	fakeData["After"] = emptySHA
+
+	mockedDB := &mDB.Store{}
+	mockedSlack := &mSlack.Slack{}
+
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+	w.PushEvent(genPushBody(fakeData))
+
+	mockedDB.AssertNotCalled(t, "GetProjectByID", mock.Anything)
+	mockedSlack.AssertNotCalled(t, "PostSlackMessage")
+}
+
+func TestPushEventOfMergeRequestSkipped(t *testing.T) {
+	input := map[string][]*gitlab.MergeRequest{
+		"merge commit":      {{TargetBranch: "main", MergeCommitSHA: "2222222222222222222222222222222222222222"}},
+		"squash commit":     {{TargetBranch: "main", SquashCommitSHA: "2222222222222222222222222222222222222222"}},
+		"fast-forward":      {{TargetBranch: "main", SHA: "2222222222222222222222222222222222222222"}},
+		"trailer of GitLab": nil,
+	}
+	for name, mrs := range input {
+		fakeData := getPushFakeData(2)
+		fakeData["Trailer"] = mrs == nil
+		mockedDB := &mDB.Store{}
+		mockedDB.On("GetProjectByID", 999).Return(&model.Project{ID: 999, DefaultChannel: "fake-project-channel", WatchedBranches: "main"}, nil)
+		stubGitLab := &mGitLab.GitLab{}
+		stubGitLab.On("ListCommitMergeRequests", 999, "2222222222222222222222222222222222222222").Return(append(mrs,
+			&gitlab.MergeRequest{TargetBranch: "develop", MergeCommitSHA: "fake-other-sha"}), nil)
+		mockedSlack := &mSlack.Slack{}
+		w := &hook{
+			db: mockedDB,
+			s:  mockedSlack,
+			g:  stubGitLab,
+		}
+
+		err := w.PushEvent(genPushBody(fakeData))
+
+		assert.Nil(t, err, name)
+		mockedDB.AssertNotCalled(t, "GetUserByID", mock.Anything)
+		mockedSlack.AssertNotCalled(t, "PostSlackMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	}
+}
+
+func TestPushEventNewBranchWithManyCommits(t *testing.T) {
+	fakeData := getPushFakeData(maxPushCommits + 2)
+	fakeData["Before"] = emptySHA
+	fakeData["Branch"] = "release/1.0"
+	mockedProject := &model.Project{
+		ID:              fakeData["ProjectID"].(int),
+		WatchedBranches: "release/*",
+	}
+	mockedAuthor := &model.User{
+		Name: "fake-author-name",
+	}
+
+	mockedDB := &mDB.Store{}
+	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(mockedProject, nil)
+	mockedDB.On("GetUserByID", fakeData["UserID"].(int)).Return(mockedAuthor, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+
+	var actual string
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("PostSlackMessage", "general", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		actual = args.String(1)
+	}).Return(nil, nil)
+
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+		g:  getStubCommitGitLab(),
+	}
+	w.PushEvent(genPushBody(fakeData))
+
+	lines := strings.Split(strings.TrimSpace(actual), "\n")
+	assert.Contains(t, lines[0], "<@fake-author-name> has pushed 12 commit(s) to `release/1.0`", "Author and count should be shown")
+	assert.Contains(t, lines[0], "http://fake.com/fake/fake-gitlab-project/commits/release/1.0", "New branch should link to commits")
+	assert.Equal(t, maxPushCommits+2, len(lines), "Only the newest commits should be listed")
+	assert.Equal(t, "and 2 more commit(s)", lines[len(lines)-1])
+}
+
+func TestMatchBranch(t *testing.T) {
+	input := []struct {
+		patterns string
+		branch   string
+		expected bool
+	}{
+		{"main", "main", true},
+		{"main, release/*", "release/1.0", true},
+		{"release/*", "release/1.0/hotfix", false},
+		{"", "main", false},
+		{"master", "main", false},
+	}
+
+	for _, i := range input {
+		assert.Equal(t, i.expected, matchBranch(i.patterns, i.branch), fmt.Sprintf("%q should match %q: %v", i.patterns, i.branch, i.expected))
+	}
+}
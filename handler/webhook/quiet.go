@@ -0,0 +1,109 @@
+package webhook
+
+import (
+	"database/sql"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gitlack/model"
+
+	"github.com/sirupsen/logrus"
+)
+
+const digestHeader = "While you were in quiet hours:"
+
+// inQuietHours returns whether t is in the quiet hours of user,
+// the window is in the time zone of user, UTC by default, and may cross midnight
+func inQuietHours(u *model.User, t time.Time) bool {
+	start, err := time.Parse("15:04", u.QuietStart)
+	if err != nil {
+		return false
+	}
+	end, err := time.Parse("15:04", u.QuietEnd)
+	if err != nil || start.Equal(end) {
+		return false
+	}
+	loc, err := time.LoadLocation(u.TimeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	now := t.Hour()*60 + t.Minute()
+	from, to := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+	if from < to {
+		return from <= now && now < to
+	}
+	return now >= from || now < to
+}
+
+// queue saves the notification to user, which is sent in the digest after quiet hours
+func (h *hook) queue(u *model.User, text string) {
+	err := h.db.CreateQueuedNotification(&model.QueuedNotification{
+		GitLabID:  u.GitLabID,
+		Text:      text,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return
+	}
+	logrus.Infof("notification to %v queued during quiet hours", u.Email)
+}
+
+// DispatchDigests sends the notifications queued to users whose quiet hours are over, a digest by direct message for each
+func (h *hook) DispatchDigests() {
+	if !atomic.CompareAndSwapInt32(&h.digesting, 0, 1) {
+		logrus.Debugln("digests are being dispatched")
+		return
+	}
+	defer atomic.StoreInt32(&h.digesting, 0)
+
+	queued, err := h.db.ListQueuedNotifications()
+	if err != nil {
+		return
+	}
+	// notifications are ordered by user
+	for i := 0; i < len(queued); {
+		j := i
+		for j < len(queued) && queued[j].GitLabID == queued[i].GitLabID {
+			j++
+		}
+		h.sendDigest(queued[i:j])
+		i = j
+	}
+}
+
+// sendDigest sends the notifications of the same user in one direct message and removes them from queue,
+// they're kept if the quiet hours of user aren't over, or the user or the direct message can't be read
+func (h *hook) sendDigest(notifications []*model.QueuedNotification) {
+	var ids []int
+	texts := []string{digestHeader}
+	for _, n := range notifications {
+		ids = append(ids, n.ID)
+		texts = append(texts, n.Text)
+	}
+
+	u, err := h.db.GetUserByID(notifications[0].GitLabID)
+	// notifications to users who are gone are dropped
+	if err == sql.ErrNoRows {
+		h.db.DeleteQueuedNotifications(ids)
+		return
+	}
+	if err != nil {
+		return
+	}
+	if inQuietHours(u, time.Now()) {
+		return
+	}
+	dm, err := h.s.OpenConversation(u.SlackID)
+	if err != nil {
+		return
+	}
+	// the digest is retried by outbox if Slack fails
+	h.post(&message{
+		channel: dm,
+		text:    strings.Join(texts, "\n\n"),
+	})
+	logrus.Infof("digest of %v notifications sent to %v", len(notifications), u.Email)
+	h.db.DeleteQueuedNotifications(ids)
+}
@@ -0,0 +1,121 @@
+package webhook
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"gitlack/model"
+	"gitlack/resource/slack"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mSlack "gitlack/resource/slack/mocks"
+	mDB "gitlack/store/mocks"
+)
+
+func TestInQuietHours(t *testing.T) {
+	// 2026-10-17 23:30 in Taipei
+	now := time.Date(2026, 10, 17, 15, 30, 0, 0, time.UTC)
+	input := []struct {
+		user     *model.User
+		expected bool
+	}{
+		{&model.User{}, false},
+		{&model.User{QuietStart: "22:00", QuietEnd: "22:00"}, false},
+		{&model.User{QuietStart: "22:00", QuietEnd: "08:00"}, false},
+		{&model.User{QuietStart: "15:00", QuietEnd: "16:00"}, true},
+		{&model.User{QuietStart: "22:00", QuietEnd: "08:00", TimeZone: "Asia/Taipei"}, true},
+		{&model.User{QuietStart: "23:30", QuietEnd: "23:45", TimeZone: "Asia/Taipei"}, true},
+		{&model.User{QuietStart: "08:00", QuietEnd: "23:30", TimeZone: "Asia/Taipei"}, false},
+		{&model.User{QuietStart: "22:00", QuietEnd: "8am", TimeZone: "Asia/Taipei"}, false},
+	}
+	for _, i := range input {
+		assert.Equal(t, i.expected, inQuietHours(i.user, now), i.user)
+	}
+}
+
+// quietUser returns the user whose quiet hours are now
+func quietUser(id int, slackID string) *model.User {
+	now := time.Now().UTC()
+	return &model.User{
+		GitLabID:   id,
+		Email:      slackID,
+		SlackID:    slackID,
+		Delivery:   model.DeliveryBoth,
+		QuietStart: now.Add(-time.Hour).Format("15:04"),
+		QuietEnd:   now.Add(time.Hour).Format("15:04"),
+	}
+}
+
+func TestNotifyQueuesInQuietHours(t *testing.T) {
+	var nilUser *model.User
+	var nilAtm *slack.Attachment
+	mockedDB := &mDB.Store{}
+	mockedDB.On("CreateQueuedNotification", mock.MatchedBy(func(n *model.QueuedNotification) bool {
+		return n.GitLabID == 3 && n.Text == "fake-dm-text\n<https://fake.slack.com/p1|View the thread>" && !n.CreatedAt.IsZero()
+	})).Return(nil)
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("GetPermalink", "fake-channel", "fake-ts").Return("https://fake.slack.com/p1", nil)
+	mockedSlack.On("PostSlackMessage", "fake-channel", "<@fake-awake> fake-text", nilUser, nilAtm, "fake-ts").Return(&slack.MessageResponse{OK: true}, nil)
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+
+	w.notify(&notice{
+		event:    model.MentionReviewRequested,
+		path:     "fake/project",
+		channel:  "fake-channel",
+		threadTS: "fake-ts",
+		text:     "fake-text",
+		dmText:   func() string { return "fake-dm-text" },
+		users:    []*model.User{quietUser(3, "fake-asleep"), {GitLabID: 4, SlackID: "fake-awake"}},
+	})
+
+	mockedDB.AssertExpectations(t)
+	mockedSlack.AssertNotCalled(t, "OpenConversation", mock.Anything)
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1)
+}
+
+func TestMentionedInQuietHours(t *testing.T) {
+	u := quietUser(3, "fake-asleep")
+
+	assert.Equal(t, "", mentioned(u, model.MentionMRAssigned, "fake/project").SlackID)
+	assert.Equal(t, "fake-asleep", u.SlackID)
+}
+
+func TestDispatchDigests(t *testing.T) {
+	queued := []*model.QueuedNotification{
+		{ID: 1, GitLabID: 3, Text: "fake-first"},
+		{ID: 2, GitLabID: 3, Text: "fake-second"},
+		{ID: 3, GitLabID: 4, Text: "fake-asleep-text"},
+		{ID: 4, GitLabID: 5, Text: "fake-gone-text"},
+		{ID: 5, GitLabID: 6, Text: "fake-broken-text"},
+	}
+	var nilUser *model.User
+	var nilAtm *slack.Attachment
+	mockedDB := &mDB.Store{}
+	mockedDB.On("ListQueuedNotifications").Return(queued, nil)
+	mockedDB.On("GetUserByID", 3).Return(&model.User{GitLabID: 3, SlackID: "fake-awake"}, nil)
+	mockedDB.On("GetUserByID", 4).Return(quietUser(4, "fake-asleep"), nil)
+	mockedDB.On("GetUserByID", 5).Return(nil, sql.ErrNoRows)
+	mockedDB.On("GetUserByID", 6).Return(nil, errors.New("fake error"))
+	mockedDB.On("DeleteQueuedNotifications", []int{1, 2}).Return(nil)
+	mockedDB.On("DeleteQueuedNotifications", []int{4}).Return(nil)
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("OpenConversation", "fake-awake").Return("fake-dm", nil)
+	mockedSlack.On("PostSlackMessage", "fake-dm", digestHeader+"\n\nfake-first\n\nfake-second", nilUser, nilAtm).Return(&slack.MessageResponse{OK: true}, nil)
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+
+	w.DispatchDigests()
+
+	mockedDB.AssertExpectations(t)
+	mockedSlack.AssertExpectations(t)
+	mockedDB.AssertNumberOfCalls(t, "DeleteQueuedNotifications", 2)
+}
@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// lifecycle events of merge request and issue which are reacted on the root message
+const (
+	reactMerge           = "merge"
+	reactClose           = "close"
+	reactPipelineFailed  = "pipeline_failed"
+	reactPipelineSuccess = "pipeline_success"
+	reactIssueClose      = "issue_close"
+)
+
+// defaultReactions maps the lifecycle events to emoji, overridden by the reactions of project
+var defaultReactions = map[string]string{
+	reactMerge:           "white_check_mark",
+	reactClose:           "no_entry",
+	reactPipelineFailed:  "x",
+	reactPipelineSuccess: "",
+	reactIssueClose:      "heavy_check_mark",
+}
+
+// ParseReactions parses the comma separated `event:emoji` pairs, e.g. `merge:tada,close:`,
+// an empty emoji turns off the reaction of event
+func ParseReactions(s string) (map[string]string, error) {
+	reactions := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, ":", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid reaction: %q", pair)
+		}
+		event := strings.TrimSpace(kv[0])
+		if _, ok := defaultReactions[event]; !ok {
+			return nil, fmt.Errorf("unknown event: %q", event)
+		}
+		reactions[event] = strings.Trim(strings.TrimSpace(kv[1]), ":")
+	}
+	return reactions, nil
+}
+
+// reactions returns the emoji of events for project
+func (h *hook) reactions(projectID int) map[string]string {
+	reactions := map[string]string{}
+	for k, v := range defaultReactions {
+		reactions[k] = v
+	}
+	p, err := h.db.GetProjectByID(projectID)
+	if err != nil || p.Reactions == "" {
+		return reactions
+	}
+	custom, err := ParseReactions(p.Reactions)
+	if err != nil {
+		logrus.Warnf("reactions of project %v ignored: %v", p.Name, err)
+		return reactions
+	}
+	for k, v := range custom {
+		reactions[k] = v
+	}
+	return reactions
+}
+
+// react adds the emoji of event to message ts and removes the emoji of events which are outdated
+func (h *hook) react(projectID int, channel, ts, event string, outdated ...string) {
+	reactions := h.reactions(projectID)
+	for _, o := range outdated {
+		if emoji := reactions[o]; emoji != "" && emoji != reactions[event] {
+			h.s.RemoveReaction(channel, ts, emoji)
+		}
+	}
+	if emoji := reactions[event]; emoji != "" {
+		h.s.AddReaction(channel, ts, emoji)
+	}
+}
+
+// unreact removes the emoji of events from message ts
+func (h *hook) unreact(projectID int, channel, ts string, events ...string) {
+	reactions := h.reactions(projectID)
+	for _, e := range events {
+		if emoji := reactions[e]; emoji != "" {
+			h.s.RemoveReaction(channel, ts, emoji)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"testing"
+
+	"gitlack/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mSlack "gitlack/resource/slack/mocks"
+	mDB "gitlack/store/mocks"
+)
+
+func TestParseReactions(t *testing.T) {
+	reactions, err := ParseReactions(" merge: :tada: ,close:,pipeline_success:green_heart,")
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{
+		"merge":            "tada",
+		"close":            "",
+		"pipeline_success": "green_heart",
+	}, reactions)
+}
+
+func TestParseReactionsInvalid(t *testing.T) {
+	input := []string{
+		"merge",
+		"fake-event:tada",
+	}
+	for _, in := range input {
+		_, err := ParseReactions(in)
+		assert.NotNil(t, err, in)
+	}
+}
+
+func TestReactWithProjectReactions(t *testing.T) {
+	mockedDB := &mDB.Store{}
+	mockedDB.On("GetProjectByID", 999).Return(&model.Project{Reactions: "merge:tada,close:"}, nil)
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("AddReaction", "fake-channel", "fake-ts", "tada").Return(nil)
+	mockedSlack.On("RemoveReaction", "fake-channel", "fake-ts", mock.Anything).Return(nil)
+
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+	w.react(999, "fake-channel", "fake-ts", reactMerge)
+	w.react(999, "fake-channel", "fake-ts", reactClose)
+	w.unreact(999, "fake-channel", "fake-ts", reactMerge, reactClose, reactPipelineFailed)
+
+	mockedSlack.AssertNumberOfCalls(t, "AddReaction", 1)
+	mockedSlack.AssertCalled(t, "RemoveReaction", "fake-channel", "fake-ts", "tada")
+	mockedSlack.AssertCalled(t, "RemoveReaction", "fake-channel", "fake-ts", "x")
+	mockedSlack.AssertNumberOfCalls(t, "RemoveReaction", 2)
+}
+
+func TestReactWithInvalidProjectReactions(t *testing.T) {
+	mockedDB := &mDB.Store{}
+	mockedDB.On("GetProjectByID", 999).Return(&model.Project{Reactions: "fake"}, nil)
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("AddReaction", "fake-channel", "fake-ts", "white_check_mark").Return(nil)
+
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+	w.react(999, "fake-channel", "fake-ts", reactMerge)
+
+	mockedSlack.AssertNumberOfCalls(t, "AddReaction", 1)
+}
@@ -0,0 +1,153 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gitlack/model"
+	"gitlack/resource/slack"
+
+	"github.com/sirupsen/logrus"
+)
+
+const releaseTemplate = "{{if .Author}}<@{{.Author}}> has published{{else}}New release{{end}} <{{.Link}}|{{.Name}}> of `{{.Path}}` with tag <{{.TagLink}}|{{.Tag}}>!\n"
+
+// ReleaseEvent represents the data structure of release in GitLab webhook request
+type ReleaseEvent struct {
+	Action      string        `json:"action"`
+	Name        string        `json:"name"`
+	Tag         string        `json:"tag"`
+	Description string        `json:"description"`
+	URL         string        `json:"url"`
+	ProjectInfo Project       `json:"project"`
+	Assets      ReleaseAssets `json:"assets"`
+	Milestones  []Milestone   `json:"milestones"`
+	CommitInfo  ReleaseCommit `json:"commit"`
+}
+
+// ReleaseAssets represents the data structure of `assets` in GitLab release webhook request
+type ReleaseAssets struct {
+	Links []ReleaseLink `json:"links"`
+}
+
+// ReleaseLink represents the data structure of an asset link of release
+type ReleaseLink struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// Milestone represents the data structure of milestone associated with release
+type Milestone struct {
+	Title  string `json:"title"`
+	WebURL string `json:"web_url"`
+}
+
+// ReleaseCommit represents the data structure of `commit` in GitLab release webhook request
+type ReleaseCommit struct {
+	Author struct {
+		Email string `json:"email"`
+	} `json:"author"`
+}
+
+func (h *hook) ReleaseEvent(b []byte) error {
+	var release ReleaseEvent
+	err := json.Unmarshal(b, &release)
+	if err != nil {
+		logrus.Errorln(err)
+		return err
+	}
+
+	// only announce new releases, updates of release notes are ignored
+	if release.Action != "create" {
+		logrus.Infof("release action not supported: %v", release.Action)
+		return nil
+	}
+
+	// release webhook has no user, use the author of tagged commit instead
+	var author *model.User
+	if email := strings.Split(release.CommitInfo.Author.Email, "@")[0]; email != "" {
+		author, err = h.db.GetUserByEmail(email)
+		if err != nil {
+			author = nil
+		}
+	}
+
+	route, err := h.route(&RouteTarget{
+		Event:       RouteRelease,
+		ProjectID:   release.ProjectInfo.ID,
+		Path:        release.ProjectInfo.PathWithNamespace,
+		Description: release.Description,
+		Author:      author,
+		Owner:       author,
+	})
+	if err != nil {
+		return err
+	}
+
+	// if user doesn't exist in Slack, use the name of user in GitLab instead
+	var authorID string
+	if author != nil {
+		authorID = author.SlackID
+		if author.SlackID == "" {
+			authorID = author.Name
+		}
+	}
+
+	name := release.Name
+	if name == "" {
+		name = release.Tag
+	}
+
+	// prepare slack text
+	data := map[string]interface{}{
+		"Author":  authorID,
+		"Name":    name,
+		"Link":    release.URL,
+		"Path":    release.ProjectInfo.PathWithNamespace,
+		"Tag":     release.Tag,
+		"TagLink": fmt.Sprintf("%v/tags/%v", release.ProjectInfo.WebURL, release.Tag),
+	}
+	slackText, _, err := h.render(TemplateRelease, release.ProjectInfo.PathWithNamespace, data)
+	if err != nil {
+		return err
+	}
+
+	attachment := &slack.Attachment{
+		Color: slack.AttachmentColor,
+		Title: name,
+		Text:  releaseNote(release),
+	}
+	m := &message{
+		channel:    route.Channels[0],
+		copies:     route.Channels[1:],
+		text:       slackText,
+		attachment: attachment,
+		projectID:  release.ProjectInfo.ID,
+	}
+	h.post(m)
+	h.postCopies(m)
+	return nil
+}
+
+// releaseNote renders the description, asset links and milestones of release
+func releaseNote(release ReleaseEvent) string {
+	note := markdownToSlack(strings.TrimSpace(release.Description))
+
+	if len(release.Assets.Links) != 0 {
+		note += "\n\n*Assets*"
+		for _, l := range release.Assets.Links {
+			note += fmt.Sprintf("\n• <%v|%v>", l.URL, l.Name)
+		}
+	}
+
+	if len(release.Milestones) != 0 {
+		var milestones []string
+		for _, m := range release.Milestones {
+			milestones = append(milestones, fmt.Sprintf("<%v|%v>", m.WebURL, m.Title))
+		}
+		note += "\n\n*Milestone*: " + strings.Join(milestones, ", ")
+	}
+
+	return strings.TrimSpace(note)
+}
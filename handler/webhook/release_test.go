@@ -0,0 +1,138 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"gitlack/model"
+	"gitlack/resource/slack"
+
+	mSlack "gitlack/resource/slack/mocks"
+	mDB "gitlack/store/mocks"
+)
+
+const releaseBodyTemplate = `{
+	"action": "{{.Action}}",
+	"name": "{{.Name}}",
+	"tag": "{{.Tag}}",
+	"description": "{{.Desc}}",
+	"url": "http://fake.com/{{.Path}}/-/releases/{{.Tag}}",
+	"project": {
+		"id": {{.ProjectID}},
+		"web_url": "http://fake.com/{{.Path}}",
+		"path_with_namespace": "{{.Path}}"
+	},
+	"assets": {
+		"links": [
+			{"name": "fake-asset", "url": "http://fake.com/fake-asset.tar.gz"}
+		]
+	},
+	"milestones": [
+		{"title": "fake-milestone", "web_url": "http://fake.com/{{.Path}}/-/milestones/1"}
+	],
+	"commit": {
+		"author": {"email": "{{.AuthorEmail}}"}
+	}
+}`
+
+func getReleaseFakeData() map[string]interface{} {
+	return map[string]interface{}{
+		"Action":      "create",
+		"Name":        "fake-release",
+		"Tag":         "v1.0.0",
+		"Desc":        "## Features\\n- **fake** feature, see [docs](http://fake.com/docs)",
+		"ProjectID":   999,
+		"Path":        "fake/fake-gitlab-project",
+		"AuthorEmail": "fake-author@fake.com",
+	}
+}
+
+func genReleaseBody(data map[string]interface{}) []byte {
+	body := renderTemplate(releaseBodyTemplate, data)
+	return body.Bytes()
+}
+
+func TestReleaseEvent(t *testing.T) {
+	fakeData := getReleaseFakeData()
+	mockedAuthor := &model.User{
+		SlackID: "fake-author-slack-id",
+	}
+	mockedProject := &model.Project{
+		DefaultChannel: "fake-project-channel",
+	}
+
+	mockedDB := &mDB.Store{}
+	mockedDB.On("GetUserByEmail", "fake-author").Return(mockedAuthor, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
+	mockedDB.On("ListTemplates", TemplateRelease, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(mockedProject, nil)
+
+	path := fakeData["Path"].(string)
+	slackExpected := fmt.Sprintf("<@fake-author-slack-id> has published <http://fake.com/%v/-/releases/v1.0.0|fake-release> of `%v` with tag <http://fake.com/%v/tags/v1.0.0|v1.0.0>!\n", path, path, path)
+	atmExpected := &slack.Attachment{
+		Color: slack.AttachmentColor,
+		Title: "fake-release",
+		Text: "*Features*\n• *fake* feature, see <http://fake.com/docs|docs>\n\n" +
+			"*Assets*\n• <http://fake.com/fake-asset.tar.gz|fake-asset>\n\n" +
+			fmt.Sprintf("*Milestone*: <http://fake.com/%v/-/milestones/1|fake-milestone>", path),
+	}
+	var nilUser *model.User
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("PostSlackMessage", mockedProject.DefaultChannel, slackExpected, nilUser, atmExpected).Return(nil, nil)
+
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+	w.ReleaseEvent(genReleaseBody(fakeData))
+
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1)
+}
+
+func TestReleaseEventUnknownAuthor(t *testing.T) {
+	fakeData := getReleaseFakeData()
+	fakeData["Desc"] = "fake-description\\n/gitlack: fake-channel"
+	fakeData["Name"] = ""
+
+	var nilUser *model.User
+	mockedDB := &mDB.Store{}
+	mockedDB.On("GetUserByEmail", "fake-author").Return(nilUser, errors.New("sql: no rows in result set"))
+	mockedDB.On("ListTemplates", TemplateRelease, mock.Anything).Return([]*model.Template{}, nil)
+
+	var actual string
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("PostSlackMessage", "fake-channel", mock.Anything, nilUser, mock.Anything).Run(func(args mock.Arguments) {
+		actual = args.String(1)
+	}).Return(nil, nil)
+
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+	w.ReleaseEvent(genReleaseBody(fakeData))
+
+	mockedDB.AssertNotCalled(t, "GetProjectByID", mock.Anything)
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1)
+	assert.Contains(t, actual, "New release <http://fake.com/fake/fake-gitlab-project/-/releases/v1.0.0|v1.0.0>", "Tag should be used without release name")
+}
+
+func TestReleaseEventUpdate(t *testing.T) {
+	fakeData := getReleaseFakeData()
+	fakeData["Action"] = "update"
+
+	mockedDB := &mDB.Store{}
+	mockedSlack := &mSlack.Slack{}
+
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+	w.ReleaseEvent(genReleaseBody(fakeData))
+
+	mockedDB.AssertNotCalled(t, "GetUserByEmail", mock.Anything)
+	mockedSlack.AssertNotCalled(t, "PostSlackMessage")
+}
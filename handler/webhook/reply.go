@@ -0,0 +1,107 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gitlack/model"
+	"gitlack/resource/gitlab"
+
+	"github.com/sirupsen/logrus"
+)
+
+// slackNoteFooter tells the readers in GitLab that the note is created from Slack
+const slackNoteFooter = "\n\n*Sent from Slack*"
+
+// slackNoteMarker is hidden in the rendered note, the comment event of note carries it to fromSlack
+const slackNoteMarker = "\n<!-- gitlack:%v -->"
+
+var slackNoteMarkerRegexp = regexp.MustCompile(`<!-- gitlack:([0-9a-f]{32}) -->`)
+
+// Reply is a message replied by the Slack user in a thread
+type Reply struct {
+	Channel  string
+	ThreadTS string
+	SlackID  string
+	Text     string
+}
+
+// SlackReply comments the reply on the merge request or issue owning the thread in GitLab,
+// as the user linked to the Slack user, replies in other threads are ignored
+func (h *hook) SlackReply(r *Reply) {
+	t, err := h.db.GetThreadByTS(r.Channel, r.ThreadTS)
+	if err != nil {
+		logrus.Debugf("thread %v of %v isn't owned by Gitlack", r.ThreadTS, r.Channel)
+		return
+	}
+	u, err := h.db.GetUserBySlackID(r.SlackID)
+	if err != nil {
+		logrus.Infof("reply of unlinked Slack user %v isn't synchronized to GitLab", r.SlackID)
+		return
+	}
+	text := strings.TrimSpace(slackToMarkdown(r.Text, h.mentionedName))
+	if text == "" {
+		return
+	}
+
+	// the marker is recorded before the note is created, since the comment event may come before the API responds
+	marker, err := newSlackNoteMarker()
+	if err != nil {
+		logrus.Errorln(err)
+		return
+	}
+	err = h.db.CreateSlackNote(&model.SlackNote{Marker: marker, ProjectID: t.ProjectID, AuthorID: u.GitLabID, CreatedAt: time.Now()})
+	if err != nil {
+		logrus.Warnf("reply of %v isn't synchronized to GitLab, it would be posted back to the thread", u.Email)
+		return
+	}
+
+	body := text + slackNoteFooter + fmt.Sprintf(slackNoteMarker, marker)
+	if t.ObjectKind == kindIssue {
+		err = h.g.CreateIssueNote(t.ProjectID, t.ObjectNum, u.GitLabID, body)
+	} else {
+		err = h.g.CreateMergeRequestNote(t.ProjectID, t.ObjectNum, u.GitLabID, body)
+	}
+	if err == gitlab.ErrForbidden {
+		logrus.Infof("%v isn't allowed to comment on %v %v#%v", u.Email, t.ObjectKind, t.ProjectID, t.ObjectNum)
+		return
+	}
+	if err != nil {
+		return
+	}
+	logrus.Infof("reply of %v synchronized to %v %v#%v", u.Email, t.ObjectKind, t.ProjectID, t.ObjectNum)
+}
+
+// mentionedName shows the Slack user mentioned in reply by the name in GitLab
+func (h *hook) mentionedName(slackID string) string {
+	u, err := h.db.GetUserBySlackID(slackID)
+	if err != nil {
+		return slackID
+	}
+	return u.Name
+}
+
+// newSlackNoteMarker returns a random marker for the note created from Slack
+func newSlackNoteMarker() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// fromSlack reports whether the note is created by SlackReply, which records the marker in note before creating it,
+// the marker counts only for the author it is recorded for, since any GitLab user can copy the text of note
+func (h *hook) fromSlack(note string, authorID int) bool {
+	m := slackNoteMarkerRegexp.FindStringSubmatch(note)
+	if m == nil {
+		return false
+	}
+	n, err := h.db.GetSlackNote(m[1])
+	return err == nil && n.AuthorID == authorID
+}
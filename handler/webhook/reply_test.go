@@ -0,0 +1,123 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+
+	"gitlack/model"
+
+	"github.com/stretchr/testify/mock"
+
+	mGitLab "gitlack/resource/gitlab/mocks"
+	mSlack "gitlack/resource/slack/mocks"
+	mDB "gitlack/store/mocks"
+)
+
+func TestSlackReply(t *testing.T) {
+	input := map[string]string{
+		kindMergeRequest: "CreateMergeRequestNote",
+		kindIssue:        "CreateIssueNote",
+	}
+	for kind, method := range input {
+		// arrange
+		mockedDB := &mDB.Store{}
+		mockedDB.On("GetThreadByTS", "fake-channel", "fake-ts").Return(&model.Thread{ProjectID: 1, ObjectKind: kind, ObjectNum: 2}, nil)
+		mockedDB.On("GetUserBySlackID", "fake-slack-id").Return(&model.User{GitLabID: 3}, nil)
+		mockedDB.On("GetUserBySlackID", "U0123456789").Return(&model.User{Name: "fake-other"}, nil)
+		var marker string
+		mockedDB.On("CreateSlackNote", mock.MatchedBy(func(n *model.SlackNote) bool {
+			marker = n.Marker
+			return len(n.Marker) == 32 && n.ProjectID == 1 && n.AuthorID == 3
+		})).Return(nil)
+		mockedGitLab := &mGitLab.GitLab{}
+		// the marker is recorded before the note is created
+		mockedGitLab.On(method, 1, 2, 3, mock.MatchedBy(func(body string) bool {
+			return marker != "" && body == "**LGTM**, fake-other"+slackNoteFooter+"\n<!-- gitlack:"+marker+" -->"
+		})).Return(nil)
+		w := &hook{
+			db: mockedDB,
+			g:  mockedGitLab,
+		}
+
+		// act
+		w.SlackReply(&Reply{Channel: "fake-channel", ThreadTS: "fake-ts", SlackID: "fake-slack-id", Text: "*LGTM*, <@U0123456789>"})
+
+		// assert
+		mockedGitLab.AssertExpectations(t)
+		mockedDB.AssertExpectations(t)
+	}
+}
+
+func TestSlackReplyIgnored(t *testing.T) {
+	// arrange
+	mockedDB := &mDB.Store{}
+	mockedDB.On("GetThreadByTS", "fake-channel", "not-owned-ts").Return(nil, errors.New("sql: no rows in result set"))
+	mockedDB.On("GetThreadByTS", "fake-channel", "fake-ts").Return(&model.Thread{ProjectID: 1, ObjectKind: kindMergeRequest, ObjectNum: 2}, nil)
+	mockedDB.On("GetUserBySlackID", "fake-unlinked-id").Return(nil, errors.New("sql: no rows in result set"))
+	mockedGitLab := &mGitLab.GitLab{}
+	w := &hook{
+		db: mockedDB,
+		g:  mockedGitLab,
+	}
+
+	// act
+	w.SlackReply(&Reply{Channel: "fake-channel", ThreadTS: "not-owned-ts", SlackID: "fake-unlinked-id", Text: "fake-text"})
+	w.SlackReply(&Reply{Channel: "fake-channel", ThreadTS: "fake-ts", SlackID: "fake-unlinked-id", Text: "fake-text"})
+
+	// assert
+	mockedGitLab.AssertNotCalled(t, "CreateMergeRequestNote", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCommentFromSlackNotPosted(t *testing.T) {
+	// arrange
+	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", 999, 1, mock.Anything).Return(nil)
+	mockedDB.On("GetSlackNote", "0123456789abcdef0123456789abcdef").Return(&model.SlackNote{ProjectID: 999, AuthorID: 2}, nil)
+	mockedSlack := &mSlack.Slack{}
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+
+	// act
+	w.CommentsEvent([]byte(`{"object_attributes": {"author_id": 2, "noteable_type": "MergeRequest",
+		"note": "fake-text\n\n*Sent from Slack*\n<!-- gitlack:0123456789abcdef0123456789abcdef -->"},
+		"project": {"id": 999}, "merge_request": {"iid": 1}}`))
+
+	// assert
+	mockedDB.AssertNotCalled(t, "GetUserByID", mock.Anything)
+	mockedSlack.AssertNotCalled(t, "PostSlackBlocks", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCommentWithFooterFromGitLabPosted(t *testing.T) {
+	// arrange
+	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", 999, 1, mock.Anything).Return(nil)
+	mockedDB.On("GetUserByID", 2).Return(nil, errors.New("sql: no rows in result set"))
+	w := &hook{db: mockedDB}
+
+	// act
+	w.CommentsEvent([]byte(`{"object_attributes": {"author_id": 2, "noteable_type": "MergeRequest", "note": "fake-text\n\n*Sent from Slack*"},
+		"project": {"id": 999}, "merge_request": {"iid": 1}}`))
+
+	// assert
+	mockedDB.AssertNotCalled(t, "GetSlackNote", mock.Anything)
+	mockedDB.AssertCalled(t, "GetUserByID", 2)
+}
+
+func TestCommentWithCopiedMarkerPosted(t *testing.T) {
+	// arrange
+	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", 999, 1, mock.Anything).Return(nil)
+	mockedDB.On("GetSlackNote", "0123456789abcdef0123456789abcdef").Return(&model.SlackNote{ProjectID: 999, AuthorID: 3}, nil)
+	mockedDB.On("GetUserByID", 2).Return(nil, errors.New("sql: no rows in result set"))
+	w := &hook{db: mockedDB}
+
+	// act
+	w.CommentsEvent([]byte(`{"object_attributes": {"author_id": 2, "noteable_type": "MergeRequest",
+		"note": "fake-text\n\n*Sent from Slack*\n<!-- gitlack:0123456789abcdef0123456789abcdef -->"},
+		"project": {"id": 999}, "merge_request": {"iid": 1}}`))
+
+	// assert
+	mockedDB.AssertCalled(t, "GetUserByID", 2)
+}
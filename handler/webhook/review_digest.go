@@ -0,0 +1,167 @@
+package webhook
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gitlack/model"
+	"gitlack/resource/gitlab"
+	"gitlack/resource/slack"
+
+	"github.com/robfig/cron"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	reviewDigestHeader = "*Merge requests awaiting review*"
+	noReviewer         = "No reviewer"
+)
+
+// DispatchReviewDigests posts the merge requests awaiting review to the channels whose schedule is due
+func (h *hook) DispatchReviewDigests() {
+	if !atomic.CompareAndSwapInt32(&h.reviewing, 0, 1) {
+		logrus.Debugln("review digests are being dispatched")
+		return
+	}
+	defer atomic.StoreInt32(&h.reviewing, 0)
+
+	digests, err := h.db.ListReviewDigests()
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	for _, d := range digests {
+		schedule, err := cron.ParseStandard(d.Schedule)
+		if err != nil {
+			logrus.Errorf("invalid schedule of review digest in %v: %v", d.Channel, err)
+			continue
+		}
+		if schedule.Next(d.LastRunAt).After(now) {
+			continue
+		}
+		// the run is recorded first so that a failing channel isn't retried until the next schedule
+		if err := h.db.UpdateReviewDigestLastRun(d.Channel, now); err != nil {
+			continue
+		}
+		h.postReviewDigest(d.Channel, now)
+	}
+}
+
+// postReviewDigest posts the open merge requests which have a thread in channel grouped by reviewer,
+// the assignees are taken if there is no reviewer, nothing is posted if there is no open merge request
+func (h *hook) postReviewDigest(channel string, now time.Time) {
+	// threads are recorded by channel ID, the digests saved by channel name before are matched by its ID
+	if !slack.IsChannelID(channel) {
+		id, err := h.s.GetChannelID(channel)
+		if err != nil {
+			logrus.Errorf("channel of review digest %v isn't found: %v", channel, err)
+			return
+		}
+		channel = id
+	}
+	mrs, err := h.db.ListOpenMergeRequests(channel)
+	if err != nil {
+		return
+	}
+
+	groups := map[string][]*gitlab.MergeRequest{}
+	for _, mr := range mrs {
+		current, ok := h.currentMR(mr)
+		if !ok {
+			continue
+		}
+		// the pipeline status of webhook is used if GitLab doesn't tell
+		if current.HeadPipeline == nil && mr.PipelineStatus != "" {
+			current.HeadPipeline = &gitlab.Pipeline{Status: mr.PipelineStatus}
+		}
+		reviewers := current.Reviewers
+		if len(reviewers) == 0 {
+			reviewers = current.Assignees
+		}
+		if len(reviewers) == 0 {
+			groups[noReviewer] = append(groups[noReviewer], current)
+		}
+		for _, r := range reviewers {
+			groups[r.Name] = append(groups[r.Name], current)
+		}
+	}
+	if len(groups) == 0 {
+		logrus.Infof("no merge request awaiting review in %v", channel)
+		return
+	}
+
+	h.post(&message{
+		channel: channel,
+		text:    reviewDigestText(groups, now),
+	})
+	logrus.Infof("review digest posted to %v", channel)
+}
+
+// currentMR returns the merge request in GitLab if it's still open, the state of merge request closed
+// without webhook is updated, so are the ones recorded before the state and deleted from GitLab since,
+// the merge request is left out if GitLab can't be checked
+func (h *hook) currentMR(mr *model.MergeRequest) (*gitlab.MergeRequest, bool) {
+	current, err := h.g.GetMergeRequest(mr.ProjectID, mr.MergeRequestNum)
+	if err == gitlab.ErrNotFound {
+		mr.State = mrClosed
+		h.updateRootMR(mr)
+		return nil, false
+	}
+	if err != nil {
+		return nil, false
+	}
+	if current.State == mrOpened {
+		return current, true
+	}
+	if current.State == mrMerged || current.State == mrClosed {
+		mr.State = current.State
+		h.updateRootMR(mr)
+	}
+	return nil, false
+}
+
+// reviewDigestText lists merge requests under each reviewer by name, the oldest first,
+// reviewers are named without mention to not ping them on schedule
+func reviewDigestText(groups map[string][]*gitlab.MergeRequest, now time.Time) string {
+	var names []string
+	for name := range groups {
+		if name != noReviewer {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if _, ok := groups[noReviewer]; ok {
+		names = append(names, noReviewer)
+	}
+
+	lines := []string{reviewDigestHeader}
+	for _, name := range names {
+		mrs := groups[name]
+		sort.SliceStable(mrs, func(i, j int) bool {
+			return mrs[i].CreatedAt.Before(mrs[j].CreatedAt)
+		})
+		lines = append(lines, "", fmt.Sprintf("*%v* (%v)", name, len(mrs)))
+		for _, mr := range mrs {
+			status := "no pipeline"
+			if mr.HeadPipeline != nil {
+				status = "pipeline " + mr.HeadPipeline.Status
+			}
+			lines = append(lines, fmt.Sprintf("• <%v|!%v %v> · %v · %v", mr.WebURL, mr.IID, mr.Title, age(now.Sub(mr.CreatedAt)), status))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// age returns the duration in the largest unit of days, hours and minutes
+func age(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dm", int(d.Minutes()))
+}
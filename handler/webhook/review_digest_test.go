@@ -0,0 +1,124 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"gitlack/model"
+	"gitlack/resource/gitlab"
+	"gitlack/resource/slack"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mGitLab "gitlack/resource/gitlab/mocks"
+	mSlack "gitlack/resource/slack/mocks"
+	mDB "gitlack/store/mocks"
+)
+
+func TestDispatchReviewDigests(t *testing.T) {
+	digests := []*model.ReviewDigest{
+		{Channel: "C0000000001", Schedule: "@daily", LastRunAt: time.Now().Add(-25 * time.Hour)},
+		{Channel: "C0000000002", Schedule: "@daily", LastRunAt: time.Now()},
+		{Channel: "C0000000003", Schedule: "someday", LastRunAt: time.Now().Add(-25 * time.Hour)},
+	}
+	mockedDB := &mDB.Store{}
+	mockedDB.On("ListReviewDigests").Return(digests, nil)
+	mockedDB.On("UpdateReviewDigestLastRun", "C0000000001", mock.Anything).Return(nil)
+	mockedDB.On("ListOpenMergeRequests", "C0000000001").Return([]*model.MergeRequest{}, nil)
+	w := &hook{db: mockedDB}
+
+	w.DispatchReviewDigests()
+
+	mockedDB.AssertExpectations(t)
+	mockedDB.AssertNumberOfCalls(t, "UpdateReviewDigestLastRun", 1)
+}
+
+func TestPostReviewDigest(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	mrs := []*model.MergeRequest{
+		{ProjectID: 1, MergeRequestNum: 1, PipelineStatus: "success"},
+		{ProjectID: 1, MergeRequestNum: 2},
+		{ProjectID: 2, MergeRequestNum: 3},
+		{ProjectID: 2, MergeRequestNum: 4, Text: "fake-text"},
+	}
+	alice, bob := &gitlab.GitLabUser{ID: 3, Name: "Alice"}, &gitlab.GitLabUser{ID: 4, Name: "Bob"}
+	stubGitLab := &mGitLab.GitLab{}
+	stubGitLab.On("GetMergeRequest", 1, 1).Return(&gitlab.MergeRequest{IID: 1, Title: "fake-first", State: "opened", WebURL: "http://fake/1",
+		CreatedAt: now.Add(-50 * time.Hour), Reviewers: []*gitlab.GitLabUser{bob, alice}}, nil)
+	stubGitLab.On("GetMergeRequest", 1, 2).Return(&gitlab.MergeRequest{IID: 2, Title: "fake-second", State: "opened", WebURL: "http://fake/2",
+		CreatedAt: now.Add(-3 * time.Hour), Assignees: []*gitlab.GitLabUser{bob}, HeadPipeline: &gitlab.Pipeline{Status: "failed"}}, nil)
+	stubGitLab.On("GetMergeRequest", 2, 3).Return(&gitlab.MergeRequest{IID: 3, Title: "fake-third", State: "opened", WebURL: "http://fake/3",
+		CreatedAt: now.Add(-10 * time.Minute)}, nil)
+	stubGitLab.On("GetMergeRequest", 2, 4).Return(&gitlab.MergeRequest{IID: 4, State: "merged"}, nil)
+
+	var nilUser *model.User
+	var nilAtm *slack.Attachment
+	expected := reviewDigestHeader + "\n\n" +
+		"*Alice* (1)\n" +
+		"• <http://fake/1|!1 fake-first> · 2d · pipeline success\n\n" +
+		"*Bob* (2)\n" +
+		"• <http://fake/1|!1 fake-first> · 2d · pipeline success\n" +
+		"• <http://fake/2|!2 fake-second> · 3h · pipeline failed\n\n" +
+		"*No reviewer* (1)\n" +
+		"• <http://fake/3|!3 fake-third> · 10m · no pipeline"
+	mockedDB := &mDB.Store{}
+	mockedDB.On("ListOpenMergeRequests", "C0123456789").Return(mrs, nil)
+	mockedDB.On("UpdateMergeRequest", mrs[3]).Return(nil)
+	mockedDB.On("ListThreads", mock.Anything, mock.Anything, mock.Anything).Return([]*model.Thread{}, nil)
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("PostSlackMessage", "C0123456789", expected, nilUser, nilAtm).Return(&slack.MessageResponse{OK: true}, nil)
+	mockedSlack.On("UpdateSlackMessage", mock.Anything, mock.Anything, "fake-text", mock.Anything, mock.Anything).Return(nil, nil)
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+		g:  stubGitLab,
+	}
+
+	w.postReviewDigest("C0123456789", now)
+
+	mockedSlack.AssertExpectations(t)
+	// merge request merged without webhook is closed in store
+	assert.Equal(t, mrMerged, mrs[3].State)
+}
+
+func TestPostReviewDigestNothingOpen(t *testing.T) {
+	stubGitLab := &mGitLab.GitLab{}
+	stubGitLab.On("GetMergeRequest", 1, 1).Return(&gitlab.MergeRequest{State: "locked"}, nil)
+	mockedDB := &mDB.Store{}
+	mockedDB.On("ListOpenMergeRequests", "C0123456789").Return([]*model.MergeRequest{{ProjectID: 1, MergeRequestNum: 1}}, nil)
+	mockedSlack := &mSlack.Slack{}
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+		g:  stubGitLab,
+	}
+
+	w.postReviewDigest("C0123456789", time.Now())
+
+	mockedSlack.AssertNotCalled(t, "PostSlackMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockedDB.AssertNotCalled(t, "UpdateMergeRequest", mock.Anything)
+}
+
+func TestPostReviewDigestByChannelName(t *testing.T) {
+	mr := &model.MergeRequest{ProjectID: 1, MergeRequestNum: 1, State: mrOpened}
+	stubGitLab := &mGitLab.GitLab{}
+	stubGitLab.On("GetMergeRequest", 1, 1).Return(nil, gitlab.ErrNotFound)
+	mockedDB := &mDB.Store{}
+	mockedDB.On("ListOpenMergeRequests", "C0123456789").Return([]*model.MergeRequest{mr}, nil)
+	mockedDB.On("UpdateMergeRequest", mr).Return(nil)
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("GetChannelID", "fake-team").Return("C0123456789", nil)
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+		g:  stubGitLab,
+	}
+
+	w.postReviewDigest("fake-team", time.Now())
+
+	mockedDB.AssertExpectations(t)
+	mockedSlack.AssertNotCalled(t, "PostSlackMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	// merge request deleted from GitLab is closed in store
+	assert.Equal(t, mrClosed, mr.State)
+}
@@ -0,0 +1,204 @@
+package webhook
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"gitlack/model"
+
+	"github.com/sirupsen/logrus"
+)
+
+// events which routing rules can be restricted to, an empty event matches all of them
+const (
+	RouteMergeRequest = TemplateMergeRequest
+	RouteIssue        = TemplateIssue
+	RouteTagPush      = TemplateTagPush
+	RouteRelease      = TemplateRelease
+	RoutePush         = TemplatePush
+)
+
+// where the channels of route come from, in order of priority
+const (
+	RouteByDescription = "description"
+	RouteByRule        = "rule"
+	RouteByProject     = "project"
+	RouteByUser        = "user"
+	RouteByDefault     = "default"
+)
+
+var channelDirective = regexp.MustCompile("/gitlack:\\s?\\S+")
+
+// RouteTarget is the object which routing rules are matched against
+type RouteTarget struct {
+	Event        string
+	ProjectID    int
+	Path         string
+	Description  string
+	Labels       []string
+	SourceBranch string
+	TargetBranch string
+	Author       *model.User
+	// Owner is the user of whom the default channel is used, the assignee of merge request, the author otherwise
+	Owner *model.User
+	// FilePaths are the changed files, they're fetched from GitLab with MRNum when a rule needs them if it's nil
+	FilePaths []string
+	MRNum     int
+}
+
+// Route is where a message goes, the first channel is where the thread lives
+type Route struct {
+	Channels []string     `json:"channels"`
+	Reason   string       `json:"reason"`
+	Rule     *model.Route `json:"rule,omitempty"`
+}
+
+// ValidRouteEvent reports whether a routing rule can be restricted to event
+func ValidRouteEvent(event string) bool {
+	switch event {
+	case "", RouteMergeRequest, RouteIssue, RouteTagPush, RouteRelease, RoutePush:
+		return true
+	}
+	return false
+}
+
+// ExplainRoute returns the channels of target and why they're chosen
+func (h *hook) ExplainRoute(t *RouteTarget) (*Route, error) {
+	return h.route(t)
+}
+
+// route returns the Slack channels of target, priority: description > rule > project > user > #general
+func (h *hook) route(t *RouteTarget) (*Route, error) {
+	// get from description
+	parsed := channelDirective.FindString(t.Description)
+	if parsed != "" {
+		channel := strings.TrimSpace(strings.Replace(parsed, "/gitlack:", "", 1))
+		return &Route{Channels: []string{channel}, Reason: RouteByDescription}, nil
+	}
+
+	// get from rules of project and its groups
+	rules, err := h.db.ListRoutes(TemplatePaths(t.Path))
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if !h.matchRoute(rule, t) {
+			continue
+		}
+		if channels := splitList(rule.Channels); len(channels) != 0 {
+			return &Route{Channels: channels, Reason: RouteByRule, Rule: rule}, nil
+		}
+	}
+
+	// get from project default channel
+	project, err := h.db.GetProjectByID(t.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if project.DefaultChannel != "" {
+		return &Route{Channels: []string{project.DefaultChannel}, Reason: RouteByProject}, nil
+	}
+
+	// get from user default channel
+	if t.Owner != nil && t.Owner.DefaultChannel != "" {
+		return &Route{Channels: []string{t.Owner.DefaultChannel}, Reason: RouteByUser}, nil
+	}
+
+	return &Route{Channels: []string{"general"}, Reason: RouteByDefault}, nil
+}
+
+// matchRoute reports whether every condition of rule matches target,
+// changed files are checked last since they may have to be fetched from GitLab
+func (h *hook) matchRoute(rule *model.Route, t *RouteTarget) bool {
+	if rule.Event != "" && rule.Event != t.Event {
+		return false
+	}
+	if rule.Labels != "" && !containsAny(splitList(rule.Labels), t.Labels) {
+		return false
+	}
+	if rule.SourceBranch != "" && (t.SourceBranch == "" || !matchBranch(rule.SourceBranch, t.SourceBranch)) {
+		return false
+	}
+	if rule.TargetBranch != "" && (t.TargetBranch == "" || !matchBranch(rule.TargetBranch, t.TargetBranch)) {
+		return false
+	}
+	if rule.Authors != "" {
+		if t.Author == nil {
+			return false
+		}
+		if !containsAny(splitList(rule.Authors), []string{t.Author.Email, t.Author.Name}) {
+			return false
+		}
+	}
+	if rule.FilePaths != "" {
+		if t.FilePaths == nil && t.Event == RouteMergeRequest && t.MRNum != 0 {
+			files, err := h.g.GetMergeRequestChanges(t.ProjectID, t.MRNum)
+			if err != nil {
+				logrus.Warnf("changed files of !%v in project %v not found, rule %v skipped", t.MRNum, t.ProjectID, rule.Position)
+				return false
+			}
+			// cache the files for the rest of rules
+			t.FilePaths = append([]string{}, files...)
+		}
+		if !matchFiles(rule.FilePaths, t.FilePaths) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchFiles reports whether one of files matches one of the comma separated patterns,
+// pattern syntax is the same as path.Match, and `dir/**` matches everything under dir
+func matchFiles(patterns string, files []string) bool {
+	for _, p := range splitList(patterns) {
+		for _, f := range files {
+			if strings.HasSuffix(p, "/**") {
+				if strings.HasPrefix(f, strings.TrimSuffix(p, "**")) {
+					return true
+				}
+				continue
+			}
+			matched, err := path.Match(p, f)
+			if err != nil {
+				logrus.Errorf("invalid file pattern %q: %v", p, err)
+				break
+			}
+			if matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitList splits the comma separated list, empty items are dropped
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func containsAny(list, items []string) bool {
+	for _, l := range list {
+		for _, item := range items {
+			if item != "" && l == item {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// labelTitles returns the titles of labels for routing
+func labelTitles(labels []Label) []string {
+	var titles []string
+	for _, l := range labels {
+		titles = append(titles, l.Title)
+	}
+	return titles
+}
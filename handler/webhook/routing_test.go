@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"errors"
+	"testing"
+
+	"gitlack/model"
+
+	"github.com/stretchr/testify/assert"
+
+	mGitLab "gitlack/resource/gitlab/mocks"
+	mDB "gitlack/store/mocks"
+)
+
+func TestRouteDescription(t *testing.T) {
+	mockedDB := new(mDB.Store)
+	h := &hook{db: mockedDB}
+
+	route, err := h.route(&RouteTarget{Description: "fix bug\n/gitlack: fake-channel"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, &Route{Channels: []string{"fake-channel"}, Reason: RouteByDescription}, route)
+	mockedDB.AssertNotCalled(t, "ListRoutes", []string{})
+}
+
+func TestRouteRule(t *testing.T) {
+	rules := []*model.Route{
+		{Path: "fake/project", Position: 0, Event: RouteIssue, Channels: "fake-issue"},
+		{Path: "fake/project", Position: 1, Labels: "frontend, backend", TargetBranch: "release/*", Channels: "fake-backend,fake-release"},
+		{Path: "fake", Position: 0, Channels: "fake-group"},
+	}
+	mockedDB := new(mDB.Store)
+	mockedDB.On("ListRoutes", []string{"fake/project", "fake"}).Return(rules, nil)
+	h := &hook{db: mockedDB}
+
+	route, err := h.route(&RouteTarget{
+		Event:        RouteMergeRequest,
+		Path:         "fake/project",
+		Labels:       []string{"bug", "backend"},
+		TargetBranch: "release/1.0",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"fake-backend", "fake-release"}, route.Channels)
+	assert.Equal(t, RouteByRule, route.Reason)
+	assert.Equal(t, rules[1], route.Rule)
+
+	// group rule comes after project rules
+	route, err = h.route(&RouteTarget{Event: RouteTagPush, Path: "fake/project"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"fake-group"}, route.Channels)
+}
+
+func TestRouteFallback(t *testing.T) {
+	mockedDB := new(mDB.Store)
+	mockedDB.On("ListRoutes", []string{"fake"}).Return([]*model.Route{{Authors: "someone", Channels: "fake-rule"}}, nil)
+	mockedDB.On("GetProjectByID", 1).Return(&model.Project{}, nil)
+	mockedDB.On("GetProjectByID", 2).Return(&model.Project{DefaultChannel: "fake-project"}, nil)
+	h := &hook{db: mockedDB}
+	author := &model.User{Email: "fake-author", Name: "Fake Author"}
+
+	route, err := h.route(&RouteTarget{ProjectID: 2, Path: "fake", Author: author, Owner: author})
+	assert.Nil(t, err)
+	assert.Equal(t, &Route{Channels: []string{"fake-project"}, Reason: RouteByProject}, route)
+
+	route, err = h.route(&RouteTarget{ProjectID: 1, Path: "fake", Author: author, Owner: &model.User{DefaultChannel: "fake-user"}})
+	assert.Nil(t, err)
+	assert.Equal(t, &Route{Channels: []string{"fake-user"}, Reason: RouteByUser}, route)
+
+	route, err = h.route(&RouteTarget{ProjectID: 1, Path: "fake", Author: author, Owner: author})
+	assert.Nil(t, err)
+	assert.Equal(t, &Route{Channels: []string{"general"}, Reason: RouteByDefault}, route)
+}
+
+func TestMatchRouteFilePaths(t *testing.T) {
+	mockedGitLab := new(mGitLab.GitLab)
+	mockedGitLab.On("GetMergeRequestChanges", 1, 2).Return([]string{"README.md", "docs/api/user.md"}, nil).Once()
+	h := &hook{g: mockedGitLab}
+	target := &RouteTarget{Event: RouteMergeRequest, ProjectID: 1, MRNum: 2}
+
+	assert.False(t, h.matchRoute(&model.Route{FilePaths: "*.go"}, target))
+	// changed files are fetched only once
+	assert.True(t, h.matchRoute(&model.Route{FilePaths: "docs/**"}, target))
+	assert.True(t, h.matchRoute(&model.Route{FilePaths: "*.go,*.md"}, target))
+	mockedGitLab.AssertNumberOfCalls(t, "GetMergeRequestChanges", 1)
+}
+
+func TestMatchRouteFilePathsGitLabError(t *testing.T) {
+	mockedGitLab := new(mGitLab.GitLab)
+	mockedGitLab.On("GetMergeRequestChanges", 1, 2).Return(nil, errors.New("fake error"))
+	h := &hook{g: mockedGitLab}
+
+	assert.False(t, h.matchRoute(&model.Route{FilePaths: "*"}, &RouteTarget{Event: RouteMergeRequest, ProjectID: 1, MRNum: 2}))
+}
+
+func TestMatchRouteAuthor(t *testing.T) {
+	h := &hook{}
+	rule := &model.Route{Authors: "fake-author, other"}
+
+	assert.True(t, h.matchRoute(rule, &RouteTarget{Author: &model.User{Email: "fake-author"}}))
+	assert.False(t, h.matchRoute(rule, &RouteTarget{Author: &model.User{Email: "someone"}}))
+	assert.False(t, h.matchRoute(rule, &RouteTarget{}))
+}
+
+func TestValidRouteEvent(t *testing.T) {
+	assert.True(t, ValidRouteEvent(""))
+	assert.True(t, ValidRouteEvent(RouteRelease))
+	assert.False(t, ValidRouteEvent("comment"))
+}
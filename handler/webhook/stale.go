@@ -0,0 +1,186 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gitlack/model"
+	"gitlack/resource/gitlab"
+
+	"github.com/sirupsen/logrus"
+)
+
+// touchMR records the activity of merge request, which postpones its reminder and escalation
+func (h *hook) touchMR(projectID, num int) {
+	h.db.UpdateMergeRequestActivity(projectID, num, time.Now())
+}
+
+// DispatchStaleReminders reminds the assignees of merge requests idle longer than the threshold of project in thread,
+// and escalates the ones idle longer than the second threshold, each once per activity
+func (h *hook) DispatchStaleReminders() {
+	if !atomic.CompareAndSwapInt32(&h.staling, 0, 1) {
+		logrus.Debugln("stale reminders are being dispatched")
+		return
+	}
+	defer atomic.StoreInt32(&h.staling, 0)
+
+	mrs, err := h.db.ListIdleMergeRequests()
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	projects := map[int]*model.Project{}
+	for _, mr := range mrs {
+		// the merge requests opened before activity is tracked are left until their next activity
+		if mr.LastActivityAt.IsZero() {
+			continue
+		}
+		p, ok := projects[mr.ProjectID]
+		if !ok {
+			p, err = h.db.GetProjectByID(mr.ProjectID)
+			if err != nil {
+				continue
+			}
+			projects[mr.ProjectID] = p
+		}
+
+		idle := workingTime(mr.LastActivityAt, now)
+		remind := exceeded(idle, p.StaleAfter) && mr.RemindedAt.Before(mr.LastActivityAt)
+		escalate := exceeded(idle, p.EscalateAfter) && mr.EscalatedAt.Before(mr.LastActivityAt)
+		if !remind && !escalate {
+			continue
+		}
+		current, ok := h.currentMR(mr)
+		if !ok {
+			continue
+		}
+		assignees := h.assignees(current)
+		if remind {
+			h.remindStale(p, mr, assignees, now)
+			mr.RemindedAt = now
+		}
+		if escalate {
+			h.escalateStale(p, mr, current, assignees, now)
+			mr.EscalatedAt = now
+		}
+		h.db.UpdateMergeRequestReminders(mr)
+	}
+}
+
+// remindStale replies in the thread of merge request mentioning the assignees as they prefer
+func (h *hook) remindStale(p *model.Project, mr *model.MergeRequest, assignees []*model.User, now time.Time) {
+	var names []string
+	for _, u := range assignees {
+		names = append(names, mentionName(mentioned(u, model.MentionStaleMR, p.Name)))
+	}
+	idle := age(now.Sub(mr.LastActivityAt))
+	text := fmt.Sprintf("This merge request has had no activity for %v.", idle)
+	if len(names) != 0 {
+		text = strings.Join(names, " ") + " " + text
+	}
+	h.post(&message{
+		channel:   mr.Channel,
+		text:      text,
+		threadTS:  mr.ThreadTS,
+		projectID: mr.ProjectID,
+	})
+	// the assignees who want direct messages get one, the text in thread is posted already
+	h.notify(&notice{
+		event:     model.MentionStaleMR,
+		path:      p.Name,
+		projectID: mr.ProjectID,
+		channel:   mr.Channel,
+		threadTS:  mr.ThreadTS,
+		dmText: func() string {
+			return fmt.Sprintf("Merge request %v!%v assigned to you has had no activity for %v", p.Name, mr.MergeRequestNum, idle)
+		},
+		users: assignees,
+	})
+	logrus.Infof("stale merge request %v!%v reminded", p.Name, mr.MergeRequestNum)
+}
+
+// escalateStale posts the idle merge request to the escalation channel of project,
+// or to the lead by direct message if it's an email, linking back to the thread
+func (h *hook) escalateStale(p *model.Project, mr *model.MergeRequest, current *gitlab.MergeRequest, assignees []*model.User, now time.Time) {
+	channel := p.EscalateTo
+	if channel == "" {
+		channel = p.DefaultChannel
+	}
+	if strings.Contains(channel, "@") {
+		lead, err := h.db.GetUserByEmail(channel)
+		if err != nil || lead.SlackID == "" {
+			logrus.Infof("lead of %v not found in Slack: %v", p.Name, channel)
+			return
+		}
+		channel, err = h.s.OpenConversation(lead.SlackID)
+		if err != nil {
+			return
+		}
+	}
+	if channel == "" {
+		logrus.Infof("no channel to escalate stale merge request %v!%v", p.Name, mr.MergeRequestNum)
+		return
+	}
+
+	var names []string
+	for _, u := range assignees {
+		names = append(names, u.Name)
+	}
+	owner := "nobody"
+	if len(names) != 0 {
+		owner = strings.Join(names, ", ")
+	}
+	h.post(&message{
+		channel: channel,
+		text: fmt.Sprintf(":warning: <%v|%v!%v> %v has had no activity for %v, assigned to %v\n%v",
+			current.WebURL, p.Name, mr.MergeRequestNum, current.Title, age(now.Sub(mr.LastActivityAt)), owner,
+			h.threadLink(mr.Channel, mr.ThreadTS)),
+		projectID: mr.ProjectID,
+	})
+	logrus.Infof("stale merge request %v!%v escalated to %v", p.Name, mr.MergeRequestNum, channel)
+}
+
+// assignees returns the assignees of merge request in store, the ones not found are skipped
+func (h *hook) assignees(mr *gitlab.MergeRequest) []*model.User {
+	var users []*model.User
+	for _, a := range mr.Assignees {
+		u, err := h.db.GetUserByID(a.ID)
+		if err != nil {
+			continue
+		}
+		users = append(users, u)
+	}
+	return users
+}
+
+// mentionName returns the mention of user, or the name if user isn't in Slack
+func mentionName(u *model.User) string {
+	if u.SlackID != "" {
+		return fmt.Sprintf("<@%v>", u.SlackID)
+	}
+	return u.Name
+}
+
+// exceeded returns whether idle is at least the threshold in hours, a zero threshold is never exceeded
+func exceeded(idle time.Duration, hours int) bool {
+	return hours > 0 && idle >= time.Duration(hours)*time.Hour
+}
+
+// workingTime returns the time between from and to except Saturdays and Sundays, in the time zone of the server
+func workingTime(from, to time.Time) time.Duration {
+	var d time.Duration
+	from = from.Local()
+	for from.Before(to) {
+		next := time.Date(from.Year(), from.Month(), from.Day()+1, 0, 0, 0, 0, from.Location())
+		if next.After(to) {
+			next = to
+		}
+		if wd := from.Weekday(); wd != time.Saturday && wd != time.Sunday {
+			d += next.Sub(from)
+		}
+		from = next
+	}
+	return d
+}
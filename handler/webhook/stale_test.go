@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"gitlack/model"
+	"gitlack/resource/gitlab"
+	"gitlack/resource/slack"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	mGitLab "gitlack/resource/gitlab/mocks"
+	mSlack "gitlack/resource/slack/mocks"
+	mDB "gitlack/store/mocks"
+)
+
+func TestWorkingTime(t *testing.T) {
+	friday := time.Date(2026, 10, 16, 12, 0, 0, 0, time.Local)
+	input := map[time.Time]time.Duration{
+		friday.Add(3 * time.Hour):  3 * time.Hour,
+		friday.Add(24 * time.Hour): 12 * time.Hour,
+		friday.Add(72 * time.Hour): 24 * time.Hour,
+		friday.Add(96 * time.Hour): 48 * time.Hour,
+		friday.Add(-time.Hour):     0,
+	}
+	for to, expected := range input {
+		assert.Equal(t, expected, workingTime(friday, to), to.String())
+	}
+}
+
+func TestDispatchStaleReminders(t *testing.T) {
+	idle := time.Now().Add(-10 * 24 * time.Hour)
+	mrs := []*model.MergeRequest{
+		{ProjectID: 1, MergeRequestNum: 1, Channel: "fake-channel", ThreadTS: "fake-ts", LastActivityAt: idle},
+		// reminded and escalated since the last activity
+		{ProjectID: 1, MergeRequestNum: 2, LastActivityAt: idle, RemindedAt: idle.Add(time.Hour), EscalatedAt: idle.Add(time.Hour)},
+		// opened before activity is tracked
+		{ProjectID: 1, MergeRequestNum: 3},
+	}
+	project := &model.Project{ID: 1, Name: "fake/project", DefaultChannel: "fake-project-channel", StaleAfter: 16, EscalateAfter: 48}
+	assignee := &model.User{GitLabID: 3, Name: "fake-assignee", SlackID: "fake-assignee-slack-id"}
+
+	var nilUser *model.User
+	var nilAtm *slack.Attachment
+	stubGitLab := &mGitLab.GitLab{}
+	stubGitLab.On("GetMergeRequest", 1, 1).Return(&gitlab.MergeRequest{IID: 1, Title: "fake-title", State: "opened", WebURL: "http://fake/1",
+		Assignees: []*gitlab.GitLabUser{{ID: 3}}}, nil)
+	mockedDB := &mDB.Store{}
+	mockedDB.On("ListIdleMergeRequests").Return(mrs, nil)
+	mockedDB.On("GetProjectByID", 1).Return(project, nil)
+	mockedDB.On("GetUserByID", 3).Return(assignee, nil)
+	mockedDB.On("UpdateMergeRequestReminders", mock.MatchedBy(func(mr *model.MergeRequest) bool {
+		return mr.MergeRequestNum == 1 && mr.RemindedAt.After(idle) && mr.EscalatedAt.After(idle)
+	})).Return(nil)
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("PostSlackMessage", "fake-channel", "<@fake-assignee-slack-id> This merge request has had no activity for 10d.",
+		nilUser, nilAtm, "fake-ts").Return(&slack.MessageResponse{OK: true}, nil)
+	mockedSlack.On("GetPermalink", "fake-channel", "fake-ts").Return("https://fake.slack.com/p1", nil)
+	mockedSlack.On("PostSlackMessage", "fake-project-channel",
+		":warning: <http://fake/1|fake/project!1> fake-title has had no activity for 10d, assigned to fake-assignee\n<https://fake.slack.com/p1|View the thread>",
+		nilUser, nilAtm).Return(&slack.MessageResponse{OK: true}, nil)
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+		g:  stubGitLab,
+	}
+
+	w.DispatchStaleReminders()
+
+	mockedSlack.AssertExpectations(t)
+	mockedDB.AssertExpectations(t)
+	mockedDB.AssertNumberOfCalls(t, "GetProjectByID", 1)
+	stubGitLab.AssertNumberOfCalls(t, "GetMergeRequest", 1)
+}
+
+func TestEscalateStaleToLead(t *testing.T) {
+	now := time.Now()
+	mr := &model.MergeRequest{ProjectID: 1, MergeRequestNum: 1, Channel: "fake-channel", ThreadTS: "fake-ts", LastActivityAt: now.Add(-3 * time.Hour)}
+	project := &model.Project{ID: 1, Name: "fake/project", DefaultChannel: "fake-project-channel", EscalateTo: "lead@fake.com"}
+
+	var nilUser *model.User
+	var nilAtm *slack.Attachment
+	mockedDB := &mDB.Store{}
+	mockedDB.On("GetUserByEmail", "lead@fake.com").Return(&model.User{SlackID: "fake-lead-slack-id"}, nil)
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("OpenConversation", "fake-lead-slack-id").Return("fake-dm", nil)
+	mockedSlack.On("GetPermalink", "fake-channel", "fake-ts").Return("https://fake.slack.com/p1", nil)
+	mockedSlack.On("PostSlackMessage", "fake-dm",
+		":warning: <http://fake/1|fake/project!1> fake-title has had no activity for 3h, assigned to nobody\n<https://fake.slack.com/p1|View the thread>",
+		nilUser, nilAtm).Return(&slack.MessageResponse{OK: true}, nil)
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+
+	w.escalateStale(project, mr, &gitlab.MergeRequest{Title: "fake-title", WebURL: "http://fake/1"}, nil, now)
+
+	mockedSlack.AssertExpectations(t)
+}
+
+func TestCommentTouchesMR(t *testing.T) {
+	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", 999, 1, mock.Anything).Return(nil)
+	mockedDB.On("GetUserByID", mock.Anything).Return(nil, assert.AnError)
+	w := &hook{db: mockedDB}
+
+	w.CommentsEvent([]byte(`{"object_attributes": {"noteable_type": "MergeRequest"}, "project": {"id": 999}, "merge_request": {"iid": 1}}`))
+
+	mockedDB.AssertNumberOfCalls(t, "UpdateMergeRequestActivity", 1)
+}
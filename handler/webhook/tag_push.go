@@ -1,12 +1,14 @@
 package webhook
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
-	"text/template"
+
+	"gitlack/resource/gitlab"
+	"gitlack/resource/slack"
 
 	"github.com/sirupsen/logrus"
 )
@@ -14,72 +16,80 @@ import (
 const tagPushTemplate = "<@{{.Author}}> has pushed a new tag: <{{.Link}}|{{.Tag}}> to `{{.Path}}`!\n" +
 	"{{.Note}}\n"
 
+var errTagDeleted = skip("tag is deleted")
+
 // TagPushEvent represents the data structure of tag push in GitLab webhook request
 type TagPushEvent struct {
 	CheckoutSHA string  `json:"checkout_sha"`
+	Ref         string  `json:"ref"`
 	ProjectInfo Project `json:"project"`
 	Message     string  `json:"message"`
 	AuthorID    int     `json:"user_id"`
 }
 
-func (h *hook) TagPushEvent(b []byte) {
+func (h *hook) TagPushEvent(b []byte) error {
 	var tagPushInfo TagPushEvent
 	err := json.Unmarshal(b, &tagPushInfo)
 	if err != nil {
 		logrus.Errorln(err)
-		return
+		return err
+	}
+	m, err := h.tagPushMessage(tagPushInfo)
+	if err != nil {
+		return failure(err)
 	}
+	h.post(m)
+	h.postCopies(m)
+	return nil
+}
+
+// tagPushMessage returns the message announcing the pushed tag and the channel it's posted to
+func (h *hook) tagPushMessage(tagPushInfo TagPushEvent) (*message, error) {
 	// if a tag is deleted, the `checkout_sha` will be Null
 	if tagPushInfo.CheckoutSHA == "" {
-		logrus.Infoln("tag is deleted")
-		return
+		logrus.Infoln(errTagDeleted)
+		return nil, errTagDeleted
 	}
 
 	// get author
 	author, err := h.db.GetUserByID(tagPushInfo.AuthorID)
 	if err != nil {
-		return
+		return nil, err
 	}
 
-	// get target Slack channel, priority: description > project > user > #general
-	// get from mr description
-	var channel string
-	re, err := regexp.Compile("/gitlack:\\s?\\S+")
+	// tag message works as description
+	route, err := h.route(&RouteTarget{
+		Event:       RouteTagPush,
+		ProjectID:   tagPushInfo.ProjectInfo.ID,
+		Path:        tagPushInfo.ProjectInfo.PathWithNamespace,
+		Description: tagPushInfo.Message,
+		Author:      author,
+		Owner:       author,
+	})
 	if err != nil {
-		logrus.Errorln(err)
-		return
-	}
-	parsed := re.FindString(tagPushInfo.Message)
-	if parsed != "" {
-		channel = strings.TrimSpace(strings.Replace(parsed, "/gitlack:", "", 1))
+		return nil, err
 	}
 
-	// get from project default channel
-	if channel == "" {
-		proejct, err := h.db.GetProjectByID(tagPushInfo.ProjectInfo.ID)
-		if err != nil {
-			return
-		}
-		if proejct.DefaultChannel != "" {
-			channel = proejct.DefaultChannel
-		}
-	}
-
-	// get from author default channel
-	if channel == "" {
-		if author.DefaultChannel != "" {
-			channel = author.DefaultChannel
+	// the pushed tag is in the payload, the tag list is only for release note and previous tag
+	tagName := strings.TrimPrefix(tagPushInfo.Ref, "refs/tags/")
+	var tagReleaseNote, previousTag string
+	tagList, err := h.g.GetTagList(tagPushInfo.ProjectInfo.ID)
+	if err == nil {
+		for _, tag := range tagList {
+			if tag.Name != tagName {
+				continue
+			}
+			// the tag created together with its release is announced by the release hook
+			if tag.ReleaseInfo.TagName != "" {
+				err := skip("tag %v of %v is announced by its release", tagName, tagPushInfo.ProjectInfo.PathWithNamespace)
+				logrus.Infoln(err)
+				return nil, err
+			}
+			tagReleaseNote = tag.ReleaseInfo.Description
+			break
 		}
+		previousTag = previousVersion(tagList, tagName)
 	}
-
-	if channel == "" {
-		channel = "general"
-	}
-
-	// get tag list
-	tagList, err := h.g.GetTagList(tagPushInfo.ProjectInfo.ID)
-	tagName := tagList[0].Name
-	tagReleaseNote := tagList[0].ReleaseInfo.Description
 	tagURL := fmt.Sprintf("%v/tags/%v", tagPushInfo.ProjectInfo.WebURL, tagName)
 
 	// prepare slack text
@@ -90,16 +100,157 @@ func (h *hook) TagPushEvent(b []byte) {
 		"Note":   tagReleaseNote,
 		"Link":   tagURL,
 	}
-	t, err := template.New("slack").Parse(tagPushTemplate)
+	slackText, custom, err := h.render(TemplateTagPush, tagPushInfo.ProjectInfo.PathWithNamespace, data)
 	if err != nil {
-		logrus.Errorln(err)
-		return
+		return nil, err
 	}
-	slackText := &bytes.Buffer{}
-	err = t.Execute(slackText, data)
-	if err != nil {
-		logrus.Errorln(err)
-		return
+	return &message{
+		channel:    route.Channels[0],
+		copies:     route.Channels[1:],
+		text:       slackText,
+		attachment: h.tagChangelog(tagPushInfo, previousTag, tagName),
+		blocks:     withBody(tagBlocks(tagPushInfo, author, tagName, tagReleaseNote, tagURL), slackText, custom),
+		projectID:  tagPushInfo.ProjectInfo.ID,
+	}, nil
+}
+
+// tagChangelog returns the changelog between the previous tag and the pushed one,
+// nil if there is no previous tag or nothing changed
+func (h *hook) tagChangelog(tagPushInfo TagPushEvent, previousTag, tagName string) *slack.Attachment {
+	if previousTag == "" {
+		return nil
+	}
+	compare, err := h.g.GetCompare(tagPushInfo.ProjectInfo.ID, previousTag, tagName)
+	if err != nil || len(compare.Commits) == 0 {
+		return nil
+	}
+
+	text := changelog(compare.Commits, tagPushInfo.ProjectInfo.WebURL)
+
+	// mention committers by their Slack ID, use the name in commit if not found
+	var committers []string
+	mentioned := make(map[string]bool)
+	for _, c := range compare.Commits {
+		email := strings.Split(c.AuthorEmail, "@")[0]
+		if email == "" || mentioned[email] {
+			continue
+		}
+		mentioned[email] = true
+		u, err := h.db.GetUserByEmail(email)
+		if err != nil || u.SlackID == "" {
+			committers = append(committers, c.AuthorName)
+			continue
+		}
+		committers = append(committers, fmt.Sprintf("<@%v>", u.SlackID))
+	}
+	if len(committers) != 0 {
+		text += "\n\nCommitters: " + strings.Join(committers, ", ")
+	}
+
+	return &slack.Attachment{
+		Color: slack.AttachmentColor,
+		Title: fmt.Sprintf("Changes since %v", previousTag),
+		Text:  text,
+	}
+}
+
+// tagVersion matches the tag such as `api-v1.2.0-rc.1`, which is split into
+// the prefix `api-v`, the version `1.2.0` and the pre-release `rc.1`
+var tagVersion = regexp.MustCompile(`^(.*?)(\d+(?:\.\d+)*)(?:-([0-9A-Za-z.-]+))?$`)
+
+type version struct {
+	prefix     string
+	numbers    []int
+	prerelease string
+}
+
+func parseVersion(tag string) (*version, bool) {
+	m := tagVersion.FindStringSubmatch(tag)
+	if m == nil {
+		return nil, false
+	}
+	v := &version{prefix: m[1], prerelease: m[3]}
+	for _, n := range strings.Split(m[2], ".") {
+		i, _ := strconv.Atoi(n)
+		v.numbers = append(v.numbers, i)
+	}
+	return v, true
+}
+
+// compare returns -1, 0 or 1 if v is lower than, equal to or higher than o in the precedence of semantic versioning
+// see: https://semver.org/#spec-item-11
+func (v *version) compare(o *version) int {
+	for i := 0; i < len(v.numbers) || i < len(o.numbers); i++ {
+		var a, b int
+		if i < len(v.numbers) {
+			a = v.numbers[i]
+		}
+		if i < len(o.numbers) {
+			b = o.numbers[i]
+		}
+		if a != b {
+			return sign(a - b)
+		}
+	}
+	// a pre-release is lower than its release
+	switch {
+	case v.prerelease == o.prerelease:
+		return 0
+	case v.prerelease == "":
+		return 1
+	case o.prerelease == "":
+		return -1
+	}
+	x, y := strings.Split(v.prerelease, "."), strings.Split(o.prerelease, ".")
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if x[i] == y[i] {
+			continue
+		}
+		a, errA := strconv.Atoi(x[i])
+		b, errB := strconv.Atoi(y[i])
+		switch {
+		case errA == nil && errB == nil:
+			return sign(a - b)
+		case errA == nil:
+			return -1
+		case errB == nil:
+			return 1
+		}
+		return strings.Compare(x[i], y[i])
+	}
+	return sign(len(x) - len(y))
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// previousVersion returns the highest tag lower than tagName in version order among the tags with the same prefix,
+// empty if tagName isn't a version or there is no lower one
+func previousVersion(tags []*gitlab.Tag, tagName string) string {
+	current, ok := parseVersion(tagName)
+	if !ok {
+		return ""
+	}
+	var previous string
+	var highest *version
+	for _, tag := range tags {
+		if tag.Name == tagName {
+			continue
+		}
+		v, ok := parseVersion(tag.Name)
+		if !ok || v.prefix != current.prefix || v.compare(current) >= 0 {
+			continue
+		}
+		if highest == nil || v.compare(highest) > 0 {
+			previous, highest = tag.Name, v
+		}
 	}
-	_, err = h.s.PostSlackMessage(channel, slackText.String(), nil, nil)
+	return previous
 }
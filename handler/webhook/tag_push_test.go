@@ -1,9 +1,14 @@
 package webhook
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
 	"gitlack/model"
 
 	"gitlack/resource/gitlab"
@@ -16,6 +21,7 @@ import (
 
 const tagPushBodyTemplate = `{
 	"checkout_sha": "{{.SHA}}",
+	"ref": "refs/tags/fake-tag-name",
 	"message": "{{.Message}}",
 	"user_id": {{.UserID}},
 	"project": {
@@ -35,6 +41,8 @@ func TestTagPushEvent(t *testing.T) {
 	}
 
 	mockedDB := &mDB.Store{}
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
 	mockedGitLab := &mGitLab.GitLab{}
 	mockedSlack := &mSlack.Slack{}
 
@@ -65,9 +73,10 @@ func TestTagPushEvent(t *testing.T) {
 	var nilUser *model.User
 	var nilAtm *slack.Attachment
 	mockedGitLab.On("GetTagList", fakeData["ProjectID"].(int)).Return(mockedTagList, nil)
+	mockedGitLab.On("GetCompare", fakeData["ProjectID"].(int), mock.Anything, mock.Anything).Return(&gitlab.Compare{}, nil)
 	mockedDB.On("GetUserByID", fakeData["UserID"].(int)).Return(mockedAuthor, nil)
 	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(mockedProject, nil)
-	mockedSlack.On("PostSlackMessage", "general", slackExpected.String(), nilUser, nilAtm).Return(nil, nil)
+	mockedSlack.On("PostSlackBlocks", "general", slackExpected.String(), nilUser, nilAtm, mock.Anything).Return(nil, nil)
 
 	w := &hook{
 		db: mockedDB,
@@ -81,7 +90,7 @@ func TestTagPushEvent(t *testing.T) {
 	mockedDB.AssertNumberOfCalls(t, "GetUserByID", 1)
 	mockedDB.AssertNumberOfCalls(t, "GetProjectByID", 1)
 	mockedGitLab.AssertNumberOfCalls(t, "GetTagList", 1)
-	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1)
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackBlocks", 1)
 }
 
 func TestTagPushTagDeleteEvent(t *testing.T) {
@@ -94,6 +103,8 @@ func TestTagPushTagDeleteEvent(t *testing.T) {
 	}
 
 	mockedDB := &mDB.Store{}
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
 	mockedGitLab := &mGitLab.GitLab{}
 	mockedSlack := &mSlack.Slack{}
 
@@ -109,7 +120,7 @@ func TestTagPushTagDeleteEvent(t *testing.T) {
 	mockedGitLab.AssertNotCalled(t, "GetTagList")
 	mockedDB.AssertNotCalled(t, "GetUserByID")
 	mockedDB.AssertNotCalled(t, "GetProjectByID")
-	mockedSlack.AssertNotCalled(t, "PostSlackMessage")
+	mockedSlack.AssertNotCalled(t, "PostSlackBlocks")
 }
 
 func TestTagPushOnlyOneTag(t *testing.T) {
@@ -122,6 +133,8 @@ func TestTagPushOnlyOneTag(t *testing.T) {
 	}
 
 	mockedDB := &mDB.Store{}
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
 	mockedGitLab := &mGitLab.GitLab{}
 	mockedSlack := &mSlack.Slack{}
 
@@ -148,9 +161,10 @@ func TestTagPushOnlyOneTag(t *testing.T) {
 	var nilUser *model.User
 	var nilAtm *slack.Attachment
 	mockedGitLab.On("GetTagList", fakeData["ProjectID"].(int)).Return(mockedTagList, nil)
+	mockedGitLab.On("GetCompare", fakeData["ProjectID"].(int), mock.Anything, mock.Anything).Return(&gitlab.Compare{}, nil)
 	mockedDB.On("GetUserByID", fakeData["UserID"].(int)).Return(mockedAuthor, nil)
 	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(mockedProject, nil)
-	mockedSlack.On("PostSlackMessage", "general", slackExpected.String(), nilUser, nilAtm).Return(nil, nil)
+	mockedSlack.On("PostSlackBlocks", "general", slackExpected.String(), nilUser, nilAtm, mock.Anything).Return(nil, nil)
 
 	w := &hook{
 		db: mockedDB,
@@ -164,7 +178,89 @@ func TestTagPushOnlyOneTag(t *testing.T) {
 	mockedDB.AssertNumberOfCalls(t, "GetUserByID", 1)
 	mockedDB.AssertNumberOfCalls(t, "GetProjectByID", 1)
 	mockedGitLab.AssertNumberOfCalls(t, "GetTagList", 1)
-	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1)
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackBlocks", 1)
+}
+
+func TestTagPushTagNotInList(t *testing.T) {
+	fakeData := map[string]interface{}{
+		"SHA":       "fake-checkout-sha",
+		"Message":   "fake-message",
+		"UserID":    1,
+		"ProjectID": 999,
+		"Path":      "fake/fake-gitlab-project",
+	}
+
+	mockedDB := &mDB.Store{}
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
+	mockedGitLab := &mGitLab.GitLab{}
+	mockedSlack := &mSlack.Slack{}
+
+	mockedAuthor := &model.User{
+		Email:   "fake-author@fake.com",
+		SlackID: "fake-author-slack-id",
+	}
+	mockedProject := &model.Project{}
+
+	expected := map[string]string{
+		"Author": mockedAuthor.SlackID,
+		"Tag":    "fake-tag-name",
+		"Path":   fakeData["Path"].(string),
+		"Note":   "",
+		"Link":   fmt.Sprintf("http://fake.com/%v/tags/%v", fakeData["Path"].(string), "fake-tag-name"),
+	}
+	slackExpected := renderTemplate(tagPushTemplate, expected)
+	var nilUser *model.User
+	var nilAtm *slack.Attachment
+	var emptyTagList []*gitlab.Tag
+	mockedGitLab.On("GetTagList", fakeData["ProjectID"].(int)).Return(emptyTagList, nil)
+	mockedDB.On("GetUserByID", fakeData["UserID"].(int)).Return(mockedAuthor, nil)
+	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(mockedProject, nil)
+	mockedSlack.On("PostSlackBlocks", "general", slackExpected.String(), nilUser, nilAtm, mock.Anything).Return(nil, nil)
+
+	w := &hook{
+		db: mockedDB,
+		g:  mockedGitLab,
+		s:  mockedSlack,
+	}
+
+	body := renderTemplate(tagPushBodyTemplate, fakeData)
+	w.TagPushEvent(body.Bytes())
+
+	mockedGitLab.AssertNumberOfCalls(t, "GetTagList", 1)
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackBlocks", 1)
+}
+
+func TestTagPushWithReleaseSkipped(t *testing.T) {
+	fakeData := map[string]interface{}{
+		"SHA":       "fake-checkout-sha",
+		"Message":   "fake-message",
+		"UserID":    1,
+		"ProjectID": 999,
+		"Path":      "fake/fake-gitlab-project",
+	}
+
+	mockedDB := &mDB.Store{}
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
+	mockedDB.On("GetUserByID", fakeData["UserID"].(int)).Return(&model.User{SlackID: "fake-author-slack-id"}, nil)
+	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(&model.Project{}, nil)
+	mockedGitLab := &mGitLab.GitLab{}
+	mockedGitLab.On("GetTagList", fakeData["ProjectID"].(int)).Return([]*gitlab.Tag{
+		{Name: "fake-tag-name", ReleaseInfo: gitlab.Release{TagName: "fake-tag-name", Description: "fake-desc"}},
+	}, nil)
+	mockedSlack := &mSlack.Slack{}
+
+	w := &hook{
+		db: mockedDB,
+		g:  mockedGitLab,
+		s:  mockedSlack,
+	}
+
+	body := renderTemplate(tagPushBodyTemplate, fakeData)
+	err := w.TagPushEvent(body.Bytes())
+
+	assert.Nil(t, err)
+	mockedSlack.AssertNotCalled(t, "PostSlackBlocks", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestTagPushChannelFromMessage(t *testing.T) {
@@ -176,6 +272,8 @@ func TestTagPushChannelFromMessage(t *testing.T) {
 	}
 
 	mockedDB := &mDB.Store{}
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
 	mockedGitLab := &mGitLab.GitLab{}
 	mockedSlack := &mSlack.Slack{}
 
@@ -206,6 +304,7 @@ func TestTagPushChannelFromMessage(t *testing.T) {
 	var nilUser *model.User
 	var nilAtm *slack.Attachment
 	mockedGitLab.On("GetTagList", fakeData["ProjectID"].(int)).Return(mockedTagList, nil)
+	mockedGitLab.On("GetCompare", fakeData["ProjectID"].(int), mock.Anything, mock.Anything).Return(&gitlab.Compare{}, nil)
 	mockedDB.On("GetUserByID", fakeData["UserID"].(int)).Return(mockedAuthor, nil)
 	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(mockedProject, nil)
 
@@ -224,7 +323,7 @@ func TestTagPushChannelFromMessage(t *testing.T) {
 		}
 
 		fakeData["Message"] = fmt.Sprintf("a\\nb\\n%v", m)
-		mockedSlack.On("PostSlackMessage", c, slackExpected.String(), nilUser, nilAtm).Return(nil, nil)
+		mockedSlack.On("PostSlackBlocks", c, slackExpected.String(), nilUser, nilAtm, mock.Anything).Return(nil, nil)
 		body := renderTemplate(tagPushBodyTemplate, fakeData)
 		w.TagPushEvent(body.Bytes())
 	}
@@ -232,7 +331,7 @@ func TestTagPushChannelFromMessage(t *testing.T) {
 	mockedGitLab.AssertNumberOfCalls(t, "GetTagList", 1*len(input))
 	mockedDB.AssertNumberOfCalls(t, "GetUserByID", 1*len(input))
 	mockedDB.AssertNotCalled(t, "GetProjectByID")
-	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1*len(input))
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackBlocks", 1*len(input))
 }
 
 func TestTagPushChannelFromProject(t *testing.T) {
@@ -245,6 +344,8 @@ func TestTagPushChannelFromProject(t *testing.T) {
 	}
 
 	mockedDB := &mDB.Store{}
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
 	mockedGitLab := &mGitLab.GitLab{}
 	mockedSlack := &mSlack.Slack{}
 
@@ -275,9 +376,10 @@ func TestTagPushChannelFromProject(t *testing.T) {
 	var nilUser *model.User
 	var nilAtm *slack.Attachment
 	mockedGitLab.On("GetTagList", fakeData["ProjectID"].(int)).Return(mockedTagList, nil)
+	mockedGitLab.On("GetCompare", fakeData["ProjectID"].(int), mock.Anything, mock.Anything).Return(&gitlab.Compare{}, nil)
 	mockedDB.On("GetUserByID", fakeData["UserID"].(int)).Return(mockedAuthor, nil)
 	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(mockedProject, nil)
-	mockedSlack.On("PostSlackMessage", mockedProject.DefaultChannel, slackExpected.String(), nilUser, nilAtm).Return(nil, nil)
+	mockedSlack.On("PostSlackBlocks", mockedProject.DefaultChannel, slackExpected.String(), nilUser, nilAtm, mock.Anything).Return(nil, nil)
 
 	w := &hook{
 		db: mockedDB,
@@ -291,7 +393,7 @@ func TestTagPushChannelFromProject(t *testing.T) {
 	mockedDB.AssertNumberOfCalls(t, "GetUserByID", 1)
 	mockedDB.AssertNumberOfCalls(t, "GetProjectByID", 1)
 	mockedGitLab.AssertNumberOfCalls(t, "GetTagList", 1)
-	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1)
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackBlocks", 1)
 }
 
 func TestTagPushChannelFromAuthor(t *testing.T) {
@@ -304,6 +406,8 @@ func TestTagPushChannelFromAuthor(t *testing.T) {
 	}
 
 	mockedDB := &mDB.Store{}
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
 	mockedGitLab := &mGitLab.GitLab{}
 	mockedSlack := &mSlack.Slack{}
 
@@ -335,9 +439,10 @@ func TestTagPushChannelFromAuthor(t *testing.T) {
 	var nilUser *model.User
 	var nilAtm *slack.Attachment
 	mockedGitLab.On("GetTagList", fakeData["ProjectID"].(int)).Return(mockedTagList, nil)
+	mockedGitLab.On("GetCompare", fakeData["ProjectID"].(int), mock.Anything, mock.Anything).Return(&gitlab.Compare{}, nil)
 	mockedDB.On("GetUserByID", fakeData["UserID"].(int)).Return(mockedAuthor, nil)
 	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(mockedProject, nil)
-	mockedSlack.On("PostSlackMessage", mockedAuthor.DefaultChannel, slackExpected.String(), nilUser, nilAtm).Return(nil, nil)
+	mockedSlack.On("PostSlackBlocks", mockedAuthor.DefaultChannel, slackExpected.String(), nilUser, nilAtm, mock.Anything).Return(nil, nil)
 
 	w := &hook{
 		db: mockedDB,
@@ -351,7 +456,7 @@ func TestTagPushChannelFromAuthor(t *testing.T) {
 	mockedDB.AssertNumberOfCalls(t, "GetUserByID", 1)
 	mockedDB.AssertNumberOfCalls(t, "GetProjectByID", 1)
 	mockedGitLab.AssertNumberOfCalls(t, "GetTagList", 1)
-	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1)
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackBlocks", 1)
 }
 
 func TestTagPushChannelOverwrite(t *testing.T) {
@@ -364,6 +469,8 @@ func TestTagPushChannelOverwrite(t *testing.T) {
 	}
 
 	mockedDB := &mDB.Store{}
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
 	mockedGitLab := &mGitLab.GitLab{}
 	mockedSlack := &mSlack.Slack{}
 
@@ -395,9 +502,10 @@ func TestTagPushChannelOverwrite(t *testing.T) {
 	var nilUser *model.User
 	var nilAtm *slack.Attachment
 	mockedGitLab.On("GetTagList", fakeData["ProjectID"].(int)).Return(mockedTagList, nil)
+	mockedGitLab.On("GetCompare", fakeData["ProjectID"].(int), mock.Anything, mock.Anything).Return(&gitlab.Compare{}, nil)
 	mockedDB.On("GetUserByID", fakeData["UserID"].(int)).Return(mockedAuthor, nil)
 	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(mockedProject, nil)
-	mockedSlack.On("PostSlackMessage", "fake-channel", slackExpected.String(), nilUser, nilAtm).Return(nil, nil)
+	mockedSlack.On("PostSlackBlocks", "fake-channel", slackExpected.String(), nilUser, nilAtm, mock.Anything).Return(nil, nil)
 
 	w := &hook{
 		db: mockedDB,
@@ -411,5 +519,99 @@ func TestTagPushChannelOverwrite(t *testing.T) {
 	mockedDB.AssertNumberOfCalls(t, "GetUserByID", 1)
 	mockedDB.AssertNotCalled(t, "GetProjectByID")
 	mockedGitLab.AssertNumberOfCalls(t, "GetTagList", 1)
-	mockedSlack.AssertNumberOfCalls(t, "PostSlackMessage", 1)
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackBlocks", 1)
+}
+
+func TestTagPushWithChangelog(t *testing.T) {
+	fakeData := map[string]interface{}{
+		"SHA":       "fake-checkout-sha",
+		"Message":   "fake-message",
+		"UserID":    1,
+		"ProjectID": 999,
+		"Path":      "fake/fake-gitlab-project",
+	}
+
+	mockedDB := &mDB.Store{}
+	mockedDB.On("ListTemplates", mock.Anything, mock.Anything).Return([]*model.Template{}, nil)
+	mockedDB.On("ListRoutes", mock.Anything).Return([]*model.Route{}, nil)
+	mockedGitLab := &mGitLab.GitLab{}
+	mockedSlack := &mSlack.Slack{}
+
+	// the hotfix is pushed after a newer major version
+	mockedTagList := []*gitlab.Tag{
+		&gitlab.Tag{Name: "v1.2.1"},
+		&gitlab.Tag{Name: "v2.0.0"},
+		&gitlab.Tag{Name: "v1.2.0"},
+		&gitlab.Tag{Name: "v1.2.0-rc.1"},
+	}
+	mockedCompare := &gitlab.Compare{
+		Commits: []*gitlab.Commit{
+			&gitlab.Commit{Title: "feat(api): fake feature", AuthorName: "fake-name-1", AuthorEmail: "fake-1@fake.com"},
+			&gitlab.Commit{Title: "fix: fake bug", AuthorName: "fake-name-2", AuthorEmail: "fake-2@fake.com"},
+			&gitlab.Commit{
+				Title:       "Merge branch 'fake-branch' into 'master'",
+				Message:     "Merge branch 'fake-branch' into 'master'\n\nfeat: fake merge request\n\nSee merge request fake/fake-gitlab-project!3",
+				AuthorName:  "fake-name-1",
+				AuthorEmail: "fake-1@fake.com",
+			},
+			&gitlab.Commit{Title: "fake other change", AuthorName: "fake-name-2", AuthorEmail: "fake-2@fake.com"},
+		},
+	}
+	mockedAuthor := &model.User{
+		SlackID: "fake-author-slack-id",
+	}
+	var nilUser *model.User
+	mockedGitLab.On("GetTagList", fakeData["ProjectID"].(int)).Return(mockedTagList, nil)
+	mockedGitLab.On("GetCompare", fakeData["ProjectID"].(int), "v1.2.0", "v1.2.1").Return(mockedCompare, nil)
+	mockedDB.On("GetUserByID", fakeData["UserID"].(int)).Return(mockedAuthor, nil)
+	mockedDB.On("GetProjectByID", fakeData["ProjectID"].(int)).Return(&model.Project{}, nil)
+	mockedDB.On("GetUserByEmail", "fake-1").Return(&model.User{SlackID: "fake-slack-id-1"}, nil)
+	mockedDB.On("GetUserByEmail", "fake-2").Return(nilUser, errors.New("sql: no rows in result set"))
+
+	expected := &slack.Attachment{
+		Color: slack.AttachmentColor,
+		Title: "Changes since v1.2.0",
+		Text: "*Features*\n" +
+			"• *api:* fake feature\n" +
+			"• fake merge request (<http://fake.com/fake/fake-gitlab-project/merge_requests/3|!3>)\n\n" +
+			"*Bug Fixes*\n" +
+			"• fake bug\n\n" +
+			"*Other Changes*\n" +
+			"• fake other change\n\n" +
+			"Committers: <@fake-slack-id-1>, fake-name-2",
+	}
+	mockedSlack.On("PostSlackBlocks", "general", mock.Anything, nilUser, expected, mock.Anything).Return(nil, nil)
+
+	w := &hook{
+		db: mockedDB,
+		g:  mockedGitLab,
+		s:  mockedSlack,
+	}
+
+	body := strings.Replace(renderTemplate(tagPushBodyTemplate, fakeData).String(), "fake-tag-name", "v1.2.1", 1)
+	w.TagPushEvent([]byte(body))
+
+	mockedGitLab.AssertNumberOfCalls(t, "GetCompare", 1)
+	mockedDB.AssertNumberOfCalls(t, "GetUserByEmail", 2)
+	mockedSlack.AssertNumberOfCalls(t, "PostSlackBlocks", 1)
+}
+
+func TestPreviousVersion(t *testing.T) {
+	var tags []*gitlab.Tag
+	for _, name := range []string{"v1.2.1", "v2.0.0", "v1.10.0", "v1.2.0", "v1.2.0-rc.2", "v1.2.0-rc.10", "api-v1.1.0", "latest"} {
+		tags = append(tags, &gitlab.Tag{Name: name})
+	}
+	input := map[string]string{
+		"v1.2.1":       "v1.2.0",
+		"v2.0.0":       "v1.10.0",
+		"v1.10.0":      "v1.2.1",
+		"v1.2.0":       "v1.2.0-rc.10",
+		"v1.2.0-rc.10": "v1.2.0-rc.2",
+		"api-v1.2.0":   "api-v1.1.0",
+		"v1.0.0":       "",
+		"latest":       "",
+	}
+	for tagName, expected := range input {
+		assert.Equal(t, expected, previousVersion(tags, tagName), tagName)
+	}
 }
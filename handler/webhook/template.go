@@ -0,0 +1,147 @@
+package webhook
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/sirupsen/logrus"
+)
+
+// events of which the message text can be overridden by the template of project or group
+const (
+	TemplateMergeRequest = "merge_request"
+	TemplateIssue        = "issue"
+	TemplateTagPush      = "tag_push"
+	TemplateComment      = "comment"
+	TemplatePush         = "push"
+	TemplateRelease      = "release"
+)
+
+// defaultTemplates are used if neither project nor its groups have a template of event
+var defaultTemplates = map[string]string{
+	TemplateMergeRequest: mrTemplate,
+	TemplateIssue:        issueTemplate,
+	TemplateTagPush:      tagPushTemplate,
+	TemplateComment:      commentTemplate,
+	TemplatePush:         pushTemplate,
+	TemplateRelease:      releaseTemplate,
+}
+
+// sampleData is rendered with the template before it's saved, it has the same keys as the real data
+var sampleData = map[string]map[string]interface{}{
+	TemplateMergeRequest: {
+		"Assignee": "U0000000002",
+		"Author":   "U0000000001",
+		"Path":     "group/project",
+		"Title":    "Add feature",
+		"Source":   "feature",
+		"Target":   "master",
+		"Link":     "https://gitlab.com/group/project/merge_requests/1",
+		"MRNum":    1,
+	},
+	TemplateIssue: {
+		"Author":   "U0000000001",
+		"Path":     "group/project",
+		"Link":     "https://gitlab.com/group/project/issues/1",
+		"IssueNum": 1,
+	},
+	TemplateTagPush: {
+		"Author": "U0000000001",
+		"Tag":    "v1.0.0",
+		"Path":   "group/project",
+		"Note":   "Release note",
+		"Link":   "https://gitlab.com/group/project/tags/v1.0.0",
+	},
+	TemplateComment: {
+		"Author": "Author",
+		"Link":   "https://gitlab.com/group/project/issues/1#note_1",
+		"Desc":   "Comment",
+	},
+	TemplatePush: {
+		"Author": "U0000000001",
+		"Count":  1,
+		"Branch": "master",
+		"Path":   "group/project",
+		"Link":   "https://gitlab.com/group/project/compare/1111111...2222222",
+		"Commits": []map[string]string{
+			{"ShortID": "22222222", "Title": "Fix bug", "URL": "https://gitlab.com/group/project/commit/2222222"},
+		},
+		"More": 0,
+	},
+	TemplateRelease: {
+		"Author":  "U0000000001",
+		"Name":    "Version 1.0.0",
+		"Link":    "https://gitlab.com/group/project/releases/v1.0.0",
+		"Path":    "group/project",
+		"Tag":     "v1.0.0",
+		"TagLink": "https://gitlab.com/group/project/tags/v1.0.0",
+	},
+}
+
+// DefaultTemplate returns the built-in template of event, false if event is unknown
+func DefaultTemplate(event string) (string, bool) {
+	t, ok := defaultTemplates[event]
+	return t, ok
+}
+
+// ValidateTemplate parses text and renders it with sample data of event,
+// referring to a key which doesn't exist is an error
+func ValidateTemplate(event, text string) error {
+	data, ok := sampleData[event]
+	if !ok {
+		return fmt.Errorf("unknown event: %q", event)
+	}
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("empty template")
+	}
+	_, err := execute(text, data)
+	return err
+}
+
+// TemplatePaths returns path and its parent groups, the nearest comes first
+func TemplatePaths(path string) []string {
+	var paths []string
+	for path != "" {
+		paths = append(paths, path)
+		i := strings.LastIndex(path, "/")
+		if i < 0 {
+			break
+		}
+		path = path[:i]
+	}
+	return paths
+}
+
+// render renders the message text of event for project at path, custom is true if it's rendered by the template
+// of project or group, which is shown in the blocks too, the default template is used if that template is broken
+func (h *hook) render(event, path string, data map[string]interface{}) (text string, custom bool, err error) {
+	templates, err := h.db.ListTemplates(event, TemplatePaths(path))
+	if err == nil && len(templates) != 0 {
+		text, err := execute(templates[0].Text, data)
+		if err == nil {
+			return text, true, nil
+		}
+		logrus.Warnf("template of %v for %v ignored: %v", event, templates[0].Path, err)
+	}
+	text, err = execute(defaultTemplates[event], data)
+	if err != nil {
+		logrus.Errorln(err)
+		return "", false, err
+	}
+	return text, false, nil
+}
+
+func execute(text string, data map[string]interface{}) (string, error) {
+	t, err := template.New("slack").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	err = t.Execute(buf, data)
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"testing"
+
+	"gitlack/model"
+
+	"github.com/stretchr/testify/assert"
+
+	mDB "gitlack/store/mocks"
+)
+
+func TestValidateTemplate(t *testing.T) {
+	input := map[string]bool{
+		"<@{{.Author}}> opened {{.Path}}#{{.IssueNum}}": true,
+		"{{if .Author}}{{.Author}}{{end}}":              true,
+		"":                                              false,
+		"{{.Author":                                     false,
+		"{{.Assignee}}":                                 false,
+	}
+	for tmpl, valid := range input {
+		err := ValidateTemplate(TemplateIssue, tmpl)
+		assert.Equal(t, valid, err == nil, "template %q, err: %v", tmpl, err)
+	}
+}
+
+func TestValidateTemplateDefault(t *testing.T) {
+	for event, tmpl := range defaultTemplates {
+		assert.Nil(t, ValidateTemplate(event, tmpl), "default template of %v should be valid", event)
+	}
+	assert.NotNil(t, ValidateTemplate("fake-event", "fake-text"))
+}
+
+func TestTemplatePaths(t *testing.T) {
+	assert.Equal(t, []string{"a/b/c", "a/b", "a"}, TemplatePaths("a/b/c"))
+	assert.Equal(t, []string{"a"}, TemplatePaths("a"))
+	assert.Nil(t, TemplatePaths(""))
+}
+
+func TestRenderWithTemplate(t *testing.T) {
+	mockedDB := &mDB.Store{}
+	mockedDB.On("ListTemplates", TemplateComment, []string{"fake/project", "fake"}).Return([]*model.Template{
+		{Path: "fake", Event: TemplateComment, Text: "{{.Author}}: {{.Desc}}"},
+	}, nil)
+	w := &hook{db: mockedDB}
+
+	text, custom, err := w.render(TemplateComment, "fake/project", map[string]interface{}{
+		"Author": "fake-author",
+		"Link":   "fake-link",
+		"Desc":   "fake-desc",
+	})
+
+	assert.Nil(t, err)
+	assert.True(t, custom)
+	assert.Equal(t, "fake-author: fake-desc", text)
+}
+
+func TestRenderBrokenTemplateFallback(t *testing.T) {
+	mockedDB := &mDB.Store{}
+	mockedDB.On("ListTemplates", TemplateComment, []string{"fake"}).Return([]*model.Template{
+		{Path: "fake", Event: TemplateComment, Text: "{{.Unknown}}"},
+	}, nil)
+	w := &hook{db: mockedDB}
+	data := map[string]interface{}{
+		"Author": "fake-author",
+		"Link":   "fake-link",
+		"Desc":   "fake-desc",
+	}
+
+	text, custom, err := w.render(TemplateComment, "fake", data)
+
+	assert.Nil(t, err)
+	assert.False(t, custom)
+	assert.Equal(t, renderTemplate(commentTemplate, data).String(), text)
+}
@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"time"
+
+	"gitlack/model"
+)
+
+// kinds of mirror thread, which is started by the copy of the first message in another routed channel
+const (
+	kindMergeRequestMirror = "mr_mirror"
+	kindIssueMirror        = "issue_mirror"
+)
+
+// mirrorKinds maps the kind of object to the kind of its mirror threads
+var mirrorKinds = map[string]string{
+	kindMergeRequest: kindMergeRequestMirror,
+	kindIssue:        kindIssueMirror,
+}
+
+// mirrors returns the mirror threads of merge request or issue, none if they can't be read
+func (h *hook) mirrors(kind string, projectID, num int) []*model.Thread {
+	threads, err := h.db.ListThreads(kind, projectID, num)
+	if err != nil {
+		return nil
+	}
+	return threads
+}
+
+// pendingThread returns the channel of merge request or issue whose root message is still in outbox,
+// the replies to it are held in outbox until the thread is started
+func (h *hook) pendingThread(kind string, projectID, num int) (string, error) {
+	root, err := h.db.GetPendingOutboxMessage(kind, projectID, num)
+	if err != nil {
+		return "", err
+	}
+	return root.Channel, nil
+}
+
+// threads returns the thread message is posted into followed by its mirror threads
+func (m *message) threads() []*model.Thread {
+	return append([]*model.Thread{{Channel: m.channel, ThreadTS: m.threadTS}}, m.mirrors...)
+}
+
+// postCopies posts message to the rest of routed channels and into the mirror threads,
+// the copy of the first message of merge request or issue starts a mirror thread
+func (h *hook) postCopies(m *message) {
+	for _, channel := range m.copies {
+		c := *m
+		c.channel = channel
+		c.copies = nil
+		c.event = nil
+		c.objectKind = mirrorKinds[m.objectKind]
+		// the thread is recorded by outbox dispatcher if Slack fails
+		res, err := h.post(&c)
+		if err == nil {
+			h.saveThread(c.objectKind, c.projectID, c.objectNum, c.text, c.blocks, res)
+		}
+	}
+	for _, t := range m.mirrors {
+		c := *m
+		c.channel = t.Channel
+		c.threadTS = t.ThreadTS
+		c.threadKind = ""
+		c.mirrors = nil
+		h.post(&c)
+	}
+}
+
+// saveMirror records the mirror thread of which the kind is mirrorKind
+func (h *hook) saveMirror(mirrorKind string, projectID, num int, channel, ts string) {
+	for kind, k := range mirrorKinds {
+		if k != mirrorKind {
+			continue
+		}
+		h.db.CreateThread(&model.Thread{
+			ProjectID:  projectID,
+			ObjectKind: kind,
+			ObjectNum:  num,
+			Channel:    channel,
+			ThreadTS:   ts,
+			CreatedAt:  time.Now(),
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"testing"
+
+	"gitlack/model"
+	"gitlack/resource/slack"
+
+	"github.com/stretchr/testify/mock"
+
+	mSlack "gitlack/resource/slack/mocks"
+	mDB "gitlack/store/mocks"
+)
+
+func TestPostCopiesStartsMirrorThread(t *testing.T) {
+	var nilUser *model.User
+	var nilAtm *slack.Attachment
+	mockedDB := &mDB.Store{}
+	mockedDB.On("CreateThread", mock.MatchedBy(func(th *model.Thread) bool {
+		return th.ProjectID == 1 && th.ObjectKind == kindMergeRequest && th.ObjectNum == 2 &&
+			th.Channel == "fake-platform-id" && th.ThreadTS == "fake-ts" && !th.CreatedAt.IsZero()
+	})).Return(nil)
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("PostSlackMessage", "fake-platform", "fake-text", nilUser, nilAtm).
+		Return(&slack.MessageResponse{OK: true, Channel: "fake-platform-id", TS: "fake-ts"}, nil)
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+
+	w.postCopies(&message{
+		channel:    "fake-team",
+		copies:     []string{"fake-platform"},
+		text:       "fake-text",
+		projectID:  1,
+		objectKind: kindMergeRequest,
+		objectNum:  2,
+	})
+
+	mockedDB.AssertExpectations(t)
+	mockedDB.AssertNotCalled(t, "CreateMergeRequest", mock.Anything)
+}
+
+func TestDeactiveMRMirrorThreads(t *testing.T) {
+	// prepare fake input
+	fakeData := getMRFakeData()
+	fakeData["Action"] = "merge"
+	mockedMR := &model.MergeRequest{
+		ProjectID:       fakeData["ProjectID"].(int),
+		MergeRequestNum: fakeData["ObjectNum"].(int),
+		ThreadTS:        "fake-ts",
+		Channel:         "fake-team",
+		Text:            "fake-text",
+	}
+	mirror := &model.Thread{Channel: "fake-platform", ThreadTS: "fake-mirror-ts"}
+
+	var nilUser *model.User
+	var nilAtm *slack.Attachment
+	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateMergeRequestActivity", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockedDB.On("GetMergeRequest", mockedMR.ProjectID, mockedMR.MergeRequestNum).Return(mockedMR, nil)
+	mockedDB.On("ListThreads", kindMergeRequest, mockedMR.ProjectID, mockedMR.MergeRequestNum).Return([]*model.Thread{mirror}, nil)
+	mockedDB.On("UpdateMergeRequest", mockedMR).Return(nil)
+	mockedDB.On("GetProjectByID", mockedMR.ProjectID).Return(&model.Project{}, nil)
+	mockedSlack := &mSlack.Slack{}
+	for _, th := range []*model.Thread{{Channel: mockedMR.Channel, ThreadTS: mockedMR.ThreadTS}, mirror} {
+		mockedSlack.On("PostSlackMessage", th.Channel, "This merge request has been merged.", nilUser, nilAtm, th.ThreadTS).Return(nil, nil).Once()
+		mockedSlack.On("UpdateSlackMessage", th.Channel, th.ThreadTS, "fake-text", mock.Anything, mock.Anything).Return(nil, nil).Once()
+		mockedSlack.On("AddReaction", th.Channel, th.ThreadTS, "white_check_mark").Return(nil).Once()
+	}
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+
+	w.MergeRequestEvent(genMRBody(fakeData))
+
+	mockedSlack.AssertExpectations(t)
+}
+
+func TestDeliverMirrorOutboxMessage(t *testing.T) {
+	msg := &model.OutboxMessage{
+		ID:         1,
+		Channel:    "fake-platform",
+		Text:       "fake-text",
+		ProjectID:  1,
+		ObjectKind: kindIssueMirror,
+		ObjectNum:  2,
+		Status:     model.OutboxPending,
+	}
+	var nilUser *model.User
+	var nilAtm *slack.Attachment
+	mockedDB := &mDB.Store{}
+	mockedDB.On("UpdateOutboxMessage", msg).Return(nil)
+	mockedDB.On("CreateThread", mock.MatchedBy(func(th *model.Thread) bool {
+		return th.ObjectKind == kindIssue && th.ObjectNum == 2 && th.ThreadTS == "fake-ts"
+	})).Return(nil)
+	mockedSlack := &mSlack.Slack{}
+	mockedSlack.On("PostSlackMessage", "fake-platform", "fake-text", nilUser, nilAtm).
+		Return(&slack.MessageResponse{OK: true, Channel: "fake-platform-id", TS: "fake-ts"}, nil)
+	w := &hook{
+		db: mockedDB,
+		s:  mockedSlack,
+	}
+
+	w.deliver(msg)
+
+	mockedDB.AssertExpectations(t)
+	mockedDB.AssertNotCalled(t, "CreateIssue", mock.Anything)
+}
@@ -1,22 +1,45 @@
 package webhook
 
 import (
+	"fmt"
+	"strings"
+
 	"gitlack/resource/gitlab"
 	"gitlack/resource/slack"
 	"gitlack/store"
 )
 
 type Webhook interface {
-	MergeRequestEvent([]byte)
-	TagPushEvent([]byte)
-	IssuesEvent([]byte)
-	CommentsEvent([]byte)
+	MergeRequestEvent([]byte) error
+	TagPushEvent([]byte) error
+	IssuesEvent([]byte) error
+	CommentsEvent([]byte) error
+	PipelineEvent([]byte) error
+	PushEvent([]byte) error
+	ReleaseEvent([]byte) error
+	DispatchOutbox()
+	DispatchDigests()
+	DispatchReviewDigests()
+	DispatchStaleReminders()
+	MergeRequestAction(*Action)
+	SlackReply(*Reply)
+	Preview(string, []byte) (*Preview, error)
+	ExplainRoute(*RouteTarget) (*Route, error)
 }
 
 type hook struct {
 	db store.Store
 	g  gitlab.GitLab
 	s  slack.Slack
+
+	// dispatching is set while DispatchOutbox is running
+	dispatching int32
+	// digesting is set while DispatchDigests is running
+	digesting int32
+	// reviewing is set while DispatchReviewDigests is running
+	reviewing int32
+	// staling is set while DispatchStaleReminders is running
+	staling int32
 }
 
 func NewWebhook(db store.Store, g gitlab.GitLab, s slack.Slack) Webhook {
@@ -26,3 +49,34 @@ func NewWebhook(db store.Store, g gitlab.GitLab, s slack.Slack) Webhook {
 		s:  s,
 	}
 }
+
+// skipError is the error of event which isn't announced on purpose, the event doesn't fail by it
+type skipError struct {
+	reason string
+}
+
+func (e *skipError) Error() string {
+	return e.reason
+}
+
+// skip returns the error skipping the event for the reason
+func skip(format string, a ...interface{}) error {
+	return &skipError{reason: fmt.Sprintf(format, a...)}
+}
+
+// failure returns err unless it skips the event
+func failure(err error) error {
+	if _, ok := err.(*skipError); ok {
+		return nil
+	}
+	return err
+}
+
+// missingThread skips the event if the thread of its merge request or issue isn't found,
+// which Gitlack never announced, other errors of store fail the event
+func missingThread(err error) error {
+	if strings.Contains(err.Error(), "sql: no rows in result set") {
+		return skip("thread isn't found")
+	}
+	return err
+}
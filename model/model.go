@@ -1,10 +1,21 @@
 package model
 
+import "time"
+
 // Project is the model of GitLab project
 type Project struct {
-	ID             int    `db:"id"`
-	Name           string `db:"name"`
-	DefaultChannel string `db:"default_channel"`
+	ID              int    `db:"id"`
+	Name            string `db:"name"`
+	DefaultChannel  string `db:"default_channel"`
+	WebhookSecret   string `db:"webhook_secret" json:"-"`
+	WatchedBranches string `db:"watched_branches"`
+	Reactions       string `db:"reactions"`
+	// open merge requests without activity for StaleAfter hours, weekends not counted, are reminded in their thread,
+	// and escalated to EscalateTo after EscalateAfter hours, zero turns either off
+	StaleAfter    int `db:"stale_after"`
+	EscalateAfter int `db:"escalate_after"`
+	// EscalateTo is a channel ID or the email of a user who gets a direct message, the default channel if empty
+	EscalateTo string `db:"escalate_to"`
 }
 
 // User is the model of user
@@ -15,6 +26,42 @@ type User struct {
 	Name           string `db:"name"`
 	AvatarURL      string `db:"avatar_url"`
 	DefaultChannel string `db:"default_channel"`
+	// Delivery is where user is mentioned, one of channel, dm or both
+	Delivery string `db:"delivery"`
+	// MutedEvents and MutedProjects are the comma-separated mention events and project or group paths user isn't mentioned for
+	MutedEvents   string `db:"muted_events"`
+	MutedProjects string `db:"muted_projects"`
+	// mentions between QuietStart and QuietEnd, in `15:04` of TimeZone, are queued and sent as a digest afterwards
+	TimeZone   string `db:"time_zone"`
+	QuietStart string `db:"quiet_start"`
+	QuietEnd   string `db:"quiet_end"`
+}
+
+// Where user is mentioned
+const (
+	DeliveryChannel = "channel"
+	DeliveryDM      = "dm"
+	DeliveryBoth    = "both"
+)
+
+// Events user is mentioned for
+const (
+	MentionMRAssigned      = "mr_assigned"
+	MentionReviewRequested = "review_requested"
+	MentionCommentOnMyMR   = "comment_on_my_mr"
+	MentionPipelineFailed  = "pipeline_failed_on_my_mr"
+	MentionIssueAssigned   = "issue_assigned"
+	MentionStaleMR         = "stale_mr"
+)
+
+// MentionEvents are all events user is mentioned for
+var MentionEvents = []string{
+	MentionMRAssigned,
+	MentionReviewRequested,
+	MentionCommentOnMyMR,
+	MentionPipelineFailed,
+	MentionIssueAssigned,
+	MentionStaleMR,
 }
 
 // MergeRequest is the model of GitLab merge request
@@ -24,6 +71,15 @@ type MergeRequest struct {
 	MergeRequestNum int    `db:"mr_num"`
 	ThreadTS        string `db:"thread_ts"`
 	Channel         string `db:"channel"`
+	Text            string `db:"text"`
+	State           string `db:"state"`
+	PipelineStatus  string `db:"pipeline_status"`
+	Blocks          string `db:"blocks"`
+	// LastActivityAt is zero for the merge requests recorded before activity is tracked,
+	// reminders and escalations earlier than LastActivityAt are of the previous activity
+	LastActivityAt time.Time `db:"last_activity_at"`
+	RemindedAt     time.Time `db:"reminded_at"`
+	EscalatedAt    time.Time `db:"escalated_at"`
 }
 
 // Issue is the model of GitLab issue
@@ -34,3 +90,127 @@ type Issue struct {
 	ThreadTS  string `db:"thread_ts"`
 	Channel   string `db:"channel"`
 }
+
+// Thread is the model of mirror thread of merge request or issue in another channel,
+// the thread in the channel of MergeRequest or Issue is the primary one
+type Thread struct {
+	ID         int       `db:"id"`
+	ProjectID  int       `db:"project_id"`
+	ObjectKind string    `db:"object_kind"`
+	ObjectNum  int       `db:"object_num"`
+	Channel    string    `db:"channel"`
+	ThreadTS   string    `db:"thread_ts"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+// SlackNote is the model of GitLab note created from the reply in Slack thread, its comment event isn't posted back,
+// Marker is written in the note body before the note is created, AuthorID is the GitLab ID of the note author
+type SlackNote struct {
+	Marker    string    `db:"marker"`
+	ProjectID int       `db:"project_id"`
+	AuthorID  int       `db:"author_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// ReviewDigest is the model of the schedule posting merge requests awaiting review in channel,
+// Schedule is a cron spec or descriptor such as `@daily`
+type ReviewDigest struct {
+	Channel   string    `db:"channel" json:"channel"`
+	Schedule  string    `db:"schedule" json:"schedule"`
+	LastRunAt time.Time `db:"last_run_at" json:"last_run_at"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// QueuedNotification is the model of notification to user during quiet hours, waiting to be sent in a digest
+type QueuedNotification struct {
+	ID        int       `db:"id"`
+	GitLabID  int       `db:"gitlab_id"`
+	Text      string    `db:"text"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// OutboxMessage is the model of Slack message waiting to be delivered,
+// ThreadKind is set instead of ThreadTS if it replies to the thread of merge request or issue which isn't started yet,
+// Event is the webhook event of the root message, its follow-ups are sent once it's delivered
+type OutboxMessage struct {
+	ID            int       `db:"id"`
+	Channel       string    `db:"channel"`
+	Text          string    `db:"text"`
+	AuthorID      int       `db:"author_id"`
+	Attachment    string    `db:"attachment"`
+	Blocks        string    `db:"blocks"`
+	ThreadTS      string    `db:"thread_ts"`
+	ProjectID     int       `db:"project_id"`
+	ObjectKind    string    `db:"object_kind"`
+	ObjectNum     int       `db:"object_num"`
+	ThreadKind    string    `db:"thread_kind"`
+	Event         string    `db:"event"`
+	Status        string    `db:"status"`
+	Attempts      int       `db:"attempts"`
+	NextAttemptAt time.Time `db:"next_attempt_at"`
+	LastError     string    `db:"last_error"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+// status of OutboxMessage
+const (
+	OutboxPending = "pending"
+	OutboxSent    = "sent"
+	OutboxFailed  = "failed"
+)
+
+// Template is the model of message template of project or group, path is the full path of either
+type Template struct {
+	ID        int       `db:"id"`
+	Path      string    `db:"path"`
+	Event     string    `db:"event"`
+	Text      string    `db:"text"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// Route is the model of channel routing rule of project or group, path is the full path of either,
+// rules are tried in order of position and every non-empty condition has to match,
+// labels, file paths, authors and channels are comma-separated, branches are comma-separated globs
+type Route struct {
+	ID           int       `db:"id" json:"-"`
+	Path         string    `db:"path" json:"path"`
+	Position     int       `db:"position" json:"position"`
+	Event        string    `db:"event" json:"event"`
+	Labels       string    `db:"labels" json:"labels"`
+	SourceBranch string    `db:"source_branch" json:"source_branch"`
+	TargetBranch string    `db:"target_branch" json:"target_branch"`
+	FilePaths    string    `db:"file_paths" json:"file_paths"`
+	Authors      string    `db:"authors" json:"authors"`
+	Channels     string    `db:"channels" json:"channels"`
+	CreatedAt    time.Time `db:"created_at" json:"-"`
+}
+
+// WebhookEvent is the model of GitLab webhook request waiting to be processed
+type WebhookEvent struct {
+	ID          int        `db:"id"`
+	Event       string     `db:"event"`
+	ProjectID   int        `db:"project_id"`
+	ObjectKey   string     `db:"object_key"`
+	UUID        string     `db:"uuid"`
+	Headers     string     `db:"headers"`
+	Payload     string     `db:"payload"`
+	Status      string     `db:"status"`
+	Error       string     `db:"error"`
+	CreatedAt   time.Time  `db:"created_at"`
+	ProcessedAt *time.Time `db:"processed_at"`
+}
+
+// WebhookEventFilter is the condition of searching WebhookEvent, empty fields match all
+type WebhookEventFilter struct {
+	ProjectID int
+	Event     string
+	Status    string
+	Limit     int
+}
+
+// status of WebhookEvent
+const (
+	EventQueued = "queued"
+	EventDone   = "done"
+	EventFailed = "failed"
+)
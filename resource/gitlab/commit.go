@@ -36,3 +36,34 @@ func (g *gitlab) GetSingleCommit(id int, sha string) (*Commit, error) {
 	}
 	return &commit, nil
 }
+
+// ListCommitMergeRequests returns the merge requests of project id which the commit sha belongs to,
+// including the ones it's the merge or squash commit of
+func (g *gitlab) ListCommitMergeRequests(id int, sha string) ([]*MergeRequest, error) {
+	url := g.GitLabAPI + fmt.Sprintf("/projects/%v/repository/commits/%v/merge_requests", id, sha)
+	params := map[string]string{
+		"private_token": g.GitLabToken,
+	}
+	res, err := g.client.Get(url, nil, params, nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		logrus.Errorln(err)
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		err := fmt.Errorf("Invalid GitLab API error: %v", string(body))
+		logrus.Errorln(err)
+		return nil, err
+	}
+	var mrs []*MergeRequest
+	err = json.Unmarshal(body, &mrs)
+	if err != nil {
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return mrs, nil
+}
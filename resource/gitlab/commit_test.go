@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestGetSingleCommitWithCommit(t *testing.T) {
@@ -45,3 +46,19 @@ func TestGetSingleCommitInvalidGitLabAPI(t *testing.T) {
 	assert.NotNil(t, err, "Error should not be nil")
 	assert.Equal(t, "Invalid GitLab API error: fake-body", err.Error(), "Error message should be equal")
 }
+
+func TestListCommitMergeRequests(t *testing.T) {
+	// arrange
+	stubByte := []byte(`[{"iid": 1, "target_branch": "main", "merge_commit_sha": "fake-merge-sha", "squash_commit_sha": null}]`)
+	stubClient := getGetClientWithResponse(stubByte, http.StatusOK, "")
+	g := getGitLab(stubClient)
+
+	// act
+	actual, err := g.ListCommitMergeRequests(1, "fake-merge-sha")
+
+	// assert
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, 1, len(actual), "Number of merge requests should be equal")
+	assert.Equal(t, "fake-merge-sha", actual[0].MergeCommitSHA, "Merge commit should be equal")
+	stubClient.AssertCalled(t, "Get", "/projects/1/repository/commits/fake-merge-sha/merge_requests", mock.Anything, mock.Anything, mock.Anything)
+}
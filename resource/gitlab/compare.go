@@ -0,0 +1,41 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/sirupsen/logrus"
+)
+
+// GetCompare returns the commits between two refs, from the older one to the newer one
+func (g *gitlab) GetCompare(id int, from, to string) (*Compare, error) {
+	url := g.GitLabAPI + fmt.Sprintf("/projects/%v/repository/compare", id)
+	params := map[string]string{
+		"private_token": g.GitLabToken,
+		"from":          from,
+		"to":            to,
+	}
+	res, err := g.client.Get(url, nil, params, nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		logrus.Errorln(err)
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		err := fmt.Errorf("Invalid GitLab API error: %v", string(body))
+		logrus.Errorln(err)
+		return nil, err
+	}
+	var compare Compare
+	err = json.Unmarshal(body, &compare)
+	if err != nil {
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return &compare, nil
+}
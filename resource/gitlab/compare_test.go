@@ -0,0 +1,73 @@
+package gitlab
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetCompareWithFiveCommits(t *testing.T) {
+	// arrange
+	stubByte, expected := getCommitResponse(5)
+	stubClient := getGetClientWithResponse(stubByte, http.StatusOK, "")
+	g := getGitLab(stubClient)
+
+	// act
+	actual, _ := g.GetCompare(1, "fake-from", "fake-to")
+
+	// assert
+	assert.Equal(t, 5, len(actual.Commits), "Number of commits should be equal")
+	assert.Equal(t, expected, *actual, "Compare's content should be equal")
+}
+
+func TestGetCompareParams(t *testing.T) {
+	// arrange
+	stubByte, _ := getCommitResponse(1)
+	stubClient := getClient()
+	stubClient.On(
+		"Get",
+		"/projects/1/repository/compare",
+		mock.Anything,
+		map[string]string{
+			"private_token": "",
+			"from":          "fake-from",
+			"to":            "fake-to",
+		},
+		mock.Anything).Return(getResponse(stubByte, http.StatusOK, nil), nil)
+	g := getGitLab(stubClient)
+
+	// act
+	_, err := g.GetCompare(1, "fake-from", "fake-to")
+
+	// assert
+	assert.Nil(t, err, "Error should be nil")
+	stubClient.AssertNumberOfCalls(t, "Get", 1)
+}
+
+func TestGetCompareRequestError(t *testing.T) {
+	// arrange
+	stubClient := getGetClientWithError("fake-error")
+	g := getGitLab(stubClient)
+
+	// act
+	_, err := g.GetCompare(1, "fake-from", "fake-to")
+
+	// assert
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "fake-error", err.Error(), "Error message should be equal")
+}
+
+func TestGetCompareInvalidGitLabAPI(t *testing.T) {
+	// arrange
+	stubClient := getGetClientWithResponse([]byte(`fake-body`), http.StatusNotFound, "")
+	g := getGitLab(stubClient)
+
+	// act
+	_, err := g.GetCompare(1, "fake-from", "fake-to")
+
+	// assert
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "Invalid GitLab API error: fake-body", err.Error(), "Error message should be equal")
+}
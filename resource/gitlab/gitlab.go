@@ -11,9 +11,20 @@ import (
 
 type GitLab interface {
 	GetProject() ([]*model.Project, error)
+	GetProjectAccessLevel(int, int) (int, error)
 	GetUser() ([]*GitLabUser, error)
 	GetTagList(int) ([]*Tag, error)
 	GetSingleCommit(int, string) (*Commit, error)
+	ListCommitMergeRequests(int, string) ([]*MergeRequest, error)
+	GetCompare(int, string, string) (*Compare, error)
+	GetMergeRequestChanges(int, int) ([]string, error)
+	GetMergeRequest(int, int) (*MergeRequest, error)
+	ListOpenMergeRequestsBySourceBranch(int, string) ([]*MergeRequest, error)
+	ApproveMergeRequest(int, int, int) error
+	MergeMergeRequest(int, int, int) (*MergeRequest, error)
+	CloseMergeRequest(int, int, int) error
+	CreateMergeRequestNote(int, int, int, string) error
+	CreateIssueNote(int, int, int, string) error
 }
 
 type gitlab struct {
@@ -0,0 +1,299 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrForbidden is returned if the user isn't allowed to act on the merge request
+var ErrForbidden = errors.New("forbidden by GitLab")
+
+// ErrAlreadyApproved is returned if the user has approved the merge request already
+var ErrAlreadyApproved = errors.New("already approved")
+
+// ErrNotFound is returned if the merge request or its project doesn't exist in GitLab
+var ErrNotFound = errors.New("not found in GitLab")
+
+// ErrSudoDenied is returned if the token isn't allowed to act as the user, it needs an administrator with sudo scope
+var ErrSudoDenied = errors.New("sudo denied by GitLab")
+
+// Change is the data structure of a changed file of merge request
+type Change struct {
+	OldPath string `json:"old_path"`
+	NewPath string `json:"new_path"`
+}
+
+// MergeRequest is the data structure of merge request
+type MergeRequest struct {
+	IID          int           `json:"iid"`
+	Title        string        `json:"title"`
+	State        string        `json:"state"`
+	WebURL       string        `json:"web_url"`
+	SourceBranch string        `json:"source_branch"`
+	TargetBranch string        `json:"target_branch"`
+	SHA          string        `json:"sha"`
+	CreatedAt    time.Time     `json:"created_at"`
+	Author       GitLabUser    `json:"author"`
+	Assignees    []*GitLabUser `json:"assignees"`
+	Reviewers    []*GitLabUser `json:"reviewers"`
+	HeadPipeline *Pipeline     `json:"head_pipeline"`
+
+	// MergeCommitSHA and SquashCommitSHA are the commits a merged merge request adds to its target branch
+	MergeCommitSHA  string `json:"merge_commit_sha"`
+	SquashCommitSHA string `json:"squash_commit_sha"`
+}
+
+// MergeRequestChanges is the data structure of merge request with its changed files
+type MergeRequestChanges struct {
+	Changes []Change `json:"changes"`
+}
+
+// GetMergeRequestChanges returns the paths of files changed by merge request, renamed files have both paths
+func (g *gitlab) GetMergeRequestChanges(id, iid int) ([]string, error) {
+	url := g.GitLabAPI + fmt.Sprintf("/projects/%v/merge_requests/%v/changes", id, iid)
+	params := map[string]string{
+		"private_token": g.GitLabToken,
+	}
+	res, err := g.client.Get(url, nil, params, nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		logrus.Errorln(err)
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		err := fmt.Errorf("Invalid GitLab API error: %v", string(body))
+		logrus.Errorln(err)
+		return nil, err
+	}
+	var mrChanges MergeRequestChanges
+	err = json.Unmarshal(body, &mrChanges)
+	if err != nil {
+		logrus.Errorln(err)
+		return nil, err
+	}
+
+	var paths []string
+	for _, c := range mrChanges.Changes {
+		paths = append(paths, c.NewPath)
+		if c.OldPath != "" && c.OldPath != c.NewPath {
+			paths = append(paths, c.OldPath)
+		}
+	}
+	return paths, nil
+}
+
+// GetMergeRequest returns the merge request iid of project id
+func (g *gitlab) GetMergeRequest(id, iid int) (*MergeRequest, error) {
+	url := g.GitLabAPI + fmt.Sprintf("/projects/%v/merge_requests/%v", id, iid)
+	params := map[string]string{
+		"private_token": g.GitLabToken,
+	}
+	res, err := g.client.Get(url, nil, params, nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		logrus.Errorln(err)
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		logrus.Infof("GitLab merge request not found: %v", string(body))
+		return nil, ErrNotFound
+	}
+	if res.StatusCode != 200 {
+		err := fmt.Errorf("Invalid GitLab API error: %v", string(body))
+		logrus.Errorln(err)
+		return nil, err
+	}
+	var mr MergeRequest
+	err = json.Unmarshal(body, &mr)
+	if err != nil {
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return &mr, nil
+}
+
+// ListOpenMergeRequestsBySourceBranch returns the open merge requests of project id from branch
+func (g *gitlab) ListOpenMergeRequestsBySourceBranch(id int, branch string) ([]*MergeRequest, error) {
+	url := g.GitLabAPI + fmt.Sprintf("/projects/%v/merge_requests", id)
+	params := map[string]string{
+		"private_token": g.GitLabToken,
+		"state":         "opened",
+		"source_branch": branch,
+	}
+	res, err := g.client.Get(url, nil, params, nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		logrus.Errorln(err)
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 {
+		err := fmt.Errorf("Invalid GitLab API error: %v", string(body))
+		logrus.Errorln(err)
+		return nil, err
+	}
+	var mrs []*MergeRequest
+	err = json.Unmarshal(body, &mrs)
+	if err != nil {
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return mrs, nil
+}
+
+// ApproveMergeRequest approves the merge request iid of project id as the user sudo
+func (g *gitlab) ApproveMergeRequest(id, iid, sudo int) error {
+	url := g.GitLabAPI + fmt.Sprintf("/projects/%v/merge_requests/%v/approve", id, iid)
+	res, err := g.client.Post(url, sudoHeader(sudo), g.tokenParams(), nil)
+	if err != nil {
+		return err
+	}
+	_, err = readActionResponse(res)
+	if err != ErrForbidden || res.StatusCode != http.StatusUnauthorized {
+		return err
+	}
+	// GitLab answers 401 to the user approving again, and to the expired or revoked token as well
+	approved, err := g.approvedBy(id, iid, sudo)
+	if err == ErrForbidden {
+		return ErrSudoDenied
+	}
+	if err != nil {
+		return err
+	}
+	if approved {
+		return ErrAlreadyApproved
+	}
+	return ErrForbidden
+}
+
+// approvedBy reports whether the user of id has approved the merge request iid of project id,
+// ErrForbidden is returned if the token can't read the approvals
+func (g *gitlab) approvedBy(id, iid, userID int) (bool, error) {
+	url := g.GitLabAPI + fmt.Sprintf("/projects/%v/merge_requests/%v/approvals", id, iid)
+	res, err := g.client.Get(url, nil, g.tokenParams(), nil)
+	if err != nil {
+		return false, err
+	}
+	b, err := readActionResponse(res)
+	if err != nil {
+		return false, err
+	}
+	var approvals struct {
+		ApprovedBy []struct {
+			User GitLabUser `json:"user"`
+		} `json:"approved_by"`
+	}
+	err = json.Unmarshal(b, &approvals)
+	if err != nil {
+		logrus.Errorln(err)
+		return false, err
+	}
+	for _, a := range approvals.ApprovedBy {
+		if a.User.ID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// MergeMergeRequest merges the merge request iid of project id as the user sudo when its pipeline succeeds,
+// it's merged at once if the pipeline has succeeded already
+func (g *gitlab) MergeMergeRequest(id, iid, sudo int) (*MergeRequest, error) {
+	url := g.GitLabAPI + fmt.Sprintf("/projects/%v/merge_requests/%v/merge", id, iid)
+	body := map[string]string{
+		"merge_when_pipeline_succeeds": "true",
+	}
+	res, err := g.client.Put(url, sudoHeader(sudo), g.tokenParams(), body)
+	if err != nil {
+		return nil, err
+	}
+	b, err := readActionResponse(res)
+	if err != nil {
+		return nil, err
+	}
+	var mr MergeRequest
+	err = json.Unmarshal(b, &mr)
+	if err != nil {
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return &mr, nil
+}
+
+// CloseMergeRequest closes the merge request iid of project id as the user sudo
+func (g *gitlab) CloseMergeRequest(id, iid, sudo int) error {
+	url := g.GitLabAPI + fmt.Sprintf("/projects/%v/merge_requests/%v", id, iid)
+	body := map[string]string{
+		"state_event": "close",
+	}
+	res, err := g.client.Put(url, sudoHeader(sudo), g.tokenParams(), body)
+	if err != nil {
+		return err
+	}
+	_, err = readActionResponse(res)
+	return err
+}
+
+// sudoHeader acts as the user of id, which needs the token of an administrator with sudo scope
+// ref: https://docs.gitlab.com/ee/api/#sudo
+func sudoHeader(id int) map[string]string {
+	return map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+		"Sudo":         strconv.Itoa(id),
+	}
+}
+
+func (g *gitlab) tokenParams() map[string]string {
+	return map[string]string{
+		"private_token": g.GitLabToken,
+	}
+}
+
+// readActionResponse returns the body of response to the action on merge request,
+// ErrSudoDenied is returned if the token can't act as the user, and ErrForbidden if the user isn't allowed to do it
+func readActionResponse(res *http.Response) ([]byte, error) {
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		logrus.Errorln(err)
+		return nil, err
+	}
+	defer res.Body.Close()
+	switch {
+	case res.StatusCode == http.StatusForbidden && strings.Contains(strings.ToLower(string(body)), "sudo"):
+		logrus.Errorf("GitLab sudo denied: %v", string(body))
+		return nil, ErrSudoDenied
+	case res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden:
+		logrus.Infof("GitLab action forbidden: %v", string(body))
+		return nil, ErrForbidden
+	case res.StatusCode >= 300:
+		var msg struct {
+			Message interface{} `json:"message"`
+		}
+		if json.Unmarshal(body, &msg) != nil || msg.Message == nil {
+			msg.Message = string(body)
+		}
+		err := fmt.Errorf("%v", msg.Message)
+		logrus.Errorf("Invalid GitLab API error: %v", string(body))
+		return nil, err
+	}
+	return body, nil
+}
@@ -0,0 +1,237 @@
+package gitlab
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetMergeRequestChanges(t *testing.T) {
+	// arrange
+	stubByte := []byte(`{"changes": [
+		{"old_path": "docs/a.md", "new_path": "docs/a.md"},
+		{"old_path": "old.go", "new_path": "new.go"}
+	]}`)
+	stubClient := getClient()
+	stubClient.On(
+		"Get",
+		"/projects/1/merge_requests/2/changes",
+		mock.Anything,
+		map[string]string{
+			"private_token": "",
+		},
+		mock.Anything).Return(getResponse(stubByte, http.StatusOK, nil), nil)
+	g := getGitLab(stubClient)
+
+	// act
+	actual, err := g.GetMergeRequestChanges(1, 2)
+
+	// assert
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, []string{"docs/a.md", "new.go", "old.go"}, actual, "Paths should be equal")
+}
+
+func TestGetMergeRequestChangesResponseError(t *testing.T) {
+	// arrange
+	stubClient := getGetClientWithResponse([]byte(`fake-body`), http.StatusNotFound, "")
+	g := getGitLab(stubClient)
+
+	// act
+	_, err := g.GetMergeRequestChanges(1, 2)
+
+	// assert
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "Invalid GitLab API error: fake-body", err.Error(), "Error message should be equal")
+}
+
+func TestGetMergeRequest(t *testing.T) {
+	// arrange
+	stubByte := []byte(`{"iid": 2, "title": "fake-title", "state": "opened", "author": {"id": 3, "name": "fake-author"},
+		"reviewers": [{"id": 4}], "head_pipeline": {"id": 5, "status": "failed"}, "created_at": "2026-10-17T08:00:00.000Z"}`)
+	stubClient := getClient()
+	stubClient.On(
+		"Get",
+		"/projects/1/merge_requests/2",
+		mock.Anything,
+		map[string]string{
+			"private_token": "",
+		},
+		mock.Anything).Return(getResponse(stubByte, http.StatusOK, nil), nil)
+	g := getGitLab(stubClient)
+
+	// act
+	actual, err := g.GetMergeRequest(1, 2)
+
+	// assert
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "fake-title", actual.Title, "Title should be equal")
+	assert.Equal(t, 3, actual.Author.ID, "Author should be equal")
+	assert.Equal(t, 4, actual.Reviewers[0].ID, "Reviewer should be equal")
+	assert.Equal(t, "failed", actual.HeadPipeline.Status, "Pipeline status should be equal")
+	assert.Equal(t, 2026, actual.CreatedAt.Year(), "Created time should be equal")
+}
+
+func TestGetMergeRequestNotFound(t *testing.T) {
+	// arrange
+	stubClient := getGetClientWithResponse([]byte(`{"message": "404 Not found"}`), http.StatusNotFound, "")
+	g := getGitLab(stubClient)
+
+	// act
+	_, err := g.GetMergeRequest(1, 2)
+
+	// assert
+	assert.Equal(t, ErrNotFound, err, "Error should be ErrNotFound")
+}
+
+func TestListOpenMergeRequestsBySourceBranch(t *testing.T) {
+	// arrange
+	stubClient := getClient()
+	stubClient.On(
+		"Get",
+		"/projects/1/merge_requests",
+		mock.Anything,
+		map[string]string{
+			"private_token": "",
+			"state":         "opened",
+			"source_branch": "fake-branch",
+		},
+		mock.Anything).Return(getResponse([]byte(`[{"iid": 2, "sha": "fake-sha"}]`), http.StatusOK, nil), nil)
+	g := getGitLab(stubClient)
+
+	// act
+	actual, err := g.ListOpenMergeRequestsBySourceBranch(1, "fake-branch")
+
+	// assert
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, 1, len(actual), "Number of merge requests should be equal")
+	assert.Equal(t, "fake-sha", actual[0].SHA, "SHA should be equal")
+}
+
+func TestApproveMergeRequest(t *testing.T) {
+	// arrange
+	stubClient := getClient()
+	stubClient.On(
+		"Post",
+		"/projects/1/merge_requests/2/approve",
+		map[string]string{
+			"Content-Type": "application/x-www-form-urlencoded",
+			"Sudo":         "3",
+		},
+		map[string]string{
+			"private_token": "",
+		},
+		mock.Anything).Return(getResponse([]byte(`{}`), http.StatusCreated, nil), nil)
+	g := getGitLab(stubClient)
+
+	// act
+	err := g.ApproveMergeRequest(1, 2, 3)
+
+	// assert
+	assert.Nil(t, err, "Error should be nil")
+}
+
+func TestApproveMergeRequestUnauthorized(t *testing.T) {
+	input := map[string]struct {
+		status   int
+		body     string
+		expected error
+	}{
+		"approved again":   {http.StatusOK, `{"approved_by": [{"user": {"id": 4}}, {"user": {"id": 3}}]}`, ErrAlreadyApproved},
+		"not approved":     {http.StatusOK, `{"approved_by": [{"user": {"id": 4}}]}`, ErrForbidden},
+		"token is revoked": {http.StatusUnauthorized, `{"message": "401 Unauthorized"}`, ErrSudoDenied},
+	}
+	for name, tc := range input {
+		// arrange
+		stubClient := getClient()
+		stubClient.On(
+			"Post",
+			"/projects/1/merge_requests/2/approve",
+			mock.Anything,
+			mock.Anything,
+			mock.Anything).Return(getResponse([]byte(`{"message": "401 Unauthorized"}`), http.StatusUnauthorized, nil), nil)
+		stubClient.On(
+			"Get",
+			"/projects/1/merge_requests/2/approvals",
+			mock.Anything,
+			map[string]string{
+				"private_token": "",
+			},
+			mock.Anything).Return(getResponse([]byte(tc.body), tc.status, nil), nil)
+		g := getGitLab(stubClient)
+
+		// act
+		err := g.ApproveMergeRequest(1, 2, 3)
+
+		// assert
+		assert.Equal(t, tc.expected, err, name)
+	}
+}
+
+func TestMergeMergeRequest(t *testing.T) {
+	// arrange
+	stubClient := getClient()
+	stubClient.On(
+		"Put",
+		"/projects/1/merge_requests/2/merge",
+		mock.Anything,
+		mock.Anything,
+		map[string]string{
+			"merge_when_pipeline_succeeds": "true",
+		}).Return(getResponse([]byte(`{"iid": 2, "state": "opened", "merge_when_pipeline_succeeds": true}`), http.StatusOK, nil), nil)
+	g := getGitLab(stubClient)
+
+	// act
+	actual, err := g.MergeMergeRequest(1, 2, 3)
+
+	// assert
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, "opened", actual.State, "State should be equal")
+}
+
+func TestCloseMergeRequestResponseError(t *testing.T) {
+	input := map[int]string{
+		http.StatusForbidden:        ErrForbidden.Error(),
+		http.StatusUnauthorized:     ErrForbidden.Error(),
+		http.StatusMethodNotAllowed: "Method Not Allowed",
+	}
+	for code, expected := range input {
+		// arrange
+		stubClient := getClient()
+		stubClient.On(
+			"Put",
+			"/projects/1/merge_requests/2",
+			mock.Anything,
+			mock.Anything,
+			map[string]string{
+				"state_event": "close",
+			}).Return(getResponse([]byte(`{"message": "`+http.StatusText(code)+`"}`), code, nil), nil)
+		g := getGitLab(stubClient)
+
+		// act
+		err := g.CloseMergeRequest(1, 2, 3)
+
+		// assert
+		assert.NotNil(t, err, "Error should not be nil")
+		assert.Contains(t, err.Error(), expected, "Error message should be equal")
+	}
+}
+
+func TestCloseMergeRequestSudoDenied(t *testing.T) {
+	// arrange
+	stubClient := getClient()
+	stubClient.On(
+		"Put",
+		"/projects/1/merge_requests/2",
+		mock.Anything,
+		mock.Anything,
+		mock.Anything).Return(getResponse([]byte(`{"message": "403 Forbidden - Must be admin to use sudo"}`), http.StatusForbidden, nil), nil)
+	g := getGitLab(stubClient)
+
+	// act
+	err := g.CloseMergeRequest(1, 2, 3)
+
+	// assert
+	assert.Equal(t, ErrSudoDenied, err, "Error should be equal")
+}
@@ -11,6 +11,131 @@ type GitLab struct {
 	mock.Mock
 }
 
+// ApproveMergeRequest provides a mock function with given fields: _a0, _a1, _a2
+func (_m *GitLab) ApproveMergeRequest(_a0 int, _a1 int, _a2 int) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, int, int) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CloseMergeRequest provides a mock function with given fields: _a0, _a1, _a2
+func (_m *GitLab) CloseMergeRequest(_a0 int, _a1 int, _a2 int) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, int, int) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateIssueNote provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *GitLab) CreateIssueNote(_a0 int, _a1 int, _a2 int, _a3 string) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, int, int, string) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateMergeRequestNote provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *GitLab) CreateMergeRequestNote(_a0 int, _a1 int, _a2 int, _a3 string) error {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, int, int, string) error); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetCompare provides a mock function with given fields: _a0, _a1, _a2
+func (_m *GitLab) GetCompare(_a0 int, _a1 string, _a2 string) (*gitlab.Compare, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *gitlab.Compare
+	if rf, ok := ret.Get(0).(func(int, string, string) *gitlab.Compare); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*gitlab.Compare)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int, string, string) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMergeRequest provides a mock function with given fields: _a0, _a1
+func (_m *GitLab) GetMergeRequest(_a0 int, _a1 int) (*gitlab.MergeRequest, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *gitlab.MergeRequest
+	if rf, ok := ret.Get(0).(func(int, int) *gitlab.MergeRequest); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*gitlab.MergeRequest)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMergeRequestChanges provides a mock function with given fields: _a0, _a1
+func (_m *GitLab) GetMergeRequestChanges(_a0 int, _a1 int) ([]string, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(int, int) []string); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetProject provides a mock function with given fields:
 func (_m *GitLab) GetProject() ([]*model.Project, error) {
 	ret := _m.Called()
@@ -34,6 +159,27 @@ func (_m *GitLab) GetProject() ([]*model.Project, error) {
 	return r0, r1
 }
 
+// GetProjectAccessLevel provides a mock function with given fields: _a0, _a1
+func (_m *GitLab) GetProjectAccessLevel(_a0 int, _a1 int) (int, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(int, int) int); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int, int) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetSingleCommit provides a mock function with given fields: _a0, _a1
 func (_m *GitLab) GetSingleCommit(_a0 int, _a1 string) (*gitlab.Commit, error) {
 	ret := _m.Called(_a0, _a1)
@@ -102,3 +248,72 @@ func (_m *GitLab) GetUser() ([]*gitlab.GitLabUser, error) {
 
 	return r0, r1
 }
+
+// ListCommitMergeRequests provides a mock function with given fields: _a0, _a1
+func (_m *GitLab) ListCommitMergeRequests(_a0 int, _a1 string) ([]*gitlab.MergeRequest, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*gitlab.MergeRequest
+	if rf, ok := ret.Get(0).(func(int, string) []*gitlab.MergeRequest); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*gitlab.MergeRequest)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListOpenMergeRequestsBySourceBranch provides a mock function with given fields: _a0, _a1
+func (_m *GitLab) ListOpenMergeRequestsBySourceBranch(_a0 int, _a1 string) ([]*gitlab.MergeRequest, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*gitlab.MergeRequest
+	if rf, ok := ret.Get(0).(func(int, string) []*gitlab.MergeRequest); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*gitlab.MergeRequest)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MergeMergeRequest provides a mock function with given fields: _a0, _a1, _a2
+func (_m *GitLab) MergeMergeRequest(_a0 int, _a1 int, _a2 int) (*gitlab.MergeRequest, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *gitlab.MergeRequest
+	if rf, ok := ret.Get(0).(func(int, int, int) *gitlab.MergeRequest); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*gitlab.MergeRequest)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int, int, int) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,27 @@
+package gitlab
+
+import (
+	"fmt"
+)
+
+// CreateMergeRequestNote comments body on the merge request iid of project id as the user sudo
+func (g *gitlab) CreateMergeRequestNote(id, iid, sudo int, body string) error {
+	return g.createNote(fmt.Sprintf("/projects/%v/merge_requests/%v/notes", id, iid), sudo, body)
+}
+
+// CreateIssueNote comments body on the issue iid of project id as the user sudo
+func (g *gitlab) CreateIssueNote(id, iid, sudo int, body string) error {
+	return g.createNote(fmt.Sprintf("/projects/%v/issues/%v/notes", id, iid), sudo, body)
+}
+
+func (g *gitlab) createNote(api string, sudo int, body string) error {
+	reqBody := map[string]string{
+		"body": body,
+	}
+	res, err := g.client.Post(g.GitLabAPI+api, sudoHeader(sudo), g.tokenParams(), reqBody)
+	if err != nil {
+		return err
+	}
+	_, err = readActionResponse(res)
+	return err
+}
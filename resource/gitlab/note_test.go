@@ -0,0 +1,52 @@
+package gitlab
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateMergeRequestNote(t *testing.T) {
+	// arrange
+	stubClient := getClient()
+	stubClient.On(
+		"Post",
+		"/projects/1/merge_requests/2/notes",
+		map[string]string{
+			"Content-Type": "application/x-www-form-urlencoded",
+			"Sudo":         "3",
+		},
+		map[string]string{
+			"private_token": "",
+		},
+		map[string]string{
+			"body": "fake-body",
+		}).Return(getResponse([]byte(`{"id": 4}`), http.StatusCreated, nil), nil)
+	g := getGitLab(stubClient)
+
+	// act
+	err := g.CreateMergeRequestNote(1, 2, 3, "fake-body")
+
+	// assert
+	assert.Nil(t, err, "Error should be nil")
+}
+
+func TestCreateIssueNoteForbidden(t *testing.T) {
+	// arrange
+	stubClient := getClient()
+	stubClient.On(
+		"Post",
+		"/projects/1/issues/2/notes",
+		mock.Anything,
+		mock.Anything,
+		mock.Anything).Return(getResponse([]byte(`{"message": "403 Forbidden"}`), http.StatusForbidden, nil), nil)
+	g := getGitLab(stubClient)
+
+	// act
+	err := g.CreateIssueNote(1, 2, 3, "fake-body")
+
+	// assert
+	assert.Equal(t, ErrForbidden, err, "Error should be ErrForbidden")
+}
@@ -4,12 +4,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 
 	"gitlack/model"
 
 	"github.com/sirupsen/logrus"
 )
 
+// MaintainerAccess is the access level of Maintainer in GitLab, Owner is above it
+// ref: https://docs.gitlab.com/ee/api/members.html#valid-access-levels
+const MaintainerAccess = 40
+
 // GitLabProject is the response of getting GitLab project list
 type GitLabProject struct {
 	Name string `json:"path_with_namespace"`
@@ -66,3 +71,39 @@ func (g *gitlab) GetProject() ([]*model.Project, error) {
 	}
 	return allProjects, nil
 }
+
+// GetProjectAccessLevel returns the access level of user to project id, including the access inherited from groups,
+// it's 0 if the user isn't a member of project
+func (g *gitlab) GetProjectAccessLevel(id, userID int) (int, error) {
+	url := g.GitLabAPI + fmt.Sprintf("/projects/%v/members/all/%v", id, userID)
+	params := map[string]string{
+		"private_token": g.GitLabToken,
+	}
+	res, err := g.client.Get(url, nil, params, nil)
+	if err != nil {
+		return 0, err
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		logrus.Errorln(err)
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return 0, nil
+	}
+	if res.StatusCode != 200 {
+		err := fmt.Errorf("Invalid GitLab API error: %v", string(body))
+		logrus.Errorln(err)
+		return 0, err
+	}
+	var member struct {
+		AccessLevel int `json:"access_level"`
+	}
+	err = json.Unmarshal(body, &member)
+	if err != nil {
+		logrus.Errorln(err)
+		return 0, err
+	}
+	return member.AccessLevel, nil
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestGetProjectGetOnce(t *testing.T) {
@@ -67,3 +68,37 @@ func TestGetProjectInvalidGitLabAPI(t *testing.T) {
 	assert.NotNil(t, err, "Error should not be nil")
 	assert.Equal(t, "Invalid GitLab API error: fake-error", err.Error(), "Error message should be equal")
 }
+
+func TestGetProjectAccessLevel(t *testing.T) {
+	// arrange
+	stubClient := getClient()
+	stubClient.On(
+		"Get",
+		"/projects/1/members/all/2",
+		mock.Anything,
+		map[string]string{
+			"private_token": "",
+		},
+		mock.Anything).Return(getResponse([]byte(`{"id": 2, "access_level": 40}`), http.StatusOK, nil), nil)
+	g := getGitLab(stubClient)
+
+	// act
+	actual, err := g.GetProjectAccessLevel(1, 2)
+
+	// assert
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, MaintainerAccess, actual, "Access level should be equal")
+}
+
+func TestGetProjectAccessLevelNotMember(t *testing.T) {
+	// arrange
+	stubClient := getGetClientWithResponse([]byte(`{"message": "404 Not found"}`), http.StatusNotFound, "")
+	g := getGitLab(stubClient)
+
+	// act
+	actual, err := g.GetProjectAccessLevel(1, 2)
+
+	// assert
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, 0, actual, "Access level should be 0")
+}
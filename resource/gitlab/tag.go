@@ -20,11 +20,19 @@ type Tag struct {
 	CommitInfo  Commit  `json:"commit"`
 }
 
+// Release is the release of tag, TagName is empty if the tag has no release
 type Release struct {
+	TagName     string `json:"tag_name"`
 	Description string `json:"description"`
 }
 
 type Commit struct {
+	ID             string   `json:"id"`
+	ShortID        string   `json:"short_id"`
+	Title          string   `json:"title"`
+	Message        string   `json:"message"`
+	AuthorName     string   `json:"author_name"`
+	AuthorEmail    string   `json:"author_email"`
 	CommitterEmail string   `json:"committer_email"`
 	LastPipeline   Pipeline `json:"last_pipeline"`
 }
@@ -39,31 +47,46 @@ type Compare struct {
 	Commits []*Commit `json:"commits"`
 }
 
+// GetTagList returns all tags of project id, every page is fetched
 func (g *gitlab) GetTagList(id int) ([]*Tag, error) {
 	url := g.GitLabAPI + fmt.Sprintf("/projects/%v/repository/tags", id)
 	params := map[string]string{
 		"private_token": g.GitLabToken,
+		"per_page":      "100",
 	}
-	res, err := g.client.Get(url, nil, params, nil)
-	if err != nil {
-		return nil, err
-	}
-	body, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		logrus.Errorln(err)
-		return nil, err
-	}
-	defer res.Body.Close()
-	if res.StatusCode != 200 {
-		err := fmt.Errorf("Invalid GitLab API error: %v", string(body))
-		logrus.Errorln(err)
-		return nil, err
-	}
+
 	var tagList []*Tag
-	err = json.Unmarshal(body, &tagList)
-	if err != nil {
-		logrus.Errorln(err)
-		return nil, err
+	// run at most 100 times for preventing from infinite loop
+	for i := 0; i < 100; i++ {
+		res, err := g.client.Get(url, nil, params, nil)
+		if err != nil {
+			return nil, err
+		}
+		body, err := ioutil.ReadAll(res.Body)
+		defer res.Body.Close()
+		if err != nil {
+			logrus.Errorln(err)
+			return nil, err
+		}
+		if res.StatusCode != 200 {
+			err := fmt.Errorf("Invalid GitLab API error: %v", string(body))
+			logrus.Errorln(err)
+			return nil, err
+		}
+		var tags []*Tag
+		err = json.Unmarshal(body, &tags)
+		if err != nil {
+			logrus.Errorln(err)
+			return nil, err
+		}
+		tagList = append(tagList, tags...)
+
+		// check next page
+		next := res.Header.Get("X-Next-Page")
+		if next == "" {
+			break
+		}
+		params["page"] = next
 	}
 	return tagList, nil
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestGetTagListGetOnce(t *testing.T) {
@@ -59,3 +60,22 @@ func TestGetTagListInvalidGitLabAPI(t *testing.T) {
 	assert.NotNil(t, err, "Error should not be nil")
 	assert.Equal(t, "Invalid GitLab API error: fake-body", err.Error(), "Error message should be equal")
 }
+
+func TestGetTagListAllPages(t *testing.T) {
+	// arrange
+	stubByte, _ := getTagListResponse(2)
+	stubClient := getClient()
+	stubClient.On("Get", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(getResponse(stubByte, http.StatusOK, getNextPageHeader("2")), nil).Once()
+	stubClient.On("Get", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(getResponse(stubByte, http.StatusOK, getNextPageHeader("")), nil).Once()
+	g := getGitLab(stubClient)
+
+	// act
+	actual, err := g.GetTagList(1)
+
+	// assert
+	assert.Nil(t, err, "Error should be nil")
+	assert.Equal(t, 4, len(actual), "Tags of every page should be returned")
+	stubClient.AssertNumberOfCalls(t, "Get", 2)
+}
@@ -1,6 +1,8 @@
 package resource
 
 import (
+	"bytes"
+	"encoding/json"
 	"net/http"
 	"net/url"
 	"strings"
@@ -12,6 +14,8 @@ import (
 type Client interface {
 	Get(string, map[string]string, map[string]string, map[string]string) (*http.Response, error)
 	Post(string, map[string]string, map[string]string, map[string]string) (*http.Response, error)
+	PostJSON(string, map[string]string, map[string]string, interface{}) (*http.Response, error)
+	Put(string, map[string]string, map[string]string, map[string]string) (*http.Response, error)
 }
 
 // Client hold a HTTP client
@@ -46,6 +50,62 @@ func (c *client) Post(endpoint string, header map[string]string, param map[strin
 	return response, nil
 }
 
+func (c *client) Put(endpoint string, header map[string]string, param map[string]string, body map[string]string) (*http.Response, error) {
+	req, err := prepareRequest(http.MethodPut, endpoint, header, param, body)
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.client.Do(req)
+	if err != nil {
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return response, nil
+}
+
+// PostJSON sends body encoded in JSON, Content-Type is set unless it's given in header
+func (c *client) PostJSON(endpoint string, header map[string]string, param map[string]string, body interface{}) (*http.Response, error) {
+	req, err := prepareJSONRequest(http.MethodPost, endpoint, header, param, body)
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.client.Do(req)
+	if err != nil {
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return response, nil
+}
+
+func prepareJSONRequest(method, endpoint string, header map[string]string, param map[string]string, body interface{}) (*http.Request, error) {
+	marshaled, err := json.Marshal(body)
+	if err != nil {
+		logrus.Errorln(err)
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, endpoint, bytes.NewReader(marshaled))
+	if err != nil {
+		logrus.Errorln(err)
+		return nil, err
+	}
+
+	// add header
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	// add query string parameters
+	params := req.URL.Query()
+	for k, v := range param {
+		params.Add(k, v)
+	}
+	req.URL.RawQuery = params.Encode()
+
+	return req, nil
+}
+
 func prepareRequest(method, endpoint string, header map[string]string, param map[string]string, body map[string]string) (*http.Request, error) {
 	// add body
 	reqBody := url.Values{}
@@ -0,0 +1,48 @@
+package resource
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostJSON(t *testing.T) {
+	// arrange
+	var contentType, auth, query string
+	var body map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		auth = r.Header.Get("Authorization")
+		query = r.URL.RawQuery
+		b, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(b, &body)
+	}))
+	defer srv.Close()
+	c := NewClient()
+
+	// act
+	res, err := c.PostJSON(srv.URL, map[string]string{"Authorization": "Bearer fake-token"}, map[string]string{"fake-param": "fake"}, map[string]string{"text": "fake-text"})
+
+	// assert
+	assert.Nil(t, err, "err should be nil")
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/json; charset=utf-8", contentType)
+	assert.Equal(t, "Bearer fake-token", auth)
+	assert.Equal(t, "fake-param=fake", query)
+	assert.Equal(t, map[string]string{"text": "fake-text"}, body)
+}
+
+func TestPostJSONInvalidBody(t *testing.T) {
+	// arrange
+	c := NewClient()
+
+	// act
+	_, err := c.PostJSON("http://fake.com", nil, nil, func() {})
+
+	// assert
+	assert.NotNil(t, err, "err should not be nil")
+}
@@ -0,0 +1,99 @@
+package slack
+
+// Block represents the Slack layout block
+// see: https://api.slack.com/reference/block-kit/blocks
+type Block struct {
+	Type     string        `json:"type"`
+	BlockID  string        `json:"block_id,omitempty"`
+	Text     *Text         `json:"text,omitempty"`
+	Fields   []*Text       `json:"fields,omitempty"`
+	Elements []interface{} `json:"elements,omitempty"`
+}
+
+// Text represents the text object used in blocks
+type Text struct {
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	Emoji bool   `json:"emoji,omitempty"`
+}
+
+// Image represents the image element in context block
+type Image struct {
+	Type     string `json:"type"`
+	ImageURL string `json:"image_url"`
+	AltText  string `json:"alt_text"`
+}
+
+// Button represents the button element in actions block
+type Button struct {
+	Type     string `json:"type"`
+	Text     *Text  `json:"text"`
+	ActionID string `json:"action_id,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Style    string `json:"style,omitempty"`
+}
+
+// Markdown returns the text object in Slack mrkdwn format
+func Markdown(text string) *Text {
+	return &Text{Type: "mrkdwn", Text: text}
+}
+
+// PlainText returns the text object in plain text, emoji is rendered
+func PlainText(text string) *Text {
+	return &Text{Type: "plain_text", Text: text, Emoji: true}
+}
+
+// NewImage returns the image element
+func NewImage(url, alt string) *Image {
+	return &Image{Type: "image", ImageURL: url, AltText: alt}
+}
+
+// NewButton returns the button element which opens url
+func NewButton(text, url string) *Button {
+	return &Button{Type: "button", Text: PlainText(text), URL: url}
+}
+
+// NewActionButton returns the button element which sends value to the interactivity endpoint when it's clicked,
+// style is empty, "primary" or "danger"
+func NewActionButton(text, actionID, value, style string) *Button {
+	return &Button{Type: "button", Text: PlainText(text), ActionID: actionID, Value: value, Style: style}
+}
+
+// HeaderBlock returns the header block, Slack only accepts plain text here
+func HeaderBlock(text string) Block {
+	return Block{Type: "header", Text: PlainText(text)}
+}
+
+// SectionBlock returns the section block with text
+func SectionBlock(text string) Block {
+	return Block{Type: "section", Text: Markdown(text)}
+}
+
+// FieldsBlock returns the section block shown as two columns
+func FieldsBlock(fields ...string) Block {
+	b := Block{Type: "section"}
+	for _, f := range fields {
+		b.Fields = append(b.Fields, Markdown(f))
+	}
+	return b
+}
+
+// ContextBlock returns the context block with images and texts
+func ContextBlock(elements ...interface{}) Block {
+	return Block{Type: "context", Elements: elements}
+}
+
+// ActionsBlock returns the actions block with buttons
+func ActionsBlock(buttons ...*Button) Block {
+	b := Block{Type: "actions"}
+	for _, btn := range buttons {
+		b.Elements = append(b.Elements, btn)
+	}
+	return b
+}
+
+// DividerBlock returns the divider block
+func DividerBlock() Block {
+	return Block{Type: "divider"}
+}
@@ -0,0 +1,157 @@
+package slack
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// conversationResponse is the response of opening a conversation
+type conversationResponse struct {
+	OK      bool   `json:"ok"`
+	Err     string `json:"error"`
+	Channel struct {
+		ID string `json:"id"`
+	} `json:"channel"`
+}
+
+// conversationListResponse is a page of the channels listed, the next page is read by NextCursor
+type conversationListResponse struct {
+	OK       bool   `json:"ok"`
+	Err      string `json:"error"`
+	Channels []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"channels"`
+	Metadata struct {
+		NextCursor string `json:"next_cursor"`
+	} `json:"response_metadata"`
+}
+
+// ErrChannelNotFound is returned if no channel has the name
+var ErrChannelNotFound = errors.New("channel not found")
+
+var channelIDRegexp = regexp.MustCompile(`^[CGD][A-Z0-9]{8,}$`)
+
+// IsChannelID reports whether channel is an ID, such as `C0123456789`, rather than a name
+func IsChannelID(channel string) bool {
+	return channelIDRegexp.MatchString(channel)
+}
+
+// permalinkResponse is the response of getting the permalink of message
+type permalinkResponse struct {
+	OK        bool   `json:"ok"`
+	Err       string `json:"error"`
+	Permalink string `json:"permalink"`
+}
+
+// OpenConversation opens the direct message with user and returns its channel ID,
+// the same channel is returned if it's already open
+func (s *slack) OpenConversation(userID string) (string, error) {
+	reqBody := map[string]string{
+		"token": s.SlackToken,
+		"users": userID,
+	}
+	var cr conversationResponse
+	err := s.callFormAPI("/conversations.open", reqBody, &cr)
+	if err != nil {
+		return "", err
+	}
+	if !cr.OK {
+		err := fmt.Errorf("Invalid Slack API: %v", cr.Err)
+		logrus.Errorln(err)
+		return "", err
+	}
+	return cr.Channel.ID, nil
+}
+
+// GetChannelID returns the ID of public or private channel by name, with or without `#`,
+// the private channels are found only if the bot is a member
+func (s *slack) GetChannelID(name string) (string, error) {
+	name = strings.TrimPrefix(name, "#")
+	cursor := ""
+	for {
+		reqBody := map[string]string{
+			"token":            s.SlackToken,
+			"types":            "public_channel,private_channel",
+			"exclude_archived": "true",
+			"limit":            "1000",
+		}
+		if cursor != "" {
+			reqBody["cursor"] = cursor
+		}
+		var lr conversationListResponse
+		err := s.callFormAPI("/conversations.list", reqBody, &lr)
+		if err != nil {
+			return "", err
+		}
+		if !lr.OK {
+			err := fmt.Errorf("Invalid Slack API: %v", lr.Err)
+			logrus.Errorln(err)
+			return "", err
+		}
+		for _, c := range lr.Channels {
+			if c.Name == name {
+				return c.ID, nil
+			}
+		}
+		cursor = lr.Metadata.NextCursor
+		if cursor == "" {
+			return "", ErrChannelNotFound
+		}
+	}
+}
+
+// GetPermalink returns the URL of message ts in channel
+func (s *slack) GetPermalink(channel, ts string) (string, error) {
+	reqBody := map[string]string{
+		"token":      s.SlackToken,
+		"channel":    channel,
+		"message_ts": ts,
+	}
+	var pr permalinkResponse
+	err := s.callFormAPI("/chat.getPermalink", reqBody, &pr)
+	if err != nil {
+		return "", err
+	}
+	if !pr.OK {
+		err := fmt.Errorf("Invalid Slack API: %v", pr.Err)
+		logrus.Errorln(err)
+		return "", err
+	}
+	return pr.Permalink, nil
+}
+
+// callFormAPI posts to Slack API and decodes the response into v, for the APIs not responding a message
+func (s *slack) callFormAPI(api string, reqBody map[string]string, v interface{}) error {
+	header := map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	}
+	res, err := s.client.Post(s.SlackAPI+api, header, nil, reqBody)
+	if err != nil {
+		return err
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		logrus.Errorln(err)
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		err := fmt.Errorf("HTTP response error: %v", string(body))
+		logrus.Errorln(err)
+		return err
+	}
+	err = json.Unmarshal(body, v)
+	if err != nil {
+		logrus.Errorln(err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package slack
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenConversation(t *testing.T) {
+	// arrange
+	expected := map[string]string{
+		"token": "",
+		"users": "fake-user-id",
+	}
+	stubClient := getPostClientWithRequestBody([]byte(`{"ok": true, "channel": {"id": "fake-dm-id"}}`), http.StatusOK, expected)
+	s := getSlack(stubClient)
+
+	// act
+	actual, err := s.OpenConversation("fake-user-id")
+
+	// assert
+	assert.Nil(t, err, "err should be nil")
+	assert.Equal(t, "fake-dm-id", actual, "channel ID should be equal")
+	stubClient.AssertCalled(t, "Post", "/conversations.open", getURLEncodedHeader(), mapNil, expected)
+}
+
+func TestOpenConversationInvalidSlackAPI(t *testing.T) {
+	// arrange
+	stubClient := getPostClientWithResponse([]byte(`{"ok": false, "error": "user_not_found"}`), http.StatusOK)
+	s := getSlack(stubClient)
+
+	// act
+	_, err := s.OpenConversation("")
+
+	// assert
+	assert.NotNil(t, err, "err should not be nil")
+	assert.Equal(t, "Invalid Slack API: user_not_found", err.Error(), "error message should be equal")
+}
+
+func TestGetChannelID(t *testing.T) {
+	// arrange
+	firstPage := map[string]string{
+		"token":            "",
+		"types":            "public_channel,private_channel",
+		"exclude_archived": "true",
+		"limit":            "1000",
+	}
+	secondPage := map[string]string{
+		"token":            "",
+		"types":            "public_channel,private_channel",
+		"exclude_archived": "true",
+		"limit":            "1000",
+		"cursor":           "fake-cursor",
+	}
+	stubClient := getClient()
+	stubClient.On("Post", "/conversations.list", getURLEncodedHeader(), mapNil, firstPage).Return(getResponse(
+		[]byte(`{"ok": true, "channels": [{"id": "C0000000001", "name": "fake-other"}], "response_metadata": {"next_cursor": "fake-cursor"}}`), http.StatusOK), nil)
+	stubClient.On("Post", "/conversations.list", getURLEncodedHeader(), mapNil, secondPage).Return(getResponse(
+		[]byte(`{"ok": true, "channels": [{"id": "C0123456789", "name": "fake-team"}], "response_metadata": {"next_cursor": ""}}`), http.StatusOK), nil)
+	s := getSlack(stubClient)
+
+	// act
+	actual, err := s.GetChannelID("#fake-team")
+
+	// assert
+	assert.Nil(t, err, "err should be nil")
+	assert.Equal(t, "C0123456789", actual, "channel ID should be equal")
+}
+
+func TestGetChannelIDNotFound(t *testing.T) {
+	// arrange
+	stubClient := getPostClientWithResponse([]byte(`{"ok": true, "channels": [{"id": "C0000000001", "name": "fake-other"}]}`), http.StatusOK)
+	s := getSlack(stubClient)
+
+	// act
+	_, err := s.GetChannelID("fake-team")
+
+	// assert
+	assert.Equal(t, ErrChannelNotFound, err, "err should be ErrChannelNotFound")
+}
+
+func TestIsChannelID(t *testing.T) {
+	assert.True(t, IsChannelID("C0123456789"))
+	assert.True(t, IsChannelID("G0123456789"))
+	assert.False(t, IsChannelID("fake-team"))
+	assert.False(t, IsChannelID("#C0123456789"))
+}
+
+func TestGetPermalink(t *testing.T) {
+	// arrange
+	expected := map[string]string{
+		"token":      "",
+		"channel":    "fake-channel",
+		"message_ts": "fake-ts",
+	}
+	stubClient := getPostClientWithRequestBody([]byte(`{"ok": true, "channel": "fake-channel", "permalink": "https://fake.slack.com/archives/fake-channel/p1"}`), http.StatusOK, expected)
+	s := getSlack(stubClient)
+
+	// act
+	actual, err := s.GetPermalink("fake-channel", "fake-ts")
+
+	// assert
+	assert.Nil(t, err, "err should be nil")
+	assert.Equal(t, "https://fake.slack.com/archives/fake-channel/p1", actual, "permalink should be equal")
+}
+
+func TestGetPermalinkHTTPError(t *testing.T) {
+	// arrange
+	stubClient := getPostClientWithResponse([]byte(`fake-body`), http.StatusInternalServerError)
+	s := getSlack(stubClient)
+
+	// act
+	_, err := s.GetPermalink("", "")
+
+	// assert
+	assert.NotNil(t, err, "err should not be nil")
+	assert.Equal(t, "HTTP response error: fake-body", err.Error(), "error message should be equal")
+}
@@ -0,0 +1,145 @@
+package slack
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"gitlack/model"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DryRunMessage is a call to Slack recorded in dry-run mode
+type DryRunMessage struct {
+	Method     string      `json:"method"`
+	Channel    string      `json:"channel"`
+	TS         string      `json:"ts"`
+	ThreadTS   string      `json:"thread_ts,omitempty"`
+	Text       string      `json:"text,omitempty"`
+	Username   string      `json:"username,omitempty"`
+	Attachment *Attachment `json:"attachment,omitempty"`
+	Blocks     []Block     `json:"blocks,omitempty"`
+	Emoji      string      `json:"emoji,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// Recorder keeps the latest messages which would have been sent to Slack
+type Recorder interface {
+	Messages() []*DryRunMessage
+}
+
+type dryRun struct {
+	Slack
+
+	mu       sync.Mutex
+	messages []*DryRunMessage
+	next     int
+	full     bool
+	seq      int64
+}
+
+// NewDryRunSlack returns a Slack which logs and records messages instead of sending them,
+// users are still read from s, size is the number of messages kept
+func NewDryRunSlack(s Slack, size int) Slack {
+	if size < 1 {
+		size = 1
+	}
+	return &dryRun{
+		Slack:    s,
+		messages: make([]*DryRunMessage, size),
+	}
+}
+
+func (d *dryRun) PostSlackMessage(channel, text string, author *model.User, atm *Attachment, thread ...string) (*MessageResponse, error) {
+	return d.PostSlackBlocks(channel, text, author, atm, nil, thread...)
+}
+
+func (d *dryRun) PostSlackBlocks(channel, text string, author *model.User, atm *Attachment, blocks []Block, thread ...string) (*MessageResponse, error) {
+	m := &DryRunMessage{
+		Method:     "chat.postMessage",
+		Channel:    channel,
+		TS:         d.ts(),
+		Text:       text,
+		Attachment: atm,
+		Blocks:     blocks,
+	}
+	if len(thread) != 0 {
+		m.ThreadTS = thread[0]
+	}
+	if author != nil {
+		m.Username = author.Name + " (Gitlack)"
+	}
+	d.record(m)
+	return &MessageResponse{OK: true, Channel: channel, TS: m.TS}, nil
+}
+
+func (d *dryRun) UpdateSlackMessage(channel, ts, text string, atm *Attachment, blocks []Block) (*MessageResponse, error) {
+	d.record(&DryRunMessage{
+		Method:     "chat.update",
+		Channel:    channel,
+		TS:         ts,
+		Text:       text,
+		Attachment: atm,
+		Blocks:     blocks,
+	})
+	return &MessageResponse{OK: true, Channel: channel, TS: ts}, nil
+}
+
+func (d *dryRun) AddReaction(channel, ts, emoji string) error {
+	d.record(&DryRunMessage{Method: "reactions.add", Channel: channel, TS: ts, Emoji: emoji})
+	return nil
+}
+
+func (d *dryRun) RemoveReaction(channel, ts, emoji string) error {
+	d.record(&DryRunMessage{Method: "reactions.remove", Channel: channel, TS: ts, Emoji: emoji})
+	return nil
+}
+
+// OpenConversation returns a synthetic channel of direct message, no conversation is opened
+func (d *dryRun) OpenConversation(userID string) (string, error) {
+	return "dm-" + userID, nil
+}
+
+// GetPermalink returns a synthetic permalink since the message is never posted
+func (d *dryRun) GetPermalink(channel, ts string) (string, error) {
+	return fmt.Sprintf("dry-run://%v/%v", channel, ts), nil
+}
+
+// RespondEphemeral records the reply to user instead of sending it to response_url
+func (d *dryRun) RespondEphemeral(responseURL, text string) error {
+	d.record(&DryRunMessage{Method: "response_url", Channel: responseURL, Text: text})
+	return nil
+}
+
+// Messages returns the recorded messages, the oldest comes first
+func (d *dryRun) Messages() []*DryRunMessage {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	msgs := []*DryRunMessage{}
+	if d.full {
+		msgs = append(msgs, d.messages[d.next:]...)
+	}
+	return append(msgs, d.messages[:d.next]...)
+}
+
+func (d *dryRun) record(m *DryRunMessage) {
+	m.CreatedAt = time.Now()
+	logrus.Infof("dry-run %v to %v, ts: %v, thread: %v, text: %q", m.Method, m.Channel, m.TS, m.ThreadTS, m.Text)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.messages[d.next] = m
+	d.next = (d.next + 1) % len(d.messages)
+	if d.next == 0 {
+		d.full = true
+	}
+}
+
+// ts returns a synthetic timestamp of message in Slack format, it's unique within the process
+func (d *dryRun) ts() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.seq++
+	return fmt.Sprintf("%v.%06d", time.Now().Unix(), d.seq%1000000)
+}
@@ -0,0 +1,65 @@
+package slack
+
+import (
+	"fmt"
+	"testing"
+
+	"gitlack/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDryRunPostSlackMessage(t *testing.T) {
+	// arrange
+	stubClient := getClient()
+	d := NewDryRunSlack(getSlack(stubClient), 10)
+	author := &model.User{Name: "fake-name"}
+
+	// act
+	root, err := d.PostSlackMessage("fake-channel", "fake-root", author, nil)
+	reply, _ := d.PostSlackBlocks("fake-channel", "fake-reply", nil, nil, []Block{DividerBlock()}, root.TS)
+
+	// assert
+	assert.Nil(t, err, "err should be nil")
+	assert.Equal(t, "fake-channel", root.Channel)
+	assert.NotEqual(t, root.TS, reply.TS, "ts should be unique")
+	msgs := d.(Recorder).Messages()
+	assert.Len(t, msgs, 2)
+	assert.Equal(t, "fake-root", msgs[0].Text)
+	assert.Equal(t, "fake-name (Gitlack)", msgs[0].Username)
+	assert.Equal(t, root.TS, msgs[1].ThreadTS)
+	assert.Equal(t, []Block{DividerBlock()}, msgs[1].Blocks)
+	assert.Empty(t, stubClient.Calls, "Slack API should not be called")
+}
+
+func TestDryRunRingBuffer(t *testing.T) {
+	// arrange
+	d := NewDryRunSlack(getSlack(getClient()), 3)
+
+	// act
+	for i := 0; i < 5; i++ {
+		d.PostSlackMessage("fake-channel", fmt.Sprintf("fake-text-%v", i), nil, nil)
+	}
+	d.AddReaction("fake-channel", "fake-ts", "tada")
+
+	// assert
+	msgs := d.(Recorder).Messages()
+	assert.Len(t, msgs, 3)
+	assert.Equal(t, "fake-text-3", msgs[0].Text)
+	assert.Equal(t, "fake-text-4", msgs[1].Text)
+	assert.Equal(t, "reactions.add", msgs[2].Method)
+	assert.Equal(t, "tada", msgs[2].Emoji)
+}
+
+func TestDryRunGetUser(t *testing.T) {
+	// arrange
+	stubClient := getGetClientWithResponse(getSlackUserResopnse(0, 0, 2, false), 200)
+	d := NewDryRunSlack(getSlack(stubClient), 3)
+
+	// act
+	users, err := d.GetUser()
+
+	// assert
+	assert.Nil(t, err, "err should be nil")
+	assert.Len(t, users, 2)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 
 	"gitlack/model"
 
@@ -31,9 +32,6 @@ type Attachment struct {
 }
 
 func (s *slack) PostSlackMessage(channel, text string, author *model.User, atm *Attachment, thread ...string) (*MessageResponse, error) {
-	header := map[string]string{
-		"Content-Type": "application/x-www-form-urlencoded",
-	}
 	reqBody := map[string]string{
 		"token":   s.SlackToken,
 		"channel": channel,
@@ -57,13 +55,140 @@ func (s *slack) PostSlackMessage(channel, text string, author *model.User, atm *
 		reqBody["icon_url"] = author.AvatarURL
 	}
 
-	url := s.SlackAPI + "/chat.postMessage"
+	return s.sendMessage("/chat.postMessage", reqBody)
+}
+
+// messageRequest is the JSON body of chat.postMessage and chat.update
+type messageRequest struct {
+	Channel     string        `json:"channel"`
+	TS          string        `json:"ts,omitempty"`
+	Text        string        `json:"text"`
+	ThreadTS    string        `json:"thread_ts,omitempty"`
+	Username    string        `json:"username,omitempty"`
+	IconURL     string        `json:"icon_url,omitempty"`
+	Attachments []*Attachment `json:"attachments,omitempty"`
+	Blocks      []Block       `json:"blocks,omitempty"`
+}
+
+// PostSlackBlocks posts message rendered by blocks, text is shown by clients which can't render blocks
+func (s *slack) PostSlackBlocks(channel, text string, author *model.User, atm *Attachment, blocks []Block, thread ...string) (*MessageResponse, error) {
+	reqBody := &messageRequest{
+		Channel: channel,
+		Text:    text,
+		Blocks:  blocks,
+	}
+	if len(thread) != 0 {
+		reqBody.ThreadTS = thread[0]
+	}
+	if atm != nil {
+		reqBody.Attachments = []*Attachment{atm}
+	}
+	if author != nil {
+		reqBody.Username = author.Name + " (Gitlack)"
+		reqBody.IconURL = author.AvatarURL
+	}
+
+	return s.sendJSONMessage("/chat.postMessage", reqBody)
+}
+
+// UpdateSlackMessage replaces the text, attachment and blocks of message ts in channel,
+// the blocks of message are kept if blocks is nil
+func (s *slack) UpdateSlackMessage(channel, ts, text string, atm *Attachment, blocks []Block) (*MessageResponse, error) {
+	reqBody := &messageRequest{
+		Channel: channel,
+		TS:      ts,
+		Text:    text,
+		Blocks:  blocks,
+	}
+	if atm != nil {
+		reqBody.Attachments = []*Attachment{atm}
+	}
+
+	return s.sendJSONMessage("/chat.update", reqBody)
+}
+
+// RespondEphemeral replies to the user interacting with a message by its response_url,
+// the reply is only shown to the user and the original message is kept
+// ref: https://api.slack.com/interactivity/handling#message_responses
+func (s *slack) RespondEphemeral(responseURL, text string) error {
+	reqBody := map[string]interface{}{
+		"response_type":    "ephemeral",
+		"replace_original": false,
+		"text":             text,
+	}
+	res, err := s.client.PostJSON(responseURL, nil, nil, reqBody)
+	if err != nil {
+		return err
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		logrus.Errorln(err)
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		err := fmt.Errorf("HTTP response error: %v", string(body))
+		logrus.Errorln(err)
+		return err
+	}
+	return nil
+}
+
+func (s *slack) sendMessage(api string, reqBody map[string]string) (*MessageResponse, error) {
+	return checkMessage(s.callAPI(api, reqBody))
+}
+
+func (s *slack) sendJSONMessage(api string, reqBody interface{}) (*MessageResponse, error) {
+	return checkMessage(s.callJSONAPI(api, reqBody))
+}
+
+// checkMessage turns the response which is not ok into error
+func checkMessage(smr *MessageResponse, err error) (*MessageResponse, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	if !smr.OK {
+		errMsg := fmt.Sprintf("Invalid Slack API: %v", smr.Err)
+		logrus.Errorln(errMsg)
+		return nil, errors.New(errMsg)
+	}
+
+	return smr, nil
+}
+
+// callAPI posts to Slack API, the response is returned even if it's not ok
+func (s *slack) callAPI(api string, reqBody map[string]string) (*MessageResponse, error) {
+	header := map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	}
+	url := s.SlackAPI + api
 
 	res, err := s.client.Post(url, header, nil, reqBody)
 	if err != nil {
 		return nil, err
 	}
 
+	return parseMessageResponse(res)
+}
+
+// callJSONAPI posts JSON body to Slack API, the token is sent in header
+func (s *slack) callJSONAPI(api string, reqBody interface{}) (*MessageResponse, error) {
+	header := map[string]string{
+		"Authorization": "Bearer " + s.SlackToken,
+	}
+	url := s.SlackAPI + api
+
+	res, err := s.client.PostJSON(url, header, nil, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseMessageResponse(res)
+}
+
+func parseMessageResponse(res *http.Response) (*MessageResponse, error) {
 	body, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		logrus.Errorln(err)
@@ -84,11 +209,5 @@ func (s *slack) PostSlackMessage(channel, text string, author *model.User, atm *
 		return nil, err
 	}
 
-	if !smr.OK {
-		errMsg := fmt.Sprintf("Invalid Slack API: %v", smr.Err)
-		logrus.Errorln(errMsg)
-		return nil, errors.New(errMsg)
-	}
-
 	return &smr, nil
 }
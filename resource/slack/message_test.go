@@ -139,3 +139,150 @@ func TestPostSlackMessageInvalidSlackAPI(t *testing.T) {
 	assert.NotNil(t, err, "err should not be nil")
 	assert.Equal(t, "Invalid Slack API: fake-error", err.Error(), "err should be fake-error")
 }
+
+func TestPostSlackBlocks(t *testing.T) {
+	// arrange
+	author := &model.User{
+		Name:      "fake-name",
+		AvatarURL: "fake-icon-url",
+	}
+	atm := &Attachment{Color: "fake-color", Title: "fake-title"}
+	blocks := []Block{HeaderBlock("fake-header"), SectionBlock("fake-section")}
+	expected := &messageRequest{
+		Channel:     "fake-channel",
+		Text:        "fake-text",
+		ThreadTS:    "fake-thread-ts",
+		Username:    "fake-name (Gitlack)",
+		IconURL:     "fake-icon-url",
+		Attachments: []*Attachment{atm},
+		Blocks:      blocks,
+	}
+	stubClient := getPostJSONClientWithResponse(getOKResponse(), http.StatusOK)
+	s := getSlack(stubClient)
+	s.SlackToken = "fake-token"
+
+	// act
+	_, err := s.PostSlackBlocks("fake-channel", "fake-text", author, atm, blocks, "fake-thread-ts")
+
+	// assert
+	assert.Nil(t, err, "err should be nil")
+	stubClient.AssertCalled(t, "PostJSON", "/chat.postMessage", getBearerHeader("fake-token"), mapNil, expected)
+}
+
+func TestPostSlackBlocksMarshal(t *testing.T) {
+	// arrange
+	reqBody := &messageRequest{
+		Channel: "fake-channel",
+		Text:    "fake-text",
+		Blocks: []Block{
+			ContextBlock(NewImage("fake-url", "fake-alt"), Markdown("fake-context")),
+			ActionsBlock(NewButton("fake-button", "fake-link")),
+		},
+	}
+	expected := `{"channel":"fake-channel","text":"fake-text","blocks":[` +
+		`{"type":"context","elements":[{"type":"image","image_url":"fake-url","alt_text":"fake-alt"},{"type":"mrkdwn","text":"fake-context"}]},` +
+		`{"type":"actions","elements":[{"type":"button","text":{"type":"plain_text","text":"fake-button","emoji":true},"url":"fake-link"}]}]}`
+
+	// act
+	b, err := json.Marshal(reqBody)
+
+	// assert
+	assert.Nil(t, err, "err should be nil")
+	assert.Equal(t, expected, string(b))
+}
+
+func TestPostSlackBlocksInvalidSlackAPI(t *testing.T) {
+	// arrange
+	stubClient := getPostJSONClientWithResponse(getErrorResponse(), http.StatusOK)
+	s := getSlack(stubClient)
+
+	// act
+	_, err := s.PostSlackBlocks("", "", nil, nil, nil)
+
+	// assert
+	assert.NotNil(t, err, "err should not be nil")
+	assert.Equal(t, "Invalid Slack API: fake-error", err.Error(), "err should be fake-error")
+}
+
+func TestUpdateSlackMessage(t *testing.T) {
+	// arrange
+	atm := &Attachment{
+		Color: "fake-color",
+		Title: "fake-title",
+		Text:  "fake-text",
+	}
+	blocks := []Block{SectionBlock("fake-section")}
+	expected := &messageRequest{
+		Channel:     "fake-channel",
+		TS:          "fake-ts",
+		Text:        "fake-text",
+		Attachments: []*Attachment{atm},
+		Blocks:      blocks,
+	}
+	stubClient := getPostJSONClientWithResponse(getOKResponse(), http.StatusOK)
+	s := getSlack(stubClient)
+
+	// act
+	_, err := s.UpdateSlackMessage("fake-channel", "fake-ts", "fake-text", atm, blocks)
+
+	// assert
+	assert.Nil(t, err, "err should be nil")
+	stubClient.AssertCalled(t, "PostJSON", "/chat.update", getBearerHeader(""), mapNil, expected)
+}
+
+func TestUpdateSlackMessageResponseError(t *testing.T) {
+	// arrange
+	stubClient := getPostJSONClientWithResponse([]byte(`fake-body`), http.StatusNotFound)
+	s := getSlack(stubClient)
+
+	// act
+	_, err := s.UpdateSlackMessage("", "", "", nil, nil)
+
+	// assert
+	assert.NotNil(t, err, "Error should not be nil")
+	assert.Equal(t, "HTTP response error: fake-body", err.Error(), "Error message should be equal")
+}
+
+func TestUpdateSlackMessageInvalidSlackAPI(t *testing.T) {
+	// arrange
+	stubClient := getPostJSONClientWithResponse(getErrorResponse(), http.StatusOK)
+	s := getSlack(stubClient)
+
+	// act
+	_, err := s.UpdateSlackMessage("", "", "", nil, nil)
+
+	// assert
+	assert.NotNil(t, err, "err should not be nil")
+	assert.Equal(t, "Invalid Slack API: fake-error", err.Error(), "err should be fake-error")
+}
+
+func TestRespondEphemeral(t *testing.T) {
+	// arrange
+	expected := map[string]interface{}{
+		"response_type":    "ephemeral",
+		"replace_original": false,
+		"text":             "fake-text",
+	}
+	stubClient := getPostJSONClientWithResponse([]byte(`{"ok": true}`), http.StatusOK)
+	s := getSlack(stubClient)
+
+	// act
+	err := s.RespondEphemeral("https://hooks.slack.com/actions/fake", "fake-text")
+
+	// assert
+	assert.Nil(t, err, "err should be nil")
+	stubClient.AssertCalled(t, "PostJSON", "https://hooks.slack.com/actions/fake", mapNil, mapNil, expected)
+}
+
+func TestRespondEphemeralResponseError(t *testing.T) {
+	// arrange
+	stubClient := getPostJSONClientWithResponse([]byte(`expired_url`), http.StatusNotFound)
+	s := getSlack(stubClient)
+
+	// act
+	err := s.RespondEphemeral("https://hooks.slack.com/actions/fake", "fake-text")
+
+	// assert
+	assert.NotNil(t, err, "err should not be nil")
+	assert.Equal(t, "HTTP response error: expired_url", err.Error())
+}
@@ -11,6 +11,62 @@ type Slack struct {
 	mock.Mock
 }
 
+// AddReaction provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Slack) AddReaction(_a0 string, _a1 string, _a2 string) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetChannelID provides a mock function with given fields: _a0
+func (_m *Slack) GetChannelID(_a0 string) (string, error) {
+	ret := _m.Called(_a0)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPermalink provides a mock function with given fields: _a0, _a1
+func (_m *Slack) GetPermalink(_a0 string, _a1 string) (string, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string, string) string); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUser provides a mock function with given fields:
 func (_m *Slack) GetUser() ([]*slack.SlackUser, error) {
 	ret := _m.Called()
@@ -34,6 +90,57 @@ func (_m *Slack) GetUser() ([]*slack.SlackUser, error) {
 	return r0, r1
 }
 
+// OpenConversation provides a mock function with given fields: _a0
+func (_m *Slack) OpenConversation(_a0 string) (string, error) {
+	ret := _m.Called(_a0)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PostSlackBlocks provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4, _a5
+func (_m *Slack) PostSlackBlocks(_a0 string, _a1 string, _a2 *model.User, _a3 *slack.Attachment, _a4 []slack.Block, _a5 ...string) (*slack.MessageResponse, error) {
+	_va := make([]interface{}, len(_a5))
+	for _i := range _a5 {
+		_va[_i] = _a5[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1, _a2, _a3, _a4)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 *slack.MessageResponse
+	if rf, ok := ret.Get(0).(func(string, string, *model.User, *slack.Attachment, []slack.Block, ...string) *slack.MessageResponse); ok {
+		r0 = rf(_a0, _a1, _a2, _a3, _a4, _a5...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*slack.MessageResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, *model.User, *slack.Attachment, []slack.Block, ...string) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3, _a4, _a5...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // PostSlackMessage provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4
 func (_m *Slack) PostSlackMessage(_a0 string, _a1 string, _a2 *model.User, _a3 *slack.Attachment, _a4 ...string) (*slack.MessageResponse, error) {
 	_va := make([]interface{}, len(_a4))
@@ -63,3 +170,54 @@ func (_m *Slack) PostSlackMessage(_a0 string, _a1 string, _a2 *model.User, _a3 *
 
 	return r0, r1
 }
+
+// RemoveReaction provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Slack) RemoveReaction(_a0 string, _a1 string, _a2 string) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RespondEphemeral provides a mock function with given fields: _a0, _a1
+func (_m *Slack) RespondEphemeral(_a0 string, _a1 string) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateSlackMessage provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4
+func (_m *Slack) UpdateSlackMessage(_a0 string, _a1 string, _a2 string, _a3 *slack.Attachment, _a4 []slack.Block) (*slack.MessageResponse, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3, _a4)
+
+	var r0 *slack.MessageResponse
+	if rf, ok := ret.Get(0).(func(string, string, string, *slack.Attachment, []slack.Block) *slack.MessageResponse); ok {
+		r0 = rf(_a0, _a1, _a2, _a3, _a4)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*slack.MessageResponse)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, string, *slack.Attachment, []slack.Block) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3, _a4)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,36 @@
+package slack
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AddReaction adds emoji to message ts in channel, adding an existing reaction is not an error
+func (s *slack) AddReaction(channel, ts, emoji string) error {
+	return s.react("/reactions.add", channel, ts, emoji, "already_reacted")
+}
+
+// RemoveReaction removes emoji from message ts in channel, removing a missing reaction is not an error
+func (s *slack) RemoveReaction(channel, ts, emoji string) error {
+	return s.react("/reactions.remove", channel, ts, emoji, "no_reaction")
+}
+
+func (s *slack) react(api, channel, ts, emoji, ignored string) error {
+	reqBody := map[string]string{
+		"token":     s.SlackToken,
+		"channel":   channel,
+		"timestamp": ts,
+		"name":      emoji,
+	}
+	smr, err := s.callAPI(api, reqBody)
+	if err != nil {
+		return err
+	}
+	if !smr.OK && smr.Err != ignored {
+		err := fmt.Errorf("Invalid Slack API: %v", smr.Err)
+		logrus.Errorln(err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package slack
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddReaction(t *testing.T) {
+	// arrange
+	expected := map[string]string{
+		"token":     "",
+		"channel":   "fake-channel",
+		"timestamp": "fake-ts",
+		"name":      "white_check_mark",
+	}
+	stubClient := getPostClientWithRequestBody(getOKResponse(), http.StatusOK, expected)
+	s := getSlack(stubClient)
+
+	// act
+	err := s.AddReaction("fake-channel", "fake-ts", "white_check_mark")
+
+	// assert
+	assert.Nil(t, err, "err should be nil")
+	stubClient.AssertCalled(t, "Post", "/reactions.add", getURLEncodedHeader(), mapNil, expected)
+}
+
+func TestAddReactionAlreadyReacted(t *testing.T) {
+	// arrange
+	stubClient := getPostClientWithResponse([]byte(`{"ok": false, "error": "already_reacted"}`), http.StatusOK)
+	s := getSlack(stubClient)
+
+	// act
+	err := s.AddReaction("", "", "")
+
+	// assert
+	assert.Nil(t, err, "err should be nil")
+}
+
+func TestRemoveReactionNoReaction(t *testing.T) {
+	// arrange
+	stubClient := getPostClientWithResponse([]byte(`{"ok": false, "error": "no_reaction"}`), http.StatusOK)
+	s := getSlack(stubClient)
+
+	// act
+	err := s.RemoveReaction("", "", "")
+
+	// assert
+	assert.Nil(t, err, "err should be nil")
+	stubClient.AssertCalled(t, "Post", "/reactions.remove", getURLEncodedHeader(), mapNil, map[string]string{
+		"token":     "",
+		"channel":   "",
+		"timestamp": "",
+		"name":      "",
+	})
+}
+
+func TestAddReactionInvalidSlackAPI(t *testing.T) {
+	// arrange
+	stubClient := getPostClientWithResponse(getErrorResponse(), http.StatusOK)
+	s := getSlack(stubClient)
+
+	// act
+	err := s.AddReaction("", "", "")
+
+	// assert
+	assert.NotNil(t, err, "err should not be nil")
+	assert.Equal(t, "Invalid Slack API: fake-error", err.Error(), "err should be fake-error")
+}
@@ -11,6 +11,14 @@ import (
 type Slack interface {
 	GetUser() ([]*SlackUser, error)
 	PostSlackMessage(string, string, *model.User, *Attachment, ...string) (*MessageResponse, error)
+	PostSlackBlocks(string, string, *model.User, *Attachment, []Block, ...string) (*MessageResponse, error)
+	UpdateSlackMessage(string, string, string, *Attachment, []Block) (*MessageResponse, error)
+	AddReaction(string, string, string) error
+	RemoveReaction(string, string, string) error
+	OpenConversation(string) (string, error)
+	GetChannelID(string) (string, error)
+	GetPermalink(string, string) (string, error)
+	RespondEphemeral(string, string) error
 }
 
 type slack struct {
@@ -186,3 +186,22 @@ func getPostClientWithError(errMsg string) *mocks.Client {
 
 	return stubClient
 }
+
+func getBearerHeader(token string) map[string]string {
+	return map[string]string{
+		"Authorization": "Bearer " + token,
+	}
+}
+
+func getPostJSONClientWithResponse(stubByte []byte, statusCode int) *mocks.Client {
+	stubReponse := getResponse(stubByte, statusCode)
+	stubClient := getClient()
+	stubClient.On(
+		"PostJSON",
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		mock.Anything).Return(stubReponse, nil)
+
+	return stubClient
+}
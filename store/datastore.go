@@ -1,6 +1,7 @@
 package store
 
 import (
+	"strings"
 	"time"
 
 	"github.com/urfave/cli"
@@ -98,6 +99,17 @@ func (ds *datastore) GetUserByID(id int) (*model.User, error) {
 	return &u, nil
 }
 
+func (ds *datastore) GetUserBySlackID(id string) (*model.User, error) {
+	var u model.User
+	err := ds.Get(&u, "SELECT * FROM User WHERE slack_id = ?", id)
+	if err != nil {
+		logrus.Debugf("GetUserBySlackID fail, id: %v", id)
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return &u, nil
+}
+
 func (ds *datastore) GetMergeRequest(projectID, mrNum int) (*model.MergeRequest, error) {
 	var mr model.MergeRequest
 	err := ds.Get(&mr, "SELECT * FROM MergeRequest WHERE project_id = ? and mr_num = ?", projectID, mrNum)
@@ -120,6 +132,271 @@ func (ds *datastore) GetIssue(projectID, issueNum int) (*model.Issue, error) {
 	return &issue, nil
 }
 
+// GetThreadByTS returns the merge request or issue whose primary or mirror thread is ts in channel
+func (ds *datastore) GetThreadByTS(channel, ts string) (*model.Thread, error) {
+	sql := `
+SELECT project_id, 'merge_request' AS object_kind, mr_num AS object_num, channel, thread_ts FROM MergeRequest
+WHERE channel = ? AND thread_ts = ?
+UNION ALL
+SELECT project_id, 'issue' AS object_kind, issue_num AS object_num, channel, thread_ts FROM Issue
+WHERE channel = ? AND thread_ts = ?
+UNION ALL
+SELECT project_id, object_kind, object_num, channel, thread_ts FROM Thread
+WHERE channel = ? AND thread_ts = ?
+LIMIT 1
+`
+	var t model.Thread
+	err := ds.Get(&t, sql, channel, ts, channel, ts, channel, ts)
+	if err != nil {
+		logrus.Debugf("GetThreadByTS fail, channel: %v, ts: %v", channel, ts)
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (ds *datastore) GetSlackNote(marker string) (*model.SlackNote, error) {
+	var n model.SlackNote
+	err := ds.Get(&n, "SELECT * FROM SlackNote WHERE marker = ?", marker)
+	if err != nil {
+		logrus.Debugf("GetSlackNote fail, marker: %v", marker)
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return &n, nil
+}
+
+func (ds *datastore) GetOutboxMessage(id int) (*model.OutboxMessage, error) {
+	var msg model.OutboxMessage
+	err := ds.Get(&msg, "SELECT * FROM Outbox WHERE id = ?", id)
+	if err != nil {
+		logrus.Debugf("GetOutboxMessage fail, id: %v", id)
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func (ds *datastore) GetPendingOutboxMessage(kind string, projectID, num int) (*model.OutboxMessage, error) {
+	var msg model.OutboxMessage
+	err := ds.Get(&msg, "SELECT * FROM Outbox WHERE status = ? AND object_kind = ? AND project_id = ? AND object_num = ? ORDER BY id LIMIT 1", model.OutboxPending, kind, projectID, num)
+	if err != nil {
+		logrus.Debugf("GetPendingOutboxMessage fail, kind: %v, project_id: %v, num: %v", kind, projectID, num)
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func (ds *datastore) ListOutboxMessages(status string) ([]*model.OutboxMessage, error) {
+	msgs := []*model.OutboxMessage{}
+	err := ds.Select(&msgs, "SELECT * FROM Outbox WHERE status = ? ORDER BY id", status)
+	if err != nil {
+		logrus.Debugf("ListOutboxMessages fail, status: %v", status)
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return msgs, nil
+}
+
+func (ds *datastore) ListDueOutboxMessages(now time.Time) ([]*model.OutboxMessage, error) {
+	msgs := []*model.OutboxMessage{}
+	err := ds.Select(&msgs, "SELECT * FROM Outbox WHERE status = ? AND next_attempt_at <= ? ORDER BY id", model.OutboxPending, now)
+	if err != nil {
+		logrus.Debugf("ListDueOutboxMessages fail, now: %v", now)
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return msgs, nil
+}
+
+func (ds *datastore) GetWebhookEvent(id int) (*model.WebhookEvent, error) {
+	var e model.WebhookEvent
+	err := ds.Get(&e, "SELECT * FROM WebhookEvent WHERE id = ?", id)
+	if err != nil {
+		logrus.Debugf("GetWebhookEvent fail, id: %v", id)
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return &e, nil
+}
+
+// SearchWebhookEvents returns the latest events matching filter
+func (ds *datastore) SearchWebhookEvents(f *model.WebhookEventFilter) ([]*model.WebhookEvent, error) {
+	var conds []string
+	var args []interface{}
+	if f.ProjectID != 0 {
+		conds = append(conds, "project_id = ?")
+		args = append(args, f.ProjectID)
+	}
+	if f.Event != "" {
+		conds = append(conds, "event = ?")
+		args = append(args, f.Event)
+	}
+	if f.Status != "" {
+		conds = append(conds, "status = ?")
+		args = append(args, f.Status)
+	}
+	sql := "SELECT * FROM WebhookEvent"
+	if len(conds) != 0 {
+		sql += " WHERE " + strings.Join(conds, " AND ")
+	}
+	sql += " ORDER BY id DESC LIMIT ?"
+	args = append(args, f.Limit)
+
+	events := []*model.WebhookEvent{}
+	err := ds.Select(&events, sql, args...)
+	if err != nil {
+		logrus.Debugf("SearchWebhookEvents fail, filter: %v", f)
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return events, nil
+}
+
+// ListTemplates returns the templates of event among paths, the longest path comes first
+func (ds *datastore) ListTemplates(event string, paths []string) ([]*model.Template, error) {
+	templates := []*model.Template{}
+	if len(paths) == 0 {
+		return templates, nil
+	}
+	sql, args, err := sqlx.In("SELECT * FROM Template WHERE event = ? AND path IN (?) ORDER BY length(path) DESC", event, paths)
+	if err != nil {
+		logrus.Errorln(err)
+		return nil, err
+	}
+	err = ds.Select(&templates, ds.Rebind(sql), args...)
+	if err != nil {
+		logrus.Debugf("ListTemplates fail, event: %v, paths: %v", event, paths)
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return templates, nil
+}
+
+// ListRoutes returns the routing rules among paths, rules of the longest path come first in order of position
+func (ds *datastore) ListRoutes(paths []string) ([]*model.Route, error) {
+	routes := []*model.Route{}
+	if len(paths) == 0 {
+		return routes, nil
+	}
+	sql, args, err := sqlx.In("SELECT * FROM Route WHERE path IN (?) ORDER BY length(path) DESC, position", paths)
+	if err != nil {
+		logrus.Errorln(err)
+		return nil, err
+	}
+	err = ds.Select(&routes, ds.Rebind(sql), args...)
+	if err != nil {
+		logrus.Debugf("ListRoutes fail, paths: %v", paths)
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return routes, nil
+}
+
+// ListThreads returns the mirror threads of merge request or issue in order of creation
+func (ds *datastore) ListThreads(kind string, projectID, num int) ([]*model.Thread, error) {
+	threads := []*model.Thread{}
+	err := ds.Select(&threads, "SELECT * FROM Thread WHERE object_kind = ? AND project_id = ? AND object_num = ? ORDER BY id", kind, projectID, num)
+	if err != nil {
+		logrus.Debugf("ListThreads fail, kind: %v, projectID: %v, num: %v", kind, projectID, num)
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return threads, nil
+}
+
+func (ds *datastore) ListQueuedNotifications() ([]*model.QueuedNotification, error) {
+	notifications := []*model.QueuedNotification{}
+	err := ds.Select(&notifications, "SELECT * FROM QueuedNotification ORDER BY gitlab_id, id")
+	if err != nil {
+		logrus.Debugln("ListQueuedNotifications fail")
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (ds *datastore) ListReviewDigests() ([]*model.ReviewDigest, error) {
+	digests := []*model.ReviewDigest{}
+	err := ds.Select(&digests, "SELECT * FROM ReviewDigest ORDER BY channel")
+	if err != nil {
+		logrus.Debugln("ListReviewDigests fail")
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return digests, nil
+}
+
+// ListOpenMergeRequests returns the open merge requests which have a thread in channel, either the primary or a mirror one
+func (ds *datastore) ListOpenMergeRequests(channel string) ([]*model.MergeRequest, error) {
+	sql := `
+SELECT * FROM MergeRequest mr WHERE state = 'opened' AND (channel = ? OR EXISTS (
+    SELECT 1 FROM Thread t
+    WHERE t.object_kind = 'merge_request' AND t.project_id = mr.project_id AND t.object_num = mr.mr_num AND t.channel = ?
+)) ORDER BY id
+`
+	mrs := []*model.MergeRequest{}
+	err := ds.Select(&mrs, sql, channel, channel)
+	if err != nil {
+		logrus.Debugf("ListOpenMergeRequests fail, channel: %v", channel)
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return mrs, nil
+}
+
+// ListProjectsByChannel returns the projects whose default channel is the channel of either id or name
+func (ds *datastore) ListProjectsByChannel(id, name string) ([]*model.Project, error) {
+	projects := []*model.Project{}
+	err := ds.Select(&projects, "SELECT * FROM Project WHERE default_channel IN (?, ?) ORDER BY name", id, name)
+	if err != nil {
+		logrus.Debugf("ListProjectsByChannel fail, id: %v, name: %v", id, name)
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return projects, nil
+}
+
+// ListIdleMergeRequests returns the open merge requests of projects which remind or escalate them
+func (ds *datastore) ListIdleMergeRequests() ([]*model.MergeRequest, error) {
+	sql := `
+SELECT mr.* FROM MergeRequest mr JOIN Project p ON p.id = mr.project_id
+WHERE mr.state = 'opened' AND (p.stale_after > 0 OR p.escalate_after > 0) ORDER BY mr.id
+`
+	mrs := []*model.MergeRequest{}
+	err := ds.Select(&mrs, sql)
+	if err != nil {
+		logrus.Debugln("ListIdleMergeRequests fail")
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return mrs, nil
+}
+
+func (ds *datastore) ListWebhookEvents(status string) ([]*model.WebhookEvent, error) {
+	events := []*model.WebhookEvent{}
+	err := ds.Select(&events, "SELECT * FROM WebhookEvent WHERE status = ? ORDER BY id", status)
+	if err != nil {
+		logrus.Debugf("ListWebhookEvents fail, status: %v", status)
+		logrus.Errorln(err)
+		return nil, err
+	}
+	return events, nil
+}
+
+// CountProjectWebhookSecrets returns the number of projects having their own webhook secret
+func (ds *datastore) CountProjectWebhookSecrets() (int, error) {
+	var n int
+	err := ds.Get(&n, "SELECT COUNT(*) FROM Project WHERE webhook_secret != ''")
+	if err != nil {
+		logrus.Debugln("CountProjectWebhookSecrets fail")
+		logrus.Errorln(err)
+		return 0, err
+	}
+	return n, nil
+}
+
 func (ds *datastore) UpdateUserDefaultChannel(email, channel string) error {
 	_, err := ds.Exec("UPDATE User SET default_channel=? WHERE email=?", channel, email)
 	if err != nil {
@@ -130,6 +407,30 @@ func (ds *datastore) UpdateUserDefaultChannel(email, channel string) error {
 	return nil
 }
 
+func (ds *datastore) UpdateUserPreferences(u *model.User) error {
+	sql := `
+UPDATE User SET delivery=:delivery, muted_events=:muted_events, muted_projects=:muted_projects,
+time_zone=:time_zone, quiet_start=:quiet_start, quiet_end=:quiet_end WHERE email=:email
+`
+	_, err := ds.NamedExec(sql, u)
+	if err != nil {
+		logrus.Debugf("UpdateUserPreferences fail, user: %+v", u)
+		logrus.Errorln(err)
+		return err
+	}
+	return nil
+}
+
+func (ds *datastore) UpdateReviewDigestLastRun(channel string, t time.Time) error {
+	_, err := ds.Exec("UPDATE ReviewDigest SET last_run_at=? WHERE channel=?", t, channel)
+	if err != nil {
+		logrus.Debugf("UpdateReviewDigestLastRun fail, channel: %v, time: %v", channel, t)
+		logrus.Errorln(err)
+		return err
+	}
+	return nil
+}
+
 func (ds *datastore) UpdateProjectDefaultChannel(name, channel string) error {
 	_, err := ds.Exec("UPDATE Project SET default_channel=? WHERE name=?", channel, name)
 	if err != nil {
@@ -150,6 +451,120 @@ func (ds *datastore) UpdateGroupDefaultChannel(name, channel string) error {
 	return nil
 }
 
+func (ds *datastore) UpdateProjectSettings(p *model.Project) error {
+	sql := `
+UPDATE Project SET default_channel=:default_channel, webhook_secret=:webhook_secret, watched_branches=:watched_branches,
+    reactions=:reactions, stale_after=:stale_after, escalate_after=:escalate_after, escalate_to=:escalate_to
+WHERE id=:id
+`
+	_, err := ds.NamedExec(sql, p)
+	if err != nil {
+		logrus.Debugf("UpdateProjectSettings fail, name: %v", p.Name)
+		logrus.Errorln(err)
+		return err
+	}
+	return nil
+}
+
+func (ds *datastore) UpdateMergeRequestActivity(projectID, mrNum int, t time.Time) error {
+	_, err := ds.Exec("UPDATE MergeRequest SET last_activity_at=? WHERE project_id=? AND mr_num=?", t, projectID, mrNum)
+	if err != nil {
+		logrus.Debugf("UpdateMergeRequestActivity fail, projectID: %v, mrNum: %v", projectID, mrNum)
+		logrus.Errorln(err)
+		return err
+	}
+	return nil
+}
+
+func (ds *datastore) UpdateMergeRequestReminders(mr *model.MergeRequest) error {
+	sql := `
+UPDATE MergeRequest SET reminded_at=:reminded_at, escalated_at=:escalated_at
+WHERE project_id=:project_id AND mr_num=:mr_num
+`
+	_, err := ds.NamedExec(sql, mr)
+	if err != nil {
+		logrus.Debugf("UpdateMergeRequestReminders fail, model.MergeRequest: %v", mr)
+		logrus.Errorln(err)
+		return err
+	}
+	return nil
+}
+
+func (ds *datastore) UpdateMergeRequest(mr *model.MergeRequest) error {
+	sql := `
+UPDATE MergeRequest SET text=:text, state=:state, pipeline_status=:pipeline_status, blocks=:blocks
+WHERE project_id=:project_id AND mr_num=:mr_num
+`
+	_, err := ds.NamedExec(sql, mr)
+	if err != nil {
+		logrus.Debugf("UpdateMergeRequest fail, model.MergeRequest: %v", mr)
+		logrus.Errorln(err)
+		return err
+	}
+	return nil
+}
+
+func (ds *datastore) UpdateOutboxMessage(msg *model.OutboxMessage) error {
+	sql := `
+UPDATE Outbox SET status=:status, attempts=:attempts, next_attempt_at=:next_attempt_at, last_error=:last_error
+WHERE id=:id
+`
+	_, err := ds.NamedExec(sql, msg)
+	if err != nil {
+		logrus.Debugf("UpdateOutboxMessage fail, model.OutboxMessage: %v", msg)
+		logrus.Errorln(err)
+		return err
+	}
+	return nil
+}
+
+func (ds *datastore) UpdateWebhookEvent(e *model.WebhookEvent) error {
+	_, err := ds.NamedExec("UPDATE WebhookEvent SET status=:status, error=:error, processed_at=:processed_at WHERE id=:id", e)
+	if err != nil {
+		logrus.Debugf("UpdateWebhookEvent fail, id: %v", e.ID)
+		logrus.Errorln(err)
+		return err
+	}
+	return nil
+}
+
+// ReplaceRoutes replaces all routing rules of path with routes, the position of rule is its index
+func (ds *datastore) ReplaceRoutes(path string, routes []*model.Route) error {
+	tx, err := ds.Beginx()
+	if err != nil {
+		logrus.Errorln(err)
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM Route WHERE path = ?", path)
+	if err != nil {
+		tx.Rollback()
+		logrus.Debugf("ReplaceRoutes fail, path: %v", path)
+		logrus.Errorln(err)
+		return err
+	}
+	sql := `
+INSERT INTO Route (path, position, event, labels, source_branch, target_branch, file_paths, authors, channels, created_at)
+VALUES (:path, :position, :event, :labels, :source_branch, :target_branch, :file_paths, :authors, :channels, :created_at)
+`
+	for i, r := range routes {
+		r.Path = path
+		r.Position = i
+		_, err = tx.NamedExec(sql, r)
+		if err != nil {
+			tx.Rollback()
+			logrus.Debugf("ReplaceRoutes fail, model.Route: %v", r)
+			logrus.Errorln(err)
+			return err
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		logrus.Errorln(err)
+		return err
+	}
+	return nil
+}
+
 func (ds *datastore) CreateUser(u *model.User) error {
 	sql := `
 INSERT INTO User (gitlab_id, email, slack_id, name, avatar_url)
@@ -182,9 +597,10 @@ ON CONFLICT(id) DO UPDATE SET name=:name
 
 func (ds *datastore) CreateMergeRequest(mr *model.MergeRequest) error {
 	sql := `
-INSERT INTO MergeRequest (project_id, mr_num, thread_ts, channel)
-VALUES (:project_id, :mr_num, :thread_ts, :channel)
-ON CONFLICT(project_id, mr_num) DO UPDATE SET thread_ts=:thread_ts, channel=:channel
+INSERT INTO MergeRequest (project_id, mr_num, thread_ts, channel, text, state, pipeline_status, blocks, last_activity_at)
+VALUES (:project_id, :mr_num, :thread_ts, :channel, :text, :state, :pipeline_status, :blocks, :last_activity_at)
+ON CONFLICT(project_id, mr_num) DO UPDATE SET thread_ts=:thread_ts, channel=:channel, text=:text, state=:state, pipeline_status=:pipeline_status, blocks=:blocks,
+    last_activity_at=:last_activity_at
 `
 	_, err := ds.NamedExec(sql, mr)
 	if err != nil {
@@ -209,3 +625,212 @@ ON CONFLICT(project_id, issue_num) DO UPDATE SET thread_ts=:thread_ts, channel=:
 	}
 	return nil
 }
+
+func (ds *datastore) CreateOutboxMessage(msg *model.OutboxMessage) error {
+	sql := `
+INSERT INTO Outbox (channel, text, author_id, attachment, blocks, thread_ts, project_id, object_kind, object_num, thread_kind, event, status, attempts, next_attempt_at, last_error, created_at)
+VALUES (:channel, :text, :author_id, :attachment, :blocks, :thread_ts, :project_id, :object_kind, :object_num, :thread_kind, :event, :status, :attempts, :next_attempt_at, :last_error, :created_at)
+`
+	res, err := ds.NamedExec(sql, msg)
+	if err != nil {
+		logrus.Debugf("CreateOutboxMessage fail, model.OutboxMessage: %v", msg)
+		logrus.Errorln(err)
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		logrus.Errorln(err)
+		return err
+	}
+	msg.ID = int(id)
+	return nil
+}
+
+func (ds *datastore) CreateWebhookEvent(e *model.WebhookEvent) error {
+	sql := `
+INSERT INTO WebhookEvent (event, project_id, object_key, uuid, headers, payload, status, error, created_at, processed_at)
+VALUES (:event, :project_id, :object_key, :uuid, :headers, :payload, :status, :error, :created_at, :processed_at)
+`
+	res, err := ds.NamedExec(sql, e)
+	if err != nil {
+		logrus.Debugf("CreateWebhookEvent fail, event: %v, project_id: %v", e.Event, e.ProjectID)
+		logrus.Errorln(err)
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		logrus.Errorln(err)
+		return err
+	}
+	e.ID = int(id)
+	return nil
+}
+
+// CreateEventUUID records the UUID of webhook event, it returns false if the UUID is already recorded
+func (ds *datastore) CreateEventUUID(uuid string, createdAt time.Time) (bool, error) {
+	res, err := ds.Exec("INSERT OR IGNORE INTO EventUUID (uuid, created_at) VALUES (?, ?)", uuid, createdAt)
+	if err != nil {
+		logrus.Debugf("CreateEventUUID fail, uuid: %v", uuid)
+		logrus.Errorln(err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		logrus.Errorln(err)
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (ds *datastore) CreateTemplate(t *model.Template) error {
+	sql := `
+INSERT INTO Template (path, event, text, updated_at)
+VALUES (:path, :event, :text, :updated_at)
+ON CONFLICT(path, event) DO UPDATE SET text=:text, updated_at=:updated_at
+`
+	_, err := ds.NamedExec(sql, t)
+	if err != nil {
+		logrus.Debugf("CreateTemplate fail, model.Template: %v", t)
+		logrus.Errorln(err)
+		return err
+	}
+	return nil
+}
+
+// CreateThread records the mirror thread, the thread of the same channel is replaced
+func (ds *datastore) CreateThread(t *model.Thread) error {
+	sql := `
+INSERT INTO Thread (project_id, object_kind, object_num, channel, thread_ts, created_at)
+VALUES (:project_id, :object_kind, :object_num, :channel, :thread_ts, :created_at)
+ON CONFLICT(project_id, object_kind, object_num, channel) DO UPDATE SET thread_ts=:thread_ts, created_at=:created_at
+`
+	_, err := ds.NamedExec(sql, t)
+	if err != nil {
+		logrus.Debugf("CreateThread fail, model.Thread: %v", t)
+		logrus.Errorln(err)
+		return err
+	}
+	return nil
+}
+
+func (ds *datastore) CreateQueuedNotification(n *model.QueuedNotification) error {
+	sql := `
+INSERT INTO QueuedNotification (gitlab_id, text, created_at)
+VALUES (:gitlab_id, :text, :created_at)
+`
+	_, err := ds.NamedExec(sql, n)
+	if err != nil {
+		logrus.Debugf("CreateQueuedNotification fail, model.QueuedNotification: %v", n)
+		logrus.Errorln(err)
+		return err
+	}
+	return nil
+}
+
+func (ds *datastore) CreateReviewDigest(d *model.ReviewDigest) error {
+	sql := `
+INSERT INTO ReviewDigest (channel, schedule, last_run_at, created_at)
+VALUES (:channel, :schedule, :last_run_at, :created_at)
+ON CONFLICT(channel) DO UPDATE SET schedule=:schedule, last_run_at=:last_run_at
+`
+	_, err := ds.NamedExec(sql, d)
+	if err != nil {
+		logrus.Debugf("CreateReviewDigest fail, model.ReviewDigest: %v", d)
+		logrus.Errorln(err)
+		return err
+	}
+	return nil
+}
+
+func (ds *datastore) CreateSlackNote(n *model.SlackNote) error {
+	sql := `
+INSERT INTO SlackNote (marker, project_id, author_id, created_at)
+VALUES (:marker, :project_id, :author_id, :created_at)
+`
+	_, err := ds.NamedExec(sql, n)
+	if err != nil {
+		logrus.Debugf("CreateSlackNote fail, model.SlackNote: %v", n)
+		logrus.Errorln(err)
+		return err
+	}
+	return nil
+}
+
+func (ds *datastore) DeleteEventUUIDsBefore(t time.Time) (int64, error) {
+	res, err := ds.Exec("DELETE FROM EventUUID WHERE created_at < ?", t)
+	if err != nil {
+		logrus.Debugf("DeleteEventUUIDsBefore fail, time: %v", t)
+		logrus.Errorln(err)
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		logrus.Errorln(err)
+		return 0, err
+	}
+	return n, nil
+}
+
+// DeleteWebhookEventsBefore removes the events processed before t, the queued ones are kept
+func (ds *datastore) DeleteWebhookEventsBefore(t time.Time) (int64, error) {
+	res, err := ds.Exec("DELETE FROM WebhookEvent WHERE status != ? AND processed_at < ?", model.EventQueued, t)
+	if err != nil {
+		logrus.Debugf("DeleteWebhookEventsBefore fail, time: %v", t)
+		logrus.Errorln(err)
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		logrus.Errorln(err)
+		return 0, err
+	}
+	return n, nil
+}
+
+func (ds *datastore) DeleteTemplate(path, event string) (int64, error) {
+	res, err := ds.Exec("DELETE FROM Template WHERE path = ? AND event = ?", path, event)
+	if err != nil {
+		logrus.Debugf("DeleteTemplate fail, path: %v, event: %v", path, event)
+		logrus.Errorln(err)
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		logrus.Errorln(err)
+		return 0, err
+	}
+	return n, nil
+}
+
+func (ds *datastore) DeleteQueuedNotifications(ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	sql, args, err := sqlx.In("DELETE FROM QueuedNotification WHERE id IN (?)", ids)
+	if err != nil {
+		logrus.Errorln(err)
+		return err
+	}
+	_, err = ds.Exec(ds.Rebind(sql), args...)
+	if err != nil {
+		logrus.Debugf("DeleteQueuedNotifications fail, ids: %v", ids)
+		logrus.Errorln(err)
+		return err
+	}
+	return nil
+}
+
+func (ds *datastore) DeleteReviewDigest(channel string) (int64, error) {
+	res, err := ds.Exec("DELETE FROM ReviewDigest WHERE channel = ?", channel)
+	if err != nil {
+		logrus.Debugf("DeleteReviewDigest fail, channel: %v", channel)
+		logrus.Errorln(err)
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		logrus.Errorln(err)
+		return 0, err
+	}
+	return n, nil
+}
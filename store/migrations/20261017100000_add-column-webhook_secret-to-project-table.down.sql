@@ -0,0 +1,20 @@
+/*
+Sqlite has no way to remove column directly.
+  1. create new table.
+  2. copy all data,
+  3. drop old table,
+  4. rename the new one.
+*/
+CREATE TABLE "TempProjectTable" (
+	"id"	INT,
+	"name"	VARCHAR(255) NOT NULL,
+	"default_channel"	VARCHAR(32) DEFAULT '',
+	PRIMARY KEY("id")
+);
+
+INSERT INTO "main"."TempProjectTable"
+("id","name","default_channel")
+SELECT "id","name","default_channel" FROM "main"."Project";
+
+DROP TABLE "main"."Project";
+ALTER TABLE "main"."TempProjectTable" RENAME TO "Project"
@@ -0,0 +1 @@
+ALTER TABLE "main"."Project" ADD COLUMN "webhook_secret" VARCHAR(255) DEFAULT '';
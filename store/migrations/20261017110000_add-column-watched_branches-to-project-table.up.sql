@@ -0,0 +1 @@
+ALTER TABLE "main"."Project" ADD COLUMN "watched_branches" VARCHAR(255) DEFAULT '';
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS Outbox(
+    id INTEGER PRIMARY KEY,
+    channel VARCHAR(32) NOT NULL,
+    text TEXT NOT NULL DEFAULT '',
+    author_id INTEGER NOT NULL DEFAULT 0,
+    attachment TEXT NOT NULL DEFAULT '',
+    thread_ts CHARACTER(32) NOT NULL DEFAULT '',
+    project_id INTEGER NOT NULL DEFAULT 0,
+    object_kind VARCHAR(16) NOT NULL DEFAULT '',
+    object_num INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS outbox_status_next_attempt_at ON Outbox(status, next_attempt_at);
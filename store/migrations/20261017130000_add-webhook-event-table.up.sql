@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS WebhookEvent(
+    id INTEGER PRIMARY KEY,
+    event VARCHAR(32) NOT NULL,
+    project_id INTEGER NOT NULL DEFAULT 0,
+    object_key VARCHAR(64) NOT NULL DEFAULT '',
+    payload TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'queued',
+    error TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    processed_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS webhook_event_status ON WebhookEvent(status);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS EventUUID(
+    uuid VARCHAR(64) PRIMARY KEY,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS event_uuid_created_at ON EventUUID(created_at);
@@ -0,0 +1,31 @@
+/*
+Sqlite has no way to remove column directly.
+  1. create new table.
+  2. copy all data,
+  3. drop old table,
+  4. rename the new one.
+*/
+DROP INDEX IF EXISTS webhook_event_project_id;
+DROP INDEX IF EXISTS webhook_event_status;
+
+CREATE TABLE "TempWebhookEventTable" (
+	"id"	INTEGER,
+	"event"	VARCHAR(32) NOT NULL,
+	"project_id"	INTEGER NOT NULL DEFAULT 0,
+	"object_key"	VARCHAR(64) NOT NULL DEFAULT '',
+	"payload"	TEXT NOT NULL,
+	"status"	VARCHAR(16) NOT NULL DEFAULT 'queued',
+	"error"	TEXT NOT NULL DEFAULT '',
+	"created_at"	DATETIME NOT NULL,
+	"processed_at"	DATETIME,
+	PRIMARY KEY("id")
+);
+
+INSERT INTO "main"."TempWebhookEventTable"
+("id","event","project_id","object_key","payload","status","error","created_at","processed_at")
+SELECT "id","event","project_id","object_key","payload","status","error","created_at","processed_at" FROM "main"."WebhookEvent";
+
+DROP TABLE "main"."WebhookEvent";
+ALTER TABLE "main"."TempWebhookEventTable" RENAME TO "WebhookEvent";
+
+CREATE INDEX IF NOT EXISTS webhook_event_status ON WebhookEvent(status);
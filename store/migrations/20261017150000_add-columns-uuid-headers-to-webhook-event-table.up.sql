@@ -0,0 +1,4 @@
+ALTER TABLE WebhookEvent ADD COLUMN uuid VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE WebhookEvent ADD COLUMN headers TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS webhook_event_project_id ON WebhookEvent(project_id);
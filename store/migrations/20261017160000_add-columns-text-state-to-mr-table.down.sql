@@ -0,0 +1,22 @@
+/*
+Sqlite has no way to remove column directly.
+  1. create new table.
+  2. copy all data,
+  3. drop old table,
+  4. rename the new one.
+*/
+CREATE TABLE TempMergeRequest(
+    id INTEGER PRIMARY KEY,
+    project_id INTEGER,
+    mr_num INTEGER,
+    thread_ts CHARACTER(32),
+    channel CHARACTER(16),
+    UNIQUE(project_id, mr_num),
+    FOREIGN KEY (project_id) REFERENCES Project(id)
+);
+
+INSERT INTO TempMergeRequest (id, project_id, mr_num, thread_ts, channel)
+    SELECT id, project_id, mr_num, thread_ts, channel FROM MergeRequest;
+
+DROP TABLE MergeRequest;
+ALTER TABLE TempMergeRequest RENAME TO MergeRequest;
@@ -0,0 +1,3 @@
+ALTER TABLE MergeRequest ADD COLUMN text TEXT NOT NULL DEFAULT '';
+ALTER TABLE MergeRequest ADD COLUMN state VARCHAR(16) NOT NULL DEFAULT 'opened';
+ALTER TABLE MergeRequest ADD COLUMN pipeline_status VARCHAR(16) NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE "main"."Project" ADD COLUMN "reactions" VARCHAR(255) DEFAULT '';
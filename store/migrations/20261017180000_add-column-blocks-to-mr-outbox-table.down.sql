@@ -0,0 +1,49 @@
+/*
+Sqlite has no way to remove column directly.
+  1. create new table.
+  2. copy all data,
+  3. drop old table,
+  4. rename the new one.
+*/
+CREATE TABLE TempMergeRequest(
+    id INTEGER PRIMARY KEY,
+    project_id INTEGER,
+    mr_num INTEGER,
+    thread_ts CHARACTER(32),
+    channel CHARACTER(16),
+    text TEXT NOT NULL DEFAULT '',
+    state VARCHAR(16) NOT NULL DEFAULT 'opened',
+    pipeline_status VARCHAR(16) NOT NULL DEFAULT '',
+    UNIQUE(project_id, mr_num),
+    FOREIGN KEY (project_id) REFERENCES Project(id)
+);
+
+INSERT INTO TempMergeRequest (id, project_id, mr_num, thread_ts, channel, text, state, pipeline_status)
+    SELECT id, project_id, mr_num, thread_ts, channel, text, state, pipeline_status FROM MergeRequest;
+
+DROP TABLE MergeRequest;
+ALTER TABLE TempMergeRequest RENAME TO MergeRequest;
+
+CREATE TABLE TempOutbox(
+    id INTEGER PRIMARY KEY,
+    channel VARCHAR(32) NOT NULL,
+    text TEXT NOT NULL DEFAULT '',
+    author_id INTEGER NOT NULL DEFAULT 0,
+    attachment TEXT NOT NULL DEFAULT '',
+    thread_ts CHARACTER(32) NOT NULL DEFAULT '',
+    project_id INTEGER NOT NULL DEFAULT 0,
+    object_kind VARCHAR(16) NOT NULL DEFAULT '',
+    object_num INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL
+);
+
+INSERT INTO TempOutbox (id, channel, text, author_id, attachment, thread_ts, project_id, object_kind, object_num, status, attempts, next_attempt_at, last_error, created_at)
+    SELECT id, channel, text, author_id, attachment, thread_ts, project_id, object_kind, object_num, status, attempts, next_attempt_at, last_error, created_at FROM Outbox;
+
+DROP TABLE Outbox;
+ALTER TABLE TempOutbox RENAME TO Outbox;
+CREATE INDEX IF NOT EXISTS outbox_status_next_attempt_at ON Outbox(status, next_attempt_at);
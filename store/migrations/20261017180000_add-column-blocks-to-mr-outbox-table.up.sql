@@ -0,0 +1,2 @@
+ALTER TABLE MergeRequest ADD COLUMN blocks TEXT NOT NULL DEFAULT '';
+ALTER TABLE Outbox ADD COLUMN blocks TEXT NOT NULL DEFAULT '';
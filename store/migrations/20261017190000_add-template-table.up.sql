@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS Template(
+    id INTEGER PRIMARY KEY,
+    path VARCHAR(255) NOT NULL,
+    event VARCHAR(32) NOT NULL,
+    text TEXT NOT NULL,
+    updated_at DATETIME NOT NULL,
+    UNIQUE(path, event)
+);
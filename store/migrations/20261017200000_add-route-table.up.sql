@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS Route(
+    id INTEGER PRIMARY KEY,
+    path VARCHAR(255) NOT NULL,
+    position INTEGER NOT NULL,
+    event VARCHAR(32) NOT NULL DEFAULT '',
+    labels TEXT NOT NULL DEFAULT '',
+    source_branch TEXT NOT NULL DEFAULT '',
+    target_branch TEXT NOT NULL DEFAULT '',
+    file_paths TEXT NOT NULL DEFAULT '',
+    authors TEXT NOT NULL DEFAULT '',
+    channels TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    UNIQUE(path, position)
+);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS Thread(
+    id INTEGER PRIMARY KEY,
+    project_id INTEGER NOT NULL,
+    object_kind VARCHAR(16) NOT NULL,
+    object_num INTEGER NOT NULL,
+    channel VARCHAR(32) NOT NULL,
+    thread_ts CHARACTER(32) NOT NULL,
+    created_at DATETIME NOT NULL,
+    UNIQUE(project_id, object_kind, object_num, channel)
+);
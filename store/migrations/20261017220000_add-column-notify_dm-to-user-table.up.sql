@@ -0,0 +1 @@
+ALTER TABLE "main"."User" ADD COLUMN "notify_dm" BOOLEAN NOT NULL DEFAULT 0;
@@ -0,0 +1,25 @@
+/*
+Sqlite has no way to remove column directly.
+  1. create new table.
+  2. copy all data,
+  3. drop old table,
+  4. rename the new one.
+*/
+CREATE TABLE "TempUserTable" (
+	"gitlab_id"	INT,
+	"email"	VARCHAR(255) NOT NULL,
+	"slack_id"	VARCHAR(9) NOT NULL,
+	"name"	VARCHAR(255) NOT NULL,
+	"default_channel"	VARCHAR(32) DEFAULT '',
+	"avatar_url"	varchar(255),
+	"notify_dm"	BOOLEAN NOT NULL DEFAULT 0,
+	PRIMARY KEY("gitlab_id")
+);
+
+INSERT INTO "main"."TempUserTable"
+("default_channel","email","gitlab_id","name","slack_id","avatar_url","notify_dm")
+SELECT "default_channel","email","gitlab_id","name","slack_id","avatar_url",
+"delivery" IN ('dm', 'both') FROM "main"."User";
+
+DROP TABLE "main"."User";
+ALTER TABLE "main"."TempUserTable" RENAME TO "User"
@@ -0,0 +1,29 @@
+DROP INDEX IF EXISTS queued_notification_gitlab_id;
+DROP TABLE IF EXISTS QueuedNotification;
+
+/*
+Sqlite has no way to remove column directly.
+  1. create new table.
+  2. copy all data,
+  3. drop old table,
+  4. rename the new one.
+*/
+CREATE TABLE "TempUserTable" (
+	"gitlab_id"	INT,
+	"email"	VARCHAR(255) NOT NULL,
+	"slack_id"	VARCHAR(9) NOT NULL,
+	"name"	VARCHAR(255) NOT NULL,
+	"default_channel"	VARCHAR(32) DEFAULT '',
+	"avatar_url"	varchar(255),
+	"delivery"	VARCHAR(16) NOT NULL DEFAULT 'channel',
+	"muted_events"	VARCHAR(255) NOT NULL DEFAULT '',
+	"muted_projects"	TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY("gitlab_id")
+);
+
+INSERT INTO "main"."TempUserTable"
+("default_channel","email","gitlab_id","name","slack_id","avatar_url","delivery","muted_events","muted_projects")
+SELECT "default_channel","email","gitlab_id","name","slack_id","avatar_url","delivery","muted_events","muted_projects" FROM "main"."User";
+
+DROP TABLE "main"."User";
+ALTER TABLE "main"."TempUserTable" RENAME TO "User"
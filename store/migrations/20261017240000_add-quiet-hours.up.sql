@@ -0,0 +1,12 @@
+ALTER TABLE User ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE User ADD COLUMN quiet_start CHARACTER(5) NOT NULL DEFAULT '';
+ALTER TABLE User ADD COLUMN quiet_end CHARACTER(5) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS QueuedNotification(
+    id INTEGER PRIMARY KEY,
+    gitlab_id INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS queued_notification_gitlab_id ON QueuedNotification(gitlab_id);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS ReviewDigest(
+    channel VARCHAR(32) PRIMARY KEY,
+    schedule VARCHAR(64) NOT NULL,
+    last_run_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL
+);
@@ -0,0 +1,41 @@
+/*
+Sqlite has no way to remove column directly.
+  1. create new table.
+  2. copy all data,
+  3. drop old table,
+  4. rename the new one.
+*/
+CREATE TABLE TempProject (
+	id INT PRIMARY KEY,
+	name VARCHAR(255) NOT NULL,
+	default_channel VARCHAR(32) NULL DEFAULT '',
+	webhook_secret VARCHAR(255) DEFAULT '',
+	watched_branches VARCHAR(255) DEFAULT '',
+	reactions VARCHAR(255) DEFAULT ''
+);
+
+INSERT INTO TempProject (id, name, default_channel, webhook_secret, watched_branches, reactions)
+    SELECT id, name, default_channel, webhook_secret, watched_branches, reactions FROM Project;
+
+DROP TABLE Project;
+ALTER TABLE TempProject RENAME TO Project;
+
+CREATE TABLE TempMergeRequest(
+    id INTEGER PRIMARY KEY,
+    project_id INTEGER,
+    mr_num INTEGER,
+    thread_ts CHARACTER(32),
+    channel CHARACTER(16),
+    text TEXT NOT NULL DEFAULT '',
+    state VARCHAR(16) NOT NULL DEFAULT 'opened',
+    pipeline_status VARCHAR(16) NOT NULL DEFAULT '',
+    blocks TEXT NOT NULL DEFAULT '',
+    UNIQUE(project_id, mr_num),
+    FOREIGN KEY (project_id) REFERENCES Project(id)
+);
+
+INSERT INTO TempMergeRequest (id, project_id, mr_num, thread_ts, channel, text, state, pipeline_status, blocks)
+    SELECT id, project_id, mr_num, thread_ts, channel, text, state, pipeline_status, blocks FROM MergeRequest;
+
+DROP TABLE MergeRequest;
+ALTER TABLE TempMergeRequest RENAME TO MergeRequest;
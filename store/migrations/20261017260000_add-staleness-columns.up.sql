@@ -0,0 +1,8 @@
+ALTER TABLE Project ADD COLUMN stale_after INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE Project ADD COLUMN escalate_after INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE Project ADD COLUMN escalate_to VARCHAR(255) NOT NULL DEFAULT '';
+
+-- merge requests opened before have no known activity until the next one
+ALTER TABLE MergeRequest ADD COLUMN last_activity_at DATETIME NOT NULL DEFAULT '0001-01-01 00:00:00+00:00';
+ALTER TABLE MergeRequest ADD COLUMN reminded_at DATETIME NOT NULL DEFAULT '0001-01-01 00:00:00+00:00';
+ALTER TABLE MergeRequest ADD COLUMN escalated_at DATETIME NOT NULL DEFAULT '0001-01-01 00:00:00+00:00';
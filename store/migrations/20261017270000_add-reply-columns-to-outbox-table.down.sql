@@ -0,0 +1,31 @@
+/*
+Sqlite has no way to remove column directly.
+  1. create new table.
+  2. copy all data,
+  3. drop old table,
+  4. rename the new one.
+*/
+CREATE TABLE TempOutbox(
+    id INTEGER PRIMARY KEY,
+    channel VARCHAR(32) NOT NULL,
+    text TEXT NOT NULL DEFAULT '',
+    author_id INTEGER NOT NULL DEFAULT 0,
+    attachment TEXT NOT NULL DEFAULT '',
+    thread_ts CHARACTER(32) NOT NULL DEFAULT '',
+    project_id INTEGER NOT NULL DEFAULT 0,
+    object_kind VARCHAR(16) NOT NULL DEFAULT '',
+    object_num INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    blocks TEXT NOT NULL DEFAULT ''
+);
+
+INSERT INTO TempOutbox (id, channel, text, author_id, attachment, thread_ts, project_id, object_kind, object_num, status, attempts, next_attempt_at, last_error, created_at, blocks)
+    SELECT id, channel, text, author_id, attachment, thread_ts, project_id, object_kind, object_num, status, attempts, next_attempt_at, last_error, created_at, blocks FROM Outbox;
+
+DROP TABLE Outbox;
+ALTER TABLE TempOutbox RENAME TO Outbox;
+CREATE INDEX IF NOT EXISTS outbox_status_next_attempt_at ON Outbox(status, next_attempt_at);
@@ -0,0 +1,2 @@
+ALTER TABLE Outbox ADD COLUMN thread_kind VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE Outbox ADD COLUMN event TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS SlackNote(
+    marker VARCHAR(32) PRIMARY KEY,
+    project_id INTEGER NOT NULL,
+    author_id INTEGER NOT NULL,
+    created_at DATETIME NOT NULL
+);
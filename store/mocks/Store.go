@@ -4,12 +4,55 @@ package mocks
 
 import mock "github.com/stretchr/testify/mock"
 import model "gitlack/model"
+import time "time"
 
 // Store is an autogenerated mock type for the Store type
 type Store struct {
 	mock.Mock
 }
 
+// CountProjectWebhookSecrets provides a mock function with given fields:
+func (_m *Store) CountProjectWebhookSecrets() (int, error) {
+	ret := _m.Called()
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateEventUUID provides a mock function with given fields: _a0, _a1
+func (_m *Store) CreateEventUUID(_a0 string, _a1 time.Time) (bool, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, time.Time) bool); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, time.Time) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateIssue provides a mock function with given fields: _a0
 func (_m *Store) CreateIssue(_a0 *model.Issue) error {
 	ret := _m.Called(_a0)
@@ -38,6 +81,20 @@ func (_m *Store) CreateMergeRequest(_a0 *model.MergeRequest) error {
 	return r0
 }
 
+// CreateOutboxMessage provides a mock function with given fields: _a0
+func (_m *Store) CreateOutboxMessage(_a0 *model.OutboxMessage) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.OutboxMessage) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreateProject provides a mock function with given fields: _a0
 func (_m *Store) CreateProject(_a0 *model.Project) error {
 	ret := _m.Called(_a0)
@@ -52,6 +109,76 @@ func (_m *Store) CreateProject(_a0 *model.Project) error {
 	return r0
 }
 
+// CreateQueuedNotification provides a mock function with given fields: _a0
+func (_m *Store) CreateQueuedNotification(_a0 *model.QueuedNotification) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.QueuedNotification) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateReviewDigest provides a mock function with given fields: _a0
+func (_m *Store) CreateReviewDigest(_a0 *model.ReviewDigest) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.ReviewDigest) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateSlackNote provides a mock function with given fields: _a0
+func (_m *Store) CreateSlackNote(_a0 *model.SlackNote) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.SlackNote) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateTemplate provides a mock function with given fields: _a0
+func (_m *Store) CreateTemplate(_a0 *model.Template) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.Template) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateThread provides a mock function with given fields: _a0
+func (_m *Store) CreateThread(_a0 *model.Thread) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.Thread) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreateUser provides a mock function with given fields: _a0
 func (_m *Store) CreateUser(_a0 *model.User) error {
 	ret := _m.Called(_a0)
@@ -66,6 +193,118 @@ func (_m *Store) CreateUser(_a0 *model.User) error {
 	return r0
 }
 
+// CreateWebhookEvent provides a mock function with given fields: _a0
+func (_m *Store) CreateWebhookEvent(_a0 *model.WebhookEvent) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.WebhookEvent) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteEventUUIDsBefore provides a mock function with given fields: _a0
+func (_m *Store) DeleteEventUUIDsBefore(_a0 time.Time) (int64, error) {
+	ret := _m.Called(_a0)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(time.Time) int64); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteQueuedNotifications provides a mock function with given fields: _a0
+func (_m *Store) DeleteQueuedNotifications(_a0 []int) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]int) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteReviewDigest provides a mock function with given fields: _a0
+func (_m *Store) DeleteReviewDigest(_a0 string) (int64, error) {
+	ret := _m.Called(_a0)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(string) int64); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteTemplate provides a mock function with given fields: _a0, _a1
+func (_m *Store) DeleteTemplate(_a0 string, _a1 string) (int64, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(string, string) int64); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteWebhookEventsBefore provides a mock function with given fields: _a0
+func (_m *Store) DeleteWebhookEventsBefore(_a0 time.Time) (int64, error) {
+	ret := _m.Called(_a0)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(time.Time) int64); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetIssue provides a mock function with given fields: _a0, _a1
 func (_m *Store) GetIssue(_a0 int, _a1 int) (*model.Issue, error) {
 	ret := _m.Called(_a0, _a1)
@@ -112,6 +351,52 @@ func (_m *Store) GetMergeRequest(_a0 int, _a1 int) (*model.MergeRequest, error)
 	return r0, r1
 }
 
+// GetOutboxMessage provides a mock function with given fields: _a0
+func (_m *Store) GetOutboxMessage(_a0 int) (*model.OutboxMessage, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *model.OutboxMessage
+	if rf, ok := ret.Get(0).(func(int) *model.OutboxMessage); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.OutboxMessage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPendingOutboxMessage provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Store) GetPendingOutboxMessage(_a0 string, _a1 int, _a2 int) (*model.OutboxMessage, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *model.OutboxMessage
+	if rf, ok := ret.Get(0).(func(string, int, int) *model.OutboxMessage); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.OutboxMessage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, int, int) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetProjectByID provides a mock function with given fields: _a0
 func (_m *Store) GetProjectByID(_a0 int) (*model.Project, error) {
 	ret := _m.Called(_a0)
@@ -158,6 +443,52 @@ func (_m *Store) GetProjectByPath(_a0 string) (*model.Project, error) {
 	return r0, r1
 }
 
+// GetSlackNote provides a mock function with given fields: _a0
+func (_m *Store) GetSlackNote(_a0 string) (*model.SlackNote, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *model.SlackNote
+	if rf, ok := ret.Get(0).(func(string) *model.SlackNote); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SlackNote)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetThreadByTS provides a mock function with given fields: _a0, _a1
+func (_m *Store) GetThreadByTS(_a0 string, _a1 string) (*model.Thread, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *model.Thread
+	if rf, ok := ret.Get(0).(func(string, string) *model.Thread); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Thread)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUserByEmail provides a mock function with given fields: _a0
 func (_m *Store) GetUserByEmail(_a0 string) (*model.User, error) {
 	ret := _m.Called(_a0)
@@ -204,26 +535,311 @@ func (_m *Store) GetUserByID(_a0 int) (*model.User, error) {
 	return r0, r1
 }
 
-// UpdateProjectDefaultChannel provides a mock function with given fields: _a0, _a1
-func (_m *Store) UpdateProjectDefaultChannel(_a0 string, _a1 string) error {
-	ret := _m.Called(_a0, _a1)
+// GetUserBySlackID provides a mock function with given fields: _a0
+func (_m *Store) GetUserBySlackID(_a0 string) (*model.User, error) {
+	ret := _m.Called(_a0)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string) error); ok {
-		r0 = rf(_a0, _a1)
+	var r0 *model.User
+	if rf, ok := ret.Get(0).(func(string) *model.User); ok {
+		r0 = rf(_a0)
 	} else {
-		r0 = ret.Error(0)
-	}
-
-	return r0
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// UpdateUserDefaultChannel provides a mock function with given fields: _a0, _a1
-func (_m *Store) UpdateUserDefaultChannel(_a0 string, _a1 string) error {
+// GetWebhookEvent provides a mock function with given fields: _a0
+func (_m *Store) GetWebhookEvent(_a0 int) (*model.WebhookEvent, error) {
+	ret := _m.Called(_a0)
+
+	var r0 *model.WebhookEvent
+	if rf, ok := ret.Get(0).(func(int) *model.WebhookEvent); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.WebhookEvent)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListDueOutboxMessages provides a mock function with given fields: _a0
+func (_m *Store) ListDueOutboxMessages(_a0 time.Time) ([]*model.OutboxMessage, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []*model.OutboxMessage
+	if rf, ok := ret.Get(0).(func(time.Time) []*model.OutboxMessage); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.OutboxMessage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListIdleMergeRequests provides a mock function with given fields:
+func (_m *Store) ListIdleMergeRequests() ([]*model.MergeRequest, error) {
+	ret := _m.Called()
+
+	var r0 []*model.MergeRequest
+	if rf, ok := ret.Get(0).(func() []*model.MergeRequest); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.MergeRequest)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListOpenMergeRequests provides a mock function with given fields: _a0
+func (_m *Store) ListOpenMergeRequests(_a0 string) ([]*model.MergeRequest, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []*model.MergeRequest
+	if rf, ok := ret.Get(0).(func(string) []*model.MergeRequest); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.MergeRequest)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListOutboxMessages provides a mock function with given fields: _a0
+func (_m *Store) ListOutboxMessages(_a0 string) ([]*model.OutboxMessage, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []*model.OutboxMessage
+	if rf, ok := ret.Get(0).(func(string) []*model.OutboxMessage); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.OutboxMessage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListProjectsByChannel provides a mock function with given fields: _a0, _a1
+func (_m *Store) ListProjectsByChannel(_a0 string, _a1 string) ([]*model.Project, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*model.Project
+	if rf, ok := ret.Get(0).(func(string, string) []*model.Project); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Project)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListQueuedNotifications provides a mock function with given fields:
+func (_m *Store) ListQueuedNotifications() ([]*model.QueuedNotification, error) {
+	ret := _m.Called()
+
+	var r0 []*model.QueuedNotification
+	if rf, ok := ret.Get(0).(func() []*model.QueuedNotification); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.QueuedNotification)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListReviewDigests provides a mock function with given fields:
+func (_m *Store) ListReviewDigests() ([]*model.ReviewDigest, error) {
+	ret := _m.Called()
+
+	var r0 []*model.ReviewDigest
+	if rf, ok := ret.Get(0).(func() []*model.ReviewDigest); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.ReviewDigest)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListRoutes provides a mock function with given fields: _a0
+func (_m *Store) ListRoutes(_a0 []string) ([]*model.Route, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []*model.Route
+	if rf, ok := ret.Get(0).(func([]string) []*model.Route); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Route)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListTemplates provides a mock function with given fields: _a0, _a1
+func (_m *Store) ListTemplates(_a0 string, _a1 []string) ([]*model.Template, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*model.Template
+	if rf, ok := ret.Get(0).(func(string, []string) []*model.Template); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Template)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []string) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListThreads provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Store) ListThreads(_a0 string, _a1 int, _a2 int) ([]*model.Thread, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 []*model.Thread
+	if rf, ok := ret.Get(0).(func(string, int, int) []*model.Thread); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Thread)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, int, int) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListWebhookEvents provides a mock function with given fields: _a0
+func (_m *Store) ListWebhookEvents(_a0 string) ([]*model.WebhookEvent, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []*model.WebhookEvent
+	if rf, ok := ret.Get(0).(func(string) []*model.WebhookEvent); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.WebhookEvent)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReplaceRoutes provides a mock function with given fields: _a0, _a1
+func (_m *Store) ReplaceRoutes(_a0 string, _a1 []*model.Route) error {
 	ret := _m.Called(_a0, _a1)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+	if rf, ok := ret.Get(0).(func(string, []*model.Route) error); ok {
 		r0 = rf(_a0, _a1)
 	} else {
 		r0 = ret.Error(0)
@@ -232,6 +848,29 @@ func (_m *Store) UpdateUserDefaultChannel(_a0 string, _a1 string) error {
 	return r0
 }
 
+// SearchWebhookEvents provides a mock function with given fields: _a0
+func (_m *Store) SearchWebhookEvents(_a0 *model.WebhookEventFilter) ([]*model.WebhookEvent, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []*model.WebhookEvent
+	if rf, ok := ret.Get(0).(func(*model.WebhookEventFilter) []*model.WebhookEvent); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.WebhookEvent)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*model.WebhookEventFilter) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateGroupDefaultChannel provides a mock function with given fields: _a0, _a1
 func (_m *Store) UpdateGroupDefaultChannel(_a0 string, _a1 string) error {
 	ret := _m.Called(_a0, _a1)
@@ -245,3 +884,143 @@ func (_m *Store) UpdateGroupDefaultChannel(_a0 string, _a1 string) error {
 
 	return r0
 }
+
+// UpdateMergeRequest provides a mock function with given fields: _a0
+func (_m *Store) UpdateMergeRequest(_a0 *model.MergeRequest) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.MergeRequest) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateMergeRequestActivity provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Store) UpdateMergeRequestActivity(_a0 int, _a1 int, _a2 time.Time) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int, int, time.Time) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateMergeRequestReminders provides a mock function with given fields: _a0
+func (_m *Store) UpdateMergeRequestReminders(_a0 *model.MergeRequest) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.MergeRequest) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateOutboxMessage provides a mock function with given fields: _a0
+func (_m *Store) UpdateOutboxMessage(_a0 *model.OutboxMessage) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.OutboxMessage) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateProjectDefaultChannel provides a mock function with given fields: _a0, _a1
+func (_m *Store) UpdateProjectDefaultChannel(_a0 string, _a1 string) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateProjectSettings provides a mock function with given fields: _a0
+func (_m *Store) UpdateProjectSettings(_a0 *model.Project) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.Project) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateReviewDigestLastRun provides a mock function with given fields: _a0, _a1
+func (_m *Store) UpdateReviewDigestLastRun(_a0 string, _a1 time.Time) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, time.Time) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateUserDefaultChannel provides a mock function with given fields: _a0, _a1
+func (_m *Store) UpdateUserDefaultChannel(_a0 string, _a1 string) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateUserPreferences provides a mock function with given fields: _a0
+func (_m *Store) UpdateUserPreferences(_a0 *model.User) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.User) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateWebhookEvent provides a mock function with given fields: _a0
+func (_m *Store) UpdateWebhookEvent(_a0 *model.WebhookEvent) error {
+	ret := _m.Called(_a0)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*model.WebhookEvent) error); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -1,6 +1,8 @@
 package store
 
 import (
+	"time"
+
 	"gitlack/model"
 )
 
@@ -10,15 +12,57 @@ type Store interface {
 	GetProjectByID(int) (*model.Project, error)
 	GetUserByEmail(string) (*model.User, error)
 	GetUserByID(int) (*model.User, error)
+	GetUserBySlackID(string) (*model.User, error)
 	GetMergeRequest(int, int) (*model.MergeRequest, error)
 	GetIssue(int, int) (*model.Issue, error)
+	GetThreadByTS(string, string) (*model.Thread, error)
+	GetOutboxMessage(int) (*model.OutboxMessage, error)
+	GetPendingOutboxMessage(string, int, int) (*model.OutboxMessage, error)
+	GetSlackNote(string) (*model.SlackNote, error)
+	ListOutboxMessages(string) ([]*model.OutboxMessage, error)
+	ListDueOutboxMessages(time.Time) ([]*model.OutboxMessage, error)
+	GetWebhookEvent(int) (*model.WebhookEvent, error)
+	ListWebhookEvents(string) ([]*model.WebhookEvent, error)
+	SearchWebhookEvents(*model.WebhookEventFilter) ([]*model.WebhookEvent, error)
+	ListTemplates(string, []string) ([]*model.Template, error)
+	ListRoutes([]string) ([]*model.Route, error)
+	ListThreads(string, int, int) ([]*model.Thread, error)
+	ListQueuedNotifications() ([]*model.QueuedNotification, error)
+	ListReviewDigests() ([]*model.ReviewDigest, error)
+	ListOpenMergeRequests(string) ([]*model.MergeRequest, error)
+	ListProjectsByChannel(string, string) ([]*model.Project, error)
+	ListIdleMergeRequests() ([]*model.MergeRequest, error)
+	CountProjectWebhookSecrets() (int, error)
 
 	UpdateUserDefaultChannel(string, string) error
+	UpdateUserPreferences(*model.User) error
 	UpdateProjectDefaultChannel(string, string) error
 	UpdateGroupDefaultChannel(string, string) error
+	UpdateProjectSettings(*model.Project) error
+	UpdateMergeRequest(*model.MergeRequest) error
+	UpdateMergeRequestActivity(int, int, time.Time) error
+	UpdateMergeRequestReminders(*model.MergeRequest) error
+	UpdateOutboxMessage(*model.OutboxMessage) error
+	UpdateWebhookEvent(*model.WebhookEvent) error
+	ReplaceRoutes(string, []*model.Route) error
+	UpdateReviewDigestLastRun(string, time.Time) error
 
 	CreateUser(*model.User) error
 	CreateProject(*model.Project) error
 	CreateMergeRequest(*model.MergeRequest) error
 	CreateIssue(*model.Issue) error
+	CreateOutboxMessage(*model.OutboxMessage) error
+	CreateWebhookEvent(*model.WebhookEvent) error
+	CreateEventUUID(string, time.Time) (bool, error)
+	CreateTemplate(*model.Template) error
+	CreateThread(*model.Thread) error
+	CreateQueuedNotification(*model.QueuedNotification) error
+	CreateReviewDigest(*model.ReviewDigest) error
+	CreateSlackNote(*model.SlackNote) error
+
+	DeleteEventUUIDsBefore(time.Time) (int64, error)
+	DeleteWebhookEventsBefore(time.Time) (int64, error)
+	DeleteTemplate(string, string) (int64, error)
+	DeleteQueuedNotifications([]int) error
+	DeleteReviewDigest(string) (int64, error)
 }